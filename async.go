@@ -0,0 +1,70 @@
+package lifx
+
+// Future is the eventual result of a call made with one of the Async
+// methods. The call runs in its own goroutine, within the client's rate
+// limit and worker pool like any other.
+type Future[T any] struct {
+	done chan struct{}
+	v    T
+	err  error
+}
+
+// async runs fn for c. Once c is closing, the future fails straight away
+// with ErrClientClosed.
+func async[T any](c *Client, fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	if !c.track() {
+		f.err = ErrClientClosed
+		close(f.done)
+		return f
+	}
+
+	go func() {
+		defer c.untrack()
+		defer close(f.done)
+		f.err = safely(func() (err error) {
+			f.v, err = fn()
+			return err
+		})
+	}()
+	return f
+}
+
+// Done is closed once the call has finished, for use in a select.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the call has finished and returns its result.
+func (f *Future[T]) Wait() (T, error) {
+	<-f.done
+	return f.v, f.err
+}
+
+func (c *Client) SetStateAsync(selector string, state State) *Future[*LifxResponse] {
+	return async(c, func() (*LifxResponse, error) { return c.SetState(selector, state) })
+}
+
+func (c *Client) SetStatesAsync(selector string, states States) *Future[*SetStatesResponse] {
+	return async(c, func() (*SetStatesResponse, error) { return c.SetStates(selector, states) })
+}
+
+func (c *Client) StateDeltaAsync(selector string, delta StateDelta) *Future[*LifxResponse] {
+	return async(c, func() (*LifxResponse, error) { return c.StateDelta(selector, delta) })
+}
+
+func (c *Client) ToggleAsync(selector string, duration float64) *Future[*LifxResponse] {
+	return async(c, func() (*LifxResponse, error) { return c.Toggle(selector, duration) })
+}
+
+func (c *Client) BreatheAsync(selector string, breathe Breathe) *Future[*LifxResponse] {
+	return async(c, func() (*LifxResponse, error) { return c.Breathe(selector, breathe) })
+}
+
+func (c *Client) ActivateSceneAsync(uuid string, activate Activate) *Future[*LifxResponse] {
+	return async(c, func() (*LifxResponse, error) { return c.ActivateScene(uuid, activate) })
+}
+
+func (c *Client) ListLightsAsync(selector string) *Future[[]Light] {
+	return async(c, func() ([]Light, error) { return c.ListLights(selector) })
+}
@@ -0,0 +1,156 @@
+package lifx
+
+type (
+	Backend interface {
+		ListLights(selector string) ([]Light, error)
+		SetState(selector string, state State) (*LifxResponse, error)
+		Toggle(selector string, duration float64) (*LifxResponse, error)
+		PowerOn(selector string) (*LifxResponse, error)
+		PowerOff(selector string) (*LifxResponse, error)
+		Breathe(selector string, breathe Breathe) (*LifxResponse, error)
+	}
+
+	// Reacher is implemented by backends that only know about some of the
+	// lights on an account, such as the LAN client.
+	Reacher interface {
+		Reachable(selector string) bool
+	}
+
+	Router struct {
+		cloud Backend
+		local Backend
+	}
+)
+
+var (
+	_ Backend = (*Client)(nil)
+	_ Backend = (*Router)(nil)
+)
+
+// NewRouter returns a Backend that sends each call to local when it can
+// reach every light the selector names and to cloud otherwise. Calls that
+// fail locally are retried against cloud; see Toggle for how toggles are.
+func NewRouter(cloud, local Backend) *Router {
+	return &Router{cloud: cloud, local: local}
+}
+
+func (r *Router) useLocal(selector string) bool {
+	if r.local == nil {
+		return false
+	}
+	if reacher, ok := r.local.(Reacher); ok {
+		return reacher.Reachable(selector)
+	}
+	return true
+}
+
+func (r *Router) ListLights(selector string) ([]Light, error) {
+	if r.useLocal(selector) {
+		if lights, err := r.local.ListLights(selector); err == nil {
+			return lights, nil
+		}
+	}
+	return r.cloud.ListLights(selector)
+}
+
+func (r *Router) SetState(selector string, state State) (*LifxResponse, error) {
+	if r.useLocal(selector) {
+		if s, err := r.local.SetState(selector, state); err == nil {
+			return s, nil
+		}
+	}
+	return r.cloud.SetState(selector, state)
+}
+
+// Toggle reads the lights' power before toggling them locally. A local
+// toggle that fails may already have flipped some of them, so the cloud is
+// then told the power each light should end up with rather than toggling
+// them a second time.
+func (r *Router) Toggle(selector string, duration float64) (*LifxResponse, error) {
+	if !r.useLocal(selector) {
+		return r.cloud.Toggle(selector, duration)
+	}
+
+	lights, err := r.local.ListLights(selector)
+	if err != nil || len(lights) == 0 {
+		return r.cloud.Toggle(selector, duration)
+	}
+
+	parts := make([]SelectorPart, len(lights))
+	for i, l := range lights {
+		parts[i] = ById(l.Id)
+	}
+	ids, err := BuildSelector(parts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if s, err := r.local.Toggle(ids, duration); err == nil {
+		return s, nil
+	}
+	return setToggled(r.cloud, lights, duration)
+}
+
+func (r *Router) PowerOn(selector string) (*LifxResponse, error) {
+	if r.useLocal(selector) {
+		if s, err := r.local.PowerOn(selector); err == nil {
+			return s, nil
+		}
+	}
+	return r.cloud.PowerOn(selector)
+}
+
+func (r *Router) PowerOff(selector string) (*LifxResponse, error) {
+	if r.useLocal(selector) {
+		if s, err := r.local.PowerOff(selector); err == nil {
+			return s, nil
+		}
+	}
+	return r.cloud.PowerOff(selector)
+}
+
+func (r *Router) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
+	if r.useLocal(selector) {
+		if s, err := r.local.Breathe(selector, breathe); err == nil {
+			return s, nil
+		}
+	}
+	return r.cloud.Breathe(selector, breathe)
+}
+
+// setToggled powers each light to the opposite of its power in lights.
+// Unlike a toggle, this can be repeated without flipping a light back.
+func setToggled(b Backend, lights []Light, duration float64) (*LifxResponse, error) {
+	parts := make(map[string][]SelectorPart)
+	for _, l := range lights {
+		power := "on"
+		if l.Power == "on" {
+			power = "off"
+		}
+		parts[power] = append(parts[power], ById(l.Id))
+	}
+
+	merged := &LifxResponse{}
+	for _, power := range []string{"on", "off"} {
+		if len(parts[power]) == 0 {
+			continue
+		}
+
+		s, err := EachBatch(parts[power], func(selector string) (*LifxResponse, error) {
+			return b.SetState(selector, State{Power: power, Duration: duration})
+		})
+		if s != nil {
+			merged.Warnings = append(merged.Warnings, s.Warnings...)
+			for _, res := range s.Results {
+				if res.Status.IsOK() {
+					res.Power = power
+				}
+				merged.Results = append(merged.Results, res)
+			}
+		}
+		if err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,135 @@
+package lifx_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+// lostAcks toggles its lights but reports a timeout, as a LAN client does
+// when the acks for a SetPower it applied never arrive.
+type lostAcks struct {
+	*lifxtest.FakeClient
+}
+
+func (l lostAcks) Toggle(selector string, duration float64) (*lifx.LifxResponse, error) {
+	l.FakeClient.Toggle(selector, duration)
+	return nil, errors.New("timed out waiting for an ack")
+}
+
+func toggleLights() []lifx.Light {
+	desk := lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home")
+	porch := lifxtest.NewLight("d073d5000002", "Porch", "Outside", "Home")
+	desk.Power = "on"
+	porch.Power = "off"
+	return []lifx.Light{desk, porch}
+}
+
+func TestRouterToggleFallback(t *testing.T) {
+	local := lifxtest.NewFakeClient(toggleLights()...)
+	cloud := lifxtest.NewFakeClient(toggleLights()...)
+
+	r := lifx.NewRouter(cloud, lostAcks{local})
+	s, err := r.Toggle("all", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(cloud.CallsTo("Toggle")); n != 0 {
+		t.Errorf("cloud toggled %d times, want explicit power changes", n)
+	}
+	want := map[string]string{"d073d5000001": "off", "d073d5000002": "on"}
+	for id, power := range want {
+		for _, f := range []*lifxtest.FakeClient{local, cloud} {
+			if l, _ := f.Light(id); l.Power != power {
+				t.Errorf("%s power = %q, want %q", id, l.Power, power)
+			}
+		}
+	}
+	for _, res := range s.Results {
+		if res.Power != want[res.Id] {
+			t.Errorf("result for %s power = %q, want %q", res.Id, res.Power, want[res.Id])
+		}
+	}
+}
+
+func TestRouterToggleUnreadable(t *testing.T) {
+	local := lifxtest.NewFakeClient(toggleLights()...)
+	local.FailWith("ListLights", errors.New("no devices"))
+	cloud := lifxtest.NewFakeClient(toggleLights()...)
+
+	r := lifx.NewRouter(cloud, local)
+	if _, err := r.Toggle("all", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(local.CallsTo("Toggle")); n != 0 {
+		t.Errorf("local toggled %d times after failing to list", n)
+	}
+	if n := len(cloud.CallsTo("Toggle")); n != 1 {
+		t.Errorf("cloud toggled %d times, want 1", n)
+	}
+}
+
+// lanBulbs reaches the bulbs behind api directly, as the LAN client does,
+// and loses the acks for every toggle it applies.
+type lanBulbs struct {
+	lifx.Backend
+	api *lifxtest.Server
+}
+
+func (b lanBulbs) HardwareAddrs() []net.HardwareAddr {
+	var macs []net.HardwareAddr
+	for _, l := range b.api.Lights() {
+		mac, _ := hex.DecodeString(l.Id)
+		macs = append(macs, mac)
+	}
+	return macs
+}
+
+func (b lanBulbs) ListLights(selector string) ([]lifx.Light, error) {
+	var lights []lifx.Light
+	for _, l := range b.api.Lights() {
+		if ok, err := lifx.MatchSelector(selector, l); err != nil {
+			return nil, err
+		} else if ok {
+			lights = append(lights, l)
+		}
+	}
+	return lights, nil
+}
+
+func (b lanBulbs) Toggle(selector string, duration float64) (*lifx.LifxResponse, error) {
+	lights, _ := b.ListLights(selector)
+	for _, l := range lights {
+		b.api.UpdateLight(l.Id, func(l *lifx.Light) {
+			if l.Power == "on" {
+				l.Power = "off"
+			} else {
+				l.Power = "on"
+			}
+		})
+	}
+	return nil, errors.New("timed out waiting for an ack")
+}
+
+func TestHybridToggleFallback(t *testing.T) {
+	api := lifxtest.NewServer(toggleLights()...)
+	defer api.Close()
+
+	h := lifx.NewHybrid(lifx.NewClient("x", lifxtest.WithServer(api)), lanBulbs{api: api})
+	if _, err := h.Toggle("all", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"d073d5000001": "off", "d073d5000002": "on"}
+	for id, power := range want {
+		if l, _ := api.Light(id); l.Power != power {
+			t.Errorf("%s power = %q, want %q", id, l.Power, power)
+		}
+	}
+}
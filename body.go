@@ -0,0 +1,46 @@
+package lifx
+
+import (
+	"errors"
+	"io"
+)
+
+// DefaultMaxResponseSize is far larger than any response the API sends,
+// even for accounts with hundreds of lights.
+var DefaultMaxResponseSize int64 = 10 << 20
+
+var ErrResponseTooLarge = errors.New("response body exceeds the maximum size")
+
+type limitedBody struct {
+	r    io.Reader
+	c    io.Closer
+	max  int64
+	read int64
+}
+
+func (c *Client) limitBody(body io.ReadCloser) io.ReadCloser {
+	max := c.maxBody
+	if max == 0 {
+		max = DefaultMaxResponseSize
+	}
+	if max < 0 {
+		return body
+	}
+
+	// One byte more than allowed tells a body that is exactly the maximum
+	// from one that is larger.
+	return &limitedBody{r: io.LimitReader(body, max+1), c: body, max: max}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.read > b.max {
+		return n - int(b.read-b.max), ErrResponseTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.c.Close()
+}
@@ -0,0 +1,114 @@
+package lifx
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// CachedLister answers ListLights from memory for a while after each
+	// request, and callers asking for the same selector while a request is
+	// in flight share its result. Polling consumers built on it cost one
+	// request per TTL however many there are.
+	CachedLister struct {
+		lister   Lister
+		ttl      time.Duration
+		clock    Clock
+		mu       sync.Mutex
+		entries  map[string]cacheEntry
+		inflight map[string]*listCall
+	}
+
+	cacheEntry struct {
+		lights  []Light
+		fetched time.Time
+	}
+
+	listCall struct {
+		done   chan struct{}
+		lights []Light
+		err    error
+	}
+)
+
+var DefaultCacheTTL = 10 * time.Second
+
+func NewCachedLister(lister Lister, ttl time.Duration, options ...func(*CachedLister)) *CachedLister {
+	c := &CachedLister{
+		lister:   lister,
+		ttl:      ttl,
+		clock:    SystemClock,
+		entries:  make(map[string]cacheEntry),
+		inflight: make(map[string]*listCall),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+func WithCacheClock(clock Clock) func(*CachedLister) {
+	return func(c *CachedLister) {
+		c.clock = clock
+	}
+}
+
+func (c *CachedLister) ListLights(selector string) ([]Light, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[selector]; ok && c.clock.Now().Sub(e.fetched) < c.ttl {
+		c.mu.Unlock()
+		return copyLights(e.lights), nil
+	}
+	if call, ok := c.inflight[selector]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return copyLights(call.lights), call.err
+	}
+
+	call := &listCall{done: make(chan struct{})}
+	c.inflight[selector] = call
+	c.mu.Unlock()
+
+	call.lights, call.err = c.lister.ListLights(selector)
+
+	c.mu.Lock()
+	delete(c.inflight, selector)
+	if call.err == nil {
+		c.entries[selector] = cacheEntry{lights: call.lights, fetched: c.clock.Now()}
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return copyLights(call.lights), call.err
+}
+
+// Age reports how long ago the lights for selector were fetched.
+func (c *CachedLister) Age(selector string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[selector]
+	if !ok {
+		return 0, false
+	}
+	return c.clock.Now().Sub(e.fetched), true
+}
+
+// Invalidate drops every cached response, for instance after changing the
+// state of some lights.
+func (c *CachedLister) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
+
+// copyLights keeps callers from modifying the cached slice.
+func copyLights(lights []Light) []Light {
+	if lights == nil {
+		return nil
+	}
+	return append([]Light(nil), lights...)
+}
@@ -7,8 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -22,12 +22,55 @@ type (
 		userAgent   string
 		Client      *http.Client
 		debug       bool
-	}
+		endpoint    string
+		timeout     time.Duration
+		retry       RetryPolicy
+		limiter     *rateLimiter
+		decoding    DecodeMode
+		logger      Logger
+		maxBody     int64
+		fast        bool
+		duration    float64
+		parallel    int
+		clampK      bool
+		floor       float64
+		pool        *workerPool
+		products    *productCache
+		life        *lifecycle
+		clock       Clock
+		rand        *lockedRand
+		onError     ErrorHandler
+		correlation string
+	}
+
+	// ErrorHandler is told about operations that failed, named by op,
+	// such as "PUT /v1/lights/all/state" for a request.
+	ErrorHandler func(op string, err error)
+
+	// Logger receives diagnostics such as unknown response fields.
+	// *log.Logger satisfies it.
+	Logger interface {
+		Printf(format string, v ...interface{})
+	}
+
+	DecodeMode int
 
 	Result struct {
 		Id     string `json:"id"`
 		Label  string `json:"label"`
 		Status Status `json:"status"`
+
+		// Power is only reported by Toggle, as the power each light was
+		// left with.
+		Power string `json:"power,omitempty"`
+	}
+
+	// ToggleResult sorts the lights in a Toggle response by the power they
+	// ended up with, so callers need not list them again to find out.
+	ToggleResult struct {
+		On     []Result
+		Off    []Result
+		Failed []Result
 	}
 
 	Error struct {
@@ -50,6 +93,7 @@ type (
 		Header     http.Header
 		Body       io.ReadCloser
 		RateLimit  RateLimit
+		Request    *http.Request
 	}
 
 	LifxResponse struct {
@@ -58,19 +102,90 @@ type (
 		Warnings []Warning `json:"warnings"`
 		Results  []Result  `json:"results"`
 	}
+
+	// APIError is an error response from the API. Errors holds the
+	// problems with individual parameters when validation failed, and Err
+	// the reason the body could not be read, if it could not.
+	APIError struct {
+		StatusCode    int
+		Message       string
+		Errors        []Error
+		RateLimit     RateLimit
+		RequestID     string
+		CorrelationID string
+		Err           error
+	}
+
+	// SelectorError is returned when the API reports that a selector
+	// matched no lights or could not be parsed. It wraps the APIError.
+	SelectorError struct {
+		Selector   string
+		StatusCode int
+		Message    string
+		Err        *APIError
+	}
+
+	// DecodeError is returned when a response body is not the JSON that
+	// was expected, such as an HTML error page from a proxy. Body holds
+	// the start of what was received.
+	DecodeError struct {
+		Method        string
+		URL           string
+		StatusCode    int
+		Body          []byte
+		RequestID     string
+		CorrelationID string
+		Err           error
+	}
+
+	// RequestError is returned when a request got no response, such as
+	// when the connection failed or timed out. It carries the ids that
+	// were sent so the attempt can be found in logs, and Err is the
+	// transport's error.
+	RequestError struct {
+		RequestID     string
+		CorrelationID string
+		Err           error
+	}
+)
+
+const (
+	// DecodeLenient ignores response fields the package does not model.
+	DecodeLenient DecodeMode = iota
+
+	// DecodeWarn logs unknown fields and otherwise decodes leniently.
+	DecodeWarn
+
+	// DecodeStrict fails calls whose responses have unknown fields.
+	DecodeStrict
+)
+
+// maxDecodeErrorBody bounds how much of a body a DecodeError keeps.
+const maxDecodeErrorBody = 512
+
+// Errors returned, wrapped in an APIError, for each documented error
+// status. Test for them with errors.Is.
+var (
+	ErrNotFound        = errors.New("Selector did not match any lights")
+	ErrUnauthorized    = errors.New("Bad access token")
+	ErrForbidden       = errors.New("Bad OAuth scope")
+	ErrValidation      = errors.New("Missing or malformed parameters")
+	ErrUpgradeRequired = errors.New("HTTP was used to make the request instead of HTTPS. Repeat the request using HTTPS instead")
+	ErrRateLimited     = errors.New("The request exceeded a rate limit")
+	ErrServer          = errors.New("Something went wrong on LIFX's end")
 )
 
 var errorMap = map[int]error{
-	http.StatusNotFound:            errors.New("Selector did not match any lights"),
-	http.StatusUnauthorized:        errors.New("Bad access token"),
-	http.StatusForbidden:           errors.New("Bad OAuth scope"),
-	http.StatusUnprocessableEntity: errors.New("Missing or malformed parameters"),
-	http.StatusUpgradeRequired:     errors.New("HTTP was used to make the request instead of HTTPS. Repeat the request using HTTPS instead"),
-	http.StatusTooManyRequests:     errors.New("The request exceeded a rate limit"),
-	http.StatusInternalServerError: errors.New("Something went wrong on LIFX's end"),
-	http.StatusBadGateway:          errors.New("Something went wrong on LIFX's end"),
-	http.StatusServiceUnavailable:  errors.New("Something went wrong on LIFX's end"),
-	523:                            errors.New("Something went wrong on LIFX's end"),
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrForbidden,
+	http.StatusUnprocessableEntity: ErrValidation,
+	http.StatusUpgradeRequired:     ErrUpgradeRequired,
+	http.StatusTooManyRequests:     ErrRateLimited,
+	http.StatusInternalServerError: ErrServer,
+	http.StatusBadGateway:          ErrServer,
+	http.StatusServiceUnavailable:  ErrServer,
+	523:                            ErrServer,
 }
 
 var userAgent string
@@ -88,6 +203,8 @@ func NewClient(accessToken string, options ...func(*Client)) *Client {
 	c = &Client{
 		accessToken: accessToken,
 		Client:      &http.Client{Transport: tr},
+		products:    newProductCache(DefaultProductTTL),
+		life:        newLifecycle(),
 	}
 
 	for _, option := range options {
@@ -97,6 +214,22 @@ func NewClient(accessToken string, options ...func(*Client)) *Client {
 	return c
 }
 
+// With returns a copy of the client with options applied, for overriding
+// settings such as WithTimeout, WithRetry, WithFast or WithDefaultDuration
+// for one call:
+//
+//	c.With(lifx.WithRetry(lifx.RetryPolicy{})).PowerOff("all")
+//
+// The copy shares the original's HTTP client and rate limiter, so making
+// one is cheap and its requests still count against the same limit.
+func (c *Client) With(options ...func(*Client)) *Client {
+	d := *c
+	for _, option := range options {
+		option(&d)
+	}
+	return &d
+}
+
 func WithUserAgent(userAgent string) func(*Client) {
 	return func(c *Client) {
 		c.userAgent = userAgent
@@ -109,6 +242,128 @@ func WithDebug(debug bool) func(*Client) {
 	}
 }
 
+// WithEndpoint sends the client's requests to endpoint instead of
+// Endpoint, such as a proxy or an emulator.
+func WithEndpoint(endpoint string) func(*Client) {
+	return func(c *Client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithTimeout bounds each request, including reading its response.
+func WithTimeout(timeout time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithLogger sends the client's diagnostics to logger, including the
+// warnings the API attaches to responses.
+func WithLogger(logger Logger) func(*Client) {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithErrorHandler calls fn for every request that fails, however it was
+// made, so that failures in Async calls, watcher polls and loops run with
+// Start are seen even when nothing checks their result.
+func WithErrorHandler(fn ErrorHandler) func(*Client) {
+	return func(c *Client) {
+		c.onError = fn
+	}
+}
+
+func (c *Client) reportError(op string, err error) {
+	if c.onError != nil && err != nil {
+		c.onError(op, err)
+	}
+}
+
+// WithMaxResponseSize caps how many bytes of a response body are read,
+// protecting long-running programs from a misbehaving endpoint or proxy.
+// Larger bodies fail with ErrResponseTooLarge. A negative size removes the
+// cap.
+func WithMaxResponseSize(size int64) func(*Client) {
+	return func(c *Client) {
+		c.maxBody = size
+	}
+}
+
+// WithFastByDefault sends every state change, scene activation and
+// SetStates call in fast mode, for programs that never read the results.
+// The API then answers without waiting for the lights, and the methods
+// return a nil response, whose methods report no failures.
+func WithFastByDefault() func(*Client) {
+	return WithFast(true)
+}
+
+// WithFast turns fast mode on or off, which with With overrides
+// WithFastByDefault for a single call.
+func WithFast(fast bool) func(*Client) {
+	return func(c *Client) {
+		c.fast = fast
+	}
+}
+
+// WithDefaultDuration gives every state change, toggle and scene
+// activation that does not set a duration a transition of d. A zero
+// duration counts as unset, so it takes the default too.
+func WithDefaultDuration(d time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.duration = d.Seconds()
+	}
+}
+
+// WithStatesConcurrency lets SetStates send up to n of the requests a
+// batch of more than MaxStates states is split into at the same time.
+// Without it they are sent one after another, or as many at once as the
+// client's worker pool holds.
+func WithStatesConcurrency(n int) func(*Client) {
+	return func(c *Client) {
+		c.parallel = n
+	}
+}
+
+func (c *Client) statesConcurrency() int {
+	switch {
+	case c.parallel >= 1:
+		return c.parallel
+	case c.pool != nil:
+		return c.pool.size()
+	}
+	return 1
+}
+
+// WithKelvinClamp makes SetState, and the methods built on it, bring a
+// kelvin outside what a light supports into its range instead of having
+// the API reject the change. The ranges come from Products, and when they
+// differ each light is sent its own kelvin.
+func WithKelvinClamp() func(*Client) {
+	return func(c *Client) {
+		c.clampK = true
+	}
+}
+
+// WithBrightnessFloor raises any brightness below min in the states the
+// client sends, in the state itself or its color, so that automations
+// cannot leave lights on but too dark to see. States that turn lights off
+// are left alone, as are deltas, whose outcome depends on the light.
+func WithBrightnessFloor(min float64) func(*Client) {
+	return func(c *Client) {
+		c.floor = min
+	}
+}
+
+// WithStrictDecoding reports response fields the package does not model
+// yet, which is how to notice that the API has grown. Warnings go to the
+// client's logger.
+func WithStrictDecoding(mode DecodeMode) func(*Client) {
+	return func(c *Client) {
+		c.decoding = mode
+	}
+}
+
 func NewClientWithUserAgent(accessToken string, userAgent string) *Client {
 	tr := &http.Transport{
 		//TLSNextProto: make(map[string]func(authority string, c *tls.Conn) http.RoundTripper),
@@ -117,6 +372,8 @@ func NewClientWithUserAgent(accessToken string, userAgent string) *Client {
 		accessToken: accessToken,
 		userAgent:   userAgent,
 		Client:      &http.Client{Transport: tr},
+		products:    newProductCache(DefaultProductTTL),
+		life:        newLifecycle(),
 	}
 }
 
@@ -125,6 +382,7 @@ func NewResponse(r *http.Response) (*Response, error) {
 		StatusCode: r.StatusCode,
 		Header:     r.Header,
 		Body:       r.Body,
+		Request:    r.Request,
 	}
 
 	if t := r.Header.Get("X-RateLimit-Limit"); t != "" {
@@ -158,223 +416,395 @@ func (r *Response) IsError() bool {
 	return r.StatusCode > 299
 }
 
+// GetLifxError reads an error response into an APIError, or a
+// SelectorError when the selector was at fault.
 func (r *Response) GetLifxError() (err error) {
 	var (
 		s *LifxResponse
 	)
-	if err = json.NewDecoder(r.Body).Decode(&s); err != nil {
-		return nil
+
+	e := &APIError{StatusCode: r.StatusCode, RateLimit: r.RateLimit}
+	e.RequestID, e.CorrelationID = requestIDs(r.Request)
+	if err = r.decode(&s, false); err != nil {
+		// Plenty of error responses have no body at all.
+		if !errors.Is(err, io.EOF) {
+			e.Err = err
+		}
+		return e
+	}
+	if s != nil {
+		e.Message, e.Errors = s.Error, s.Errors
 	}
-	return errors.New(s.Error)
-}
 
-func (c *Client) NewRequest(method, url string, body io.Reader) (req *http.Request, err error) {
-	req, err = http.NewRequest(method, url, body)
-	if err != nil {
-		return
+	if selector := r.selector(); selector != "" {
+		if r.StatusCode == http.StatusNotFound {
+			return &SelectorError{Selector: selector, StatusCode: r.StatusCode, Message: e.Message, Err: e}
+		}
+		if msgs := e.Field("selector"); msgs != nil {
+			return &SelectorError{Selector: selector, StatusCode: r.StatusCode, Message: strings.Join(msgs, ", "), Err: e}
+		}
 	}
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", c.userAgent)
-	return
+
+	return e
 }
 
-func (c *Client) setState(selector string, state State) (*Response, error) {
-	var (
-		err  error
-		j    []byte
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-	)
+func (e *APIError) Error() string {
+	var b strings.Builder
 
-	if j, err = json.Marshal(state); err != nil {
-		return nil, err
+	switch {
+	case e.Message != "":
+		b.WriteString(e.Message)
+	case errorMap[e.StatusCode] != nil:
+		b.WriteString(errorMap[e.StatusCode].Error())
+	case e.StatusCode >= 500:
+		b.WriteString(ErrServer.Error())
+	default:
+		fmt.Fprintf(&b, "unexpected status %d", e.StatusCode)
 	}
 
-	if req, err = c.NewRequest("PUT", EndpointState(selector), bytes.NewBuffer(j)); err != nil {
-		return nil, err
+	for _, f := range e.Errors {
+		fmt.Fprintf(&b, "; %s", f)
 	}
-
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+	if e.Err != nil {
+		fmt.Fprintf(&b, " (%s)", e.Err)
 	}
+	b.WriteString(idSuffix(e.RequestID, e.CorrelationID))
+	return b.String()
+}
 
-	resp, err = NewResponse(r)
-	if err != nil {
-		return nil, err
+// Is matches the error for the response's status, so that
+// errors.Is(err, ErrRateLimited) holds for any 429.
+func (e *APIError) Is(target error) bool {
+	if target == ErrServer && e.StatusCode >= 500 {
+		return true
 	}
+	return target != nil && errorMap[e.StatusCode] == target
+}
 
-	return resp, nil
+func (e *APIError) Unwrap() error {
+	return e.Err
 }
 
-func (c *Client) breathe(selector string, breathe Breathe) (*Response, error) {
-	var (
-		err  error
-		j    []byte
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-	)
+// Field returns the validation messages for the named parameter.
+func (e *APIError) Field(name string) []string {
+	for _, f := range e.Errors {
+		if f.Field == name {
+			return f.Message
+		}
+	}
+	return nil
+}
 
-	if j, err = json.Marshal(breathe); err != nil {
-		return nil, err
+func (e Error) String() string {
+	return fmt.Sprintf("%s: %s", e.Field, strings.Join(e.Message, ", "))
+}
+
+// selector is the selector the request was made for, taken from its path.
+func (r *Response) selector() string {
+	if r.Request == nil {
+		return ""
 	}
 
-	if req, err = c.NewRequest("POST", EndpointBreathe(selector), bytes.NewBuffer(j)); err != nil {
-		return nil, err
+	parts := strings.Split(strings.Trim(r.Request.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if (parts[i] == "lights" && parts[i+1] != "states") || (parts[i] == "scenes" && i+2 < len(parts)) {
+			return parts[i+1]
+		}
 	}
+	return ""
+}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+func (e *SelectorError) Error() string {
+	var ids string
+	if e.Err != nil {
+		ids = idSuffix(e.Err.RequestID, e.Err.CorrelationID)
 	}
 
-	resp, err = NewResponse(r)
-	if err != nil {
-		return nil, err
+	switch {
+	case e.StatusCode == http.StatusNotFound && e.Message != "":
+		return e.Message + ids
+	case e.StatusCode == http.StatusNotFound:
+		return fmt.Sprintf("selector %s did not match any lights%s", e.Selector, ids)
 	}
+	return fmt.Sprintf("invalid selector %s: %s%s", e.Selector, e.Message, ids)
+}
 
-	return resp, nil
+func (e *SelectorError) Unwrap() error {
+	return e.Err
 }
 
-func (c *Client) setStates(selector string, states States) (*Response, error) {
-	var (
-		err  error
-		j    []byte
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-	)
+// NotFound reports whether the selector was valid but matched nothing.
+func (e *SelectorError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// decode reads the body as JSON into v, keeping a copy of the start of the
+// body to report if it cannot be decoded.
+func (r *Response) decode(v interface{}, strict bool) error {
+	var head bytes.Buffer
 
-	if j, err = json.Marshal(states); err != nil {
-		return nil, err
+	dec := json.NewDecoder(io.TeeReader(r.Body, &limitedWriter{&head, maxDecodeErrorBody}))
+	if strict {
+		dec.DisallowUnknownFields()
 	}
+	if err := dec.Decode(v); err != nil {
+		io.Copy(&limitedWriter{&head, maxDecodeErrorBody}, io.LimitReader(r.Body, maxDecodeErrorBody))
 
-	if req, err = c.NewRequest("PUT", EndpointStates(), bytes.NewBuffer(j)); err != nil {
-		return nil, err
+		e := &DecodeError{StatusCode: r.StatusCode, Body: head.Bytes(), Err: err}
+		if r.Request != nil {
+			e.Method, e.URL = r.Request.Method, r.Request.URL.String()
+			e.RequestID, e.CorrelationID = requestIDs(r.Request)
+		}
+		return e
 	}
+	return nil
+}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+// decode applies the client's decoding mode to resp.decode.
+func (c *Client) decode(resp *Response, v interface{}) error {
+	if c.decoding == DecodeLenient {
+		return resp.decode(v, false)
 	}
 
-	resp, err = NewResponse(r)
+	// The body may need decoding a second time.
+	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return resp, nil
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	err = resp.decode(v, true)
+	if c.decoding == DecodeWarn && unknownField(err) {
+		c.logf("lifx: %s", err)
+		resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+		return resp.decode(v, false)
+	}
+	return err
 }
 
-func (c *Client) toggle(selector string, duration float64) (*Response, error) {
-	var (
-		err  error
-		j    []byte
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-	)
-
-	if j, err = json.Marshal(&Toggle{Duration: duration}); err != nil {
-		return nil, err
+// decodeResults decodes the response to a call that changes lights. The
+// API answers 207 Multi-Status whenever it reports on several lights,
+// whether or not they all succeeded, so success is only known per result.
+// Fast calls are answered 202 with no body, leaving v untouched.
+func (c *Client) decodeResults(resp *Response, v interface{}) error {
+	if resp.IsError() {
+		return resp.GetLifxError()
+	}
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+	if err := c.decode(resp, v); err != nil {
+		return err
 	}
 
-	if req, err = c.NewRequest("POST", EndpointToggle(selector), bytes.NewBuffer(j)); err != nil {
-		return nil, err
+	var warnings []Warning
+	switch r := v.(type) {
+	case **LifxResponse:
+		if *r != nil {
+			warnings = (*r).Warnings
+		}
+	case **SetStatesResponse:
+		if *r != nil {
+			warnings = (*r).Warnings
+		}
 	}
+	c.logWarnings(resp, warnings)
+	return nil
+}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+// logWarnings passes the warnings the API attached to a response, such as
+// for parameters it did not recognize, to the client's logger.
+func (c *Client) logWarnings(resp *Response, warnings []Warning) {
+	for _, w := range warnings {
+		if resp.Request != nil {
+			c.logf("lifx: %s %s: warning: %s%s", resp.Request.Method, resp.Request.URL.Path, w, idSuffix(requestIDs(resp.Request)))
+		} else {
+			c.logf("lifx: warning: %s", w)
+		}
 	}
+}
 
-	resp, err = NewResponse(r)
-	if err != nil {
-		return nil, err
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
 	}
+}
 
-	return resp, nil
+// unknownField reports whether err is from a strict decoder meeting a field
+// it has no place for. encoding/json only describes these in the message.
+func unknownField(err error) bool {
+	var e *DecodeError
+	return errors.As(err, &e) && strings.HasPrefix(e.Err.Error(), "json: unknown field")
 }
 
-func (c *Client) validateColor(color Color) (*Response, error) {
-	var (
-		err  error
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-		q    url.Values
-	)
+func (e *DecodeError) Error() string {
+	var b strings.Builder
 
-	if req, err = c.NewRequest("GET", EndpointColor(), nil); err != nil {
-		return nil, err
+	b.WriteString("decoding response")
+	if e.URL != "" {
+		fmt.Fprintf(&b, " to %s %s", e.Method, e.URL)
+	}
+	fmt.Fprintf(&b, " (status %d): %s", e.StatusCode, e.Err)
+	if len(e.Body) > 0 {
+		fmt.Fprintf(&b, ": body %q", e.Body)
+		if len(e.Body) == maxDecodeErrorBody {
+			b.WriteString("...")
+		}
 	}
+	b.WriteString(idSuffix(e.RequestID, e.CorrelationID))
+	return b.String()
+}
 
-	q = req.URL.Query()
-	q.Set("string", color.ColorString())
-	req.URL.RawQuery = q.Encode()
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error() + idSuffix(e.RequestID, e.CorrelationID)
+}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// limitedWriter keeps the first n bytes written to it and discards the
+// rest without failing.
+type limitedWriter struct {
+	buf *bytes.Buffer
+	n   int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.n - w.buf.Len(); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		w.buf.Write(p[:room])
 	}
+	return len(p), nil
+}
 
-	resp, err = NewResponse(r)
-	if err != nil {
-		return nil, err
+func (w Warning) String() string {
+	return w.Warning
+}
+
+// Failed lists the lights that did not apply the change, such as ones that
+// are offline or timed out.
+func (r *LifxResponse) Failed() []Result {
+	if r == nil {
+		return nil
 	}
+	return failed(r.Results)
+}
 
-	return resp, nil
+// OK reports whether every light applied the change.
+func (r *LifxResponse) OK() bool {
+	return len(r.Failed()) == 0
 }
 
-func (c *Client) listLights(selector string) (*Response, error) {
-	var (
-		err  error
-		req  *http.Request
-		r    *http.Response
-		resp *Response
-	)
+// ToggleResult splits the response to a Toggle by each light's new power.
+func (r *LifxResponse) ToggleResult() ToggleResult {
+	var t ToggleResult
+	if r == nil {
+		return t
+	}
+	for _, res := range r.Results {
+		switch {
+		case !res.Status.IsOK():
+			t.Failed = append(t.Failed, res)
+		case res.Power == "on":
+			t.On = append(t.On, res)
+		case res.Power == "off":
+			t.Off = append(t.Off, res)
+		}
+	}
+	return t
+}
 
-	if req, err = c.NewRequest("GET", EndpointListLights(selector), nil); err != nil {
-		return nil, err
+func failed(results []Result) []Result {
+	var f []Result
+	for _, res := range results {
+		if !res.Status.IsOK() {
+			f = append(f, res)
+		}
 	}
+	return f
+}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+func (c *Client) NewRequest(method, url string, body io.Reader) (req *http.Request, err error) {
+	// The endpoint helpers build URLs from the package-wide Endpoint, which
+	// is swapped for the client's own here.
+	if c.endpoint != "" && strings.HasPrefix(url, Endpoint) {
+		url = c.endpoint + strings.TrimPrefix(url, Endpoint)
 	}
 
-	resp, err = NewResponse(r)
+	req, err = http.NewRequest(method, url, body)
 	if err != nil {
-		return nil, err
+		return
 	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", c.userAgent)
+	setRequestIDs(req, c.correlation)
+	return
+}
 
-	return resp, nil
+// Do sends a request built with NewRequest within the client's rate limit,
+// timeout and retry policy, for calls the package has no method for.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req)
 }
 
-func (c *Client) stateDelta(selector string, delta StateDelta) (*Response, error) {
+// doRequest is the path every API call takes. It sends body, if there is
+// one, as JSON and decodes the response into a T, applying the client's
+// error, status and decoding handling. The body is always drained and
+// closed so the connection can be reused.
+func doRequest[T any](c *Client, method, url string, body interface{}) (v T, err error) {
 	var (
-		err  error
-		j    []byte
-		req  *http.Request
-		r    *http.Response
-		resp *Response
+		b   io.Reader
+		req *http.Request
+		r   *http.Response
 	)
 
-	if j, err = json.Marshal(delta); err != nil {
-		return nil, err
+	defer func() {
+		if err != nil {
+			op := method + " " + url
+			if req != nil {
+				op = method + " " + req.URL.Path
+			}
+			c.reportError(op, err)
+		}
+	}()
+
+	if body != nil {
+		j, err := json.Marshal(body)
+		if err != nil {
+			return v, err
+		}
+		// A bytes.Reader lets retries rewind the body.
+		b = bytes.NewReader(j)
 	}
 
-	if req, err = c.NewRequest("POST", EndpointStateDelta(selector), bytes.NewBuffer(j)); err != nil {
-		return nil, err
+	req, err = c.NewRequest(method, url, b)
+	if err != nil {
+		return v, err
 	}
 
-	if r, err = c.Client.Do(req); err != nil {
-		return nil, err
+	if r, err = c.do(req); err != nil {
+		return v, err
 	}
+	defer func() {
+		io.Copy(ioutil.Discard, r.Body)
+		r.Body.Close()
+	}()
 
-	resp, err = NewResponse(r)
+	resp, err := NewResponse(r)
 	if err != nil {
-		return nil, err
+		return v, err
 	}
 
-	return resp, nil
+	err = c.decodeResults(resp, &v)
+	return v, err
 }
 
 func initUserAgent() string {
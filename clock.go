@@ -0,0 +1,65 @@
+package lifx
+
+import "time"
+
+type (
+	// Clock is the source of time for the client's retries and product
+	// cache, watchers, stores, caches, queues and the scheduler, so that
+	// tests can move time along instead of sleeping. lifxtest.Clock is one
+	// such.
+	Clock interface {
+		Now() time.Time
+		NewTimer(d time.Duration) Timer
+		NewTicker(d time.Duration) Ticker
+	}
+
+	Timer interface {
+		C() <-chan time.Time
+		Stop() bool
+	}
+
+	Ticker interface {
+		C() <-chan time.Time
+		Stop()
+	}
+
+	systemClock  struct{}
+	systemTimer  struct{ t *time.Timer }
+	systemTicker struct{ t *time.Ticker }
+)
+
+// SystemClock is the real time, which everything uses by default.
+var SystemClock Clock = systemClock{}
+
+// WithClock has the client's retries and product cache use clock.
+func WithClock(clock Clock) func(*Client) {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+func (c *Client) now() time.Time {
+	return clockOrSystem(c.clock).Now()
+}
+
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (t systemTimer) C() <-chan time.Time  { return t.t.C }
+func (t systemTimer) Stop() bool           { return t.t.Stop() }
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
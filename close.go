@@ -0,0 +1,171 @@
+package lifx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClientClosed is returned by requests made after Close.
+var ErrClientClosed = errors.New("client is closed")
+
+// restartDelay is how long Start waits before running a loop that
+// panicked again.
+const restartDelay = time.Second
+
+// lifecycle is the part of a client that Close acts on. Copies made with
+// With share it, so closing any of them closes them all.
+type lifecycle struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	closed  bool
+	done    bool
+	loops   sync.WaitGroup
+	calls   sync.WaitGroup
+	flushes []func()
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Context is done once Close is called. Background loops the client does
+// not start itself should run with it.
+func (c *Client) Context() context.Context {
+	if c.life == nil {
+		return context.Background()
+	}
+	return c.life.ctx
+}
+
+// Start runs fn in its own goroutine with the client's Context, so that
+// Close stops it and waits for it to return. It suits the Run methods of
+// watchers, stores, schedulers, queues and health monitors:
+//
+//	c.Start(store.Run)
+//	c.Start(func(ctx context.Context) error { return q.Run(ctx, time.Minute) })
+//
+// Errors fn returns other than its context's are passed to the client's
+// ErrorHandler. If fn panics, the panic is passed on as a *PanicError and
+// fn is started again after a second, so one bad event does not stop a
+// loop for good.
+func (c *Client) Start(fn func(ctx context.Context) error) {
+	if c.life == nil {
+		go fn(context.Background())
+		return
+	}
+
+	l := c.life
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+
+	l.loops.Add(1)
+	go func() {
+		defer l.loops.Done()
+		for {
+			err := safely(func() error { return fn(l.ctx) })
+			if err != nil && !errors.Is(err, l.ctx.Err()) {
+				c.reportError("background", err)
+			}
+			if _, ok := err.(*PanicError); !ok {
+				return
+			}
+
+			t := clockOrSystem(c.clock).NewTimer(restartDelay)
+			select {
+			case <-l.ctx.Done():
+				t.Stop()
+				return
+			case <-t.C():
+			}
+		}
+	}()
+}
+
+// OnClose has Close call fn once every loop from Start has stopped and
+// while the client can still make requests, for flushing work such as an
+// UpdateCoalescer's or a Queue's.
+func (c *Client) OnClose(fn func()) {
+	if c.life == nil {
+		return
+	}
+
+	c.life.mu.Lock()
+	c.life.flushes = append(c.life.flushes, fn)
+	c.life.mu.Unlock()
+}
+
+// Close stops the loops started with Start, runs the OnClose functions,
+// waits for calls made with the Async methods and closes idle
+// connections. Requests made afterwards fail with ErrClientClosed. Closing
+// a client more than once does nothing.
+func (c *Client) Close() error {
+	l := c.life
+	if l == nil {
+		c.Client.CloseIdleConnections()
+		return nil
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	flushes := l.flushes
+	l.mu.Unlock()
+
+	l.cancel()
+	l.loops.Wait()
+	for _, fn := range flushes {
+		fn()
+	}
+	l.calls.Wait()
+
+	l.mu.Lock()
+	l.done = true
+	l.mu.Unlock()
+
+	c.Client.CloseIdleConnections()
+	return nil
+}
+
+// isClosed reports whether Close has finished. The flushes it runs on the
+// way can still make requests.
+func (c *Client) isClosed() bool {
+	if c.life == nil {
+		return false
+	}
+
+	c.life.mu.Lock()
+	defer c.life.mu.Unlock()
+	return c.life.done
+}
+
+// track counts an Async call for Close to wait for, unless Close has
+// begun.
+func (c *Client) track() bool {
+	if c.life == nil {
+		return true
+	}
+
+	c.life.mu.Lock()
+	defer c.life.mu.Unlock()
+	if c.life.closed {
+		return false
+	}
+	c.life.calls.Add(1)
+	return true
+}
+
+func (c *Client) untrack() {
+	if c.life != nil {
+		c.life.calls.Done()
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lan"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+type bulb struct {
+	Id       string `json:"id"`
+	Label    string `json:"label"`
+	Group    string `json:"group"`
+	Location string `json:"location"`
+	Power    string `json:"power"`
+	Color    string `json:"color"`
+}
+
+func main() {
+	var (
+		httpAddr = flag.String("http", "127.0.0.1:8080", "address to serve the HTTP API on, empty to disable")
+		lanAddr  = flag.String("lan", fmt.Sprintf(":%d", lan.DefaultPort), "address to serve the LAN protocol on, empty to disable")
+		config   = flag.String("config", "", "JSON file describing the bulbs to emulate")
+		count    = flag.Int("bulbs", 3, "number of bulbs to emulate when no config is given")
+		token    = flag.String("token", "", "access token the HTTP API requires, empty to accept any")
+
+		latency     = flag.Duration("latency", 0, "delay added to every HTTP request and LAN reply")
+		jitter      = flag.Duration("jitter", 0, "random extra delay of up to this much per HTTP request")
+		errorRate   = flag.Float64("error-rate", 0, "fraction of HTTP requests answered with a 5xx error")
+		limitRate   = flag.Float64("rate-limit-rate", 0, "fraction of HTTP requests answered with 429 Too Many Requests")
+		timeoutRate = flag.Float64("timeout-rate", 0, "fraction of lights reporting timed_out in each result")
+		dropRate    = flag.Float64("drop-rate", 0, "fraction of LAN messages ignored")
+		offline     = flag.String("offline", "", "comma-separated ids of bulbs that are offline")
+		timedOut    = flag.String("timed-out", "", "comma-separated ids of bulbs that always time out")
+	)
+	flag.Parse()
+
+	lights, err := loadLights(*config, *count)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := lifxtest.NewUnstartedServer(lights...)
+	srv.Token = *token
+	srv.SetFaults(lifxtest.Faults{
+		Latency:       *latency,
+		Jitter:        *jitter,
+		ErrorRate:     *errorRate,
+		RateLimitRate: *limitRate,
+		TimeoutRate:   *timeoutRate,
+	})
+	for _, id := range splitIds(*offline) {
+		srv.SetStatus(id, lifx.Offline)
+	}
+	for _, id := range splitIds(*timedOut) {
+		srv.SetStatus(id, lifx.TimedOut)
+	}
+
+	if *httpAddr != "" {
+		l, err := net.Listen("tcp", *httpAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.Listener.Close()
+		srv.Listener = l
+		srv.Start()
+		defer srv.Close()
+		log.Printf("serving the HTTP API at %s/v1", srv.URL)
+	}
+
+	if *lanAddr != "" {
+		conn, err := net.ListenPacket("udp", *lanAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+
+		ids := make([]string, len(lights))
+		for i, l := range lights {
+			ids[i] = l.Id
+		}
+
+		e := lan.NewEmulator(srv, ids...)
+		e.Latency = *latency
+		e.DropRate = *dropRate
+
+		go func() {
+			if err := e.Serve(conn); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("serving the LAN protocol on %s", conn.LocalAddr())
+	}
+
+	for _, l := range lights {
+		log.Printf("emulating %s (%s) in %s/%s", l.Label, l.Id, l.Location.Name, l.Group.Name)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+}
+
+func loadLights(config string, count int) ([]lifx.Light, error) {
+	var bulbs []bulb
+
+	if config == "" {
+		for i := 1; i <= count; i++ {
+			bulbs = append(bulbs, bulb{
+				Id:       fmt.Sprintf("d073d5%06x", i),
+				Label:    fmt.Sprintf("Bulb %d", i),
+				Group:    "Emulated",
+				Location: "Home",
+			})
+		}
+	} else {
+		b, err := ioutil.ReadFile(config)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &bulbs); err != nil {
+			return nil, err
+		}
+	}
+
+	lights := make([]lifx.Light, len(bulbs))
+	for i, b := range bulbs {
+		l := lifxtest.NewLight(b.Id, b.Label, b.Group, b.Location)
+		if b.Power != "" {
+			l.Power = b.Power
+		}
+		if b.Color != "" {
+			c, err := lifx.ParseColor(b.Color)
+			if err != nil {
+				return nil, fmt.Errorf("bulb %s: %w", b.Label, err)
+			}
+			l.Color = mergeColor(l.Color, c)
+			if c.B != nil {
+				l.Brightness = float64(*c.B)
+			}
+		}
+		lights[i] = l
+	}
+
+	return lights, nil
+}
+
+func mergeColor(base, c lifx.HSBKColor) lifx.HSBKColor {
+	if c.H != nil {
+		base.H = c.H
+	}
+	if c.S != nil {
+		base.S = c.S
+	}
+	if c.B != nil {
+		base.B = c.B
+	}
+	if c.K != nil {
+		base.K = c.K
+	}
+	return base
+}
+
+func splitIds(s string) []string {
+	var ids []string
+
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
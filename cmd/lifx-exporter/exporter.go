@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	exporter struct {
+		lister   *lifx.CachedLister
+		selector string
+	}
+
+	// metrics writes the Prometheus text exposition format, grouping
+	// samples under one HELP and TYPE line per metric.
+	metrics struct {
+		bytes.Buffer
+	}
+)
+
+var lightLabels = []string{"id", "label", "group", "location"}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var m metrics
+
+	start := time.Now()
+	lights, err := e.lister.ListLights(e.selector)
+	duration := time.Since(start)
+
+	up := 1.0
+	if err != nil {
+		log.Printf("listing lights: %s", err)
+		up = 0
+	}
+
+	m.metric("lifx_up", "Whether the last request to the LIFX API succeeded.", "gauge")
+	m.sample("lifx_up", nil, up)
+	m.metric("lifx_scrape_duration_seconds", "Time taken to list the lights, near zero when served from the cache.", "gauge")
+	m.sample("lifx_scrape_duration_seconds", nil, duration.Seconds())
+	if age, ok := e.lister.Age(e.selector); ok {
+		m.metric("lifx_cache_age_seconds", "Age of the light states being reported.", "gauge")
+		m.sample("lifx_cache_age_seconds", nil, age.Seconds())
+	}
+
+	if err == nil {
+		e.lights(&m, lights)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(m.Bytes())
+}
+
+func (e *exporter) lights(m *metrics, lights []lifx.Light) {
+	sort.Slice(lights, func(i, j int) bool { return lights[i].Id < lights[j].Id })
+
+	gauges := []struct {
+		name, help string
+		value      func(lifx.Light) (float64, bool)
+	}{
+		{"lifx_light_power", "Whether the light is on.", func(l lifx.Light) (float64, bool) {
+			return boolValue(l.Power == "on"), true
+		}},
+		{"lifx_light_connected", "Whether the light is reachable by the LIFX cloud.", func(l lifx.Light) (float64, bool) {
+			return boolValue(l.Connected), true
+		}},
+		{"lifx_light_brightness", "Brightness between 0 and 1.", func(l lifx.Light) (float64, bool) {
+			return l.Brightness, true
+		}},
+		{"lifx_light_kelvin", "White color temperature in kelvin.", func(l lifx.Light) (float64, bool) {
+			if l.Color.K == nil {
+				return 0, false
+			}
+			return float64(*l.Color.K), true
+		}},
+		{"lifx_light_hue", "Hue in degrees.", func(l lifx.Light) (float64, bool) {
+			if l.Color.H == nil {
+				return 0, false
+			}
+			return float64(*l.Color.H), true
+		}},
+		{"lifx_light_saturation", "Saturation between 0 and 1.", func(l lifx.Light) (float64, bool) {
+			if l.Color.S == nil {
+				return 0, false
+			}
+			return float64(*l.Color.S), true
+		}},
+		{"lifx_light_seconds_since_seen", "Seconds since the LIFX cloud last heard from the light.", func(l lifx.Light) (float64, bool) {
+			return l.StaleFor().Seconds(), true
+		}},
+	}
+
+	for _, g := range gauges {
+		m.metric(g.name, g.help, "gauge")
+		for _, l := range lights {
+			if v, ok := g.value(l); ok {
+				m.sample(g.name, labelValues(l), v)
+			}
+		}
+	}
+
+	m.metric("lifx_light_info", "Static information about the light, always 1.", "gauge")
+	for _, l := range lights {
+		m.sample("lifx_light_info", append(labelValues(l), "product", l.Product.Name, "effect", l.Effect.String()), 1)
+	}
+
+	var on, connected int
+	groups, locations := map[string]bool{}, map[string]bool{}
+	for _, l := range lights {
+		if l.Power == "on" {
+			on++
+		}
+		if l.Connected {
+			connected++
+		}
+		groups[l.Group.Id] = true
+		locations[l.Location.Id] = true
+	}
+
+	for _, c := range []struct {
+		name, help string
+		value      int
+	}{
+		{"lifx_lights", "Number of lights on the account.", len(lights)},
+		{"lifx_lights_on", "Number of lights that are on.", on},
+		{"lifx_lights_connected", "Number of lights that are connected.", connected},
+		{"lifx_groups", "Number of groups.", len(groups)},
+		{"lifx_locations", "Number of locations.", len(locations)},
+	} {
+		m.metric(c.name, c.help, "gauge")
+		m.sample(c.name, nil, float64(c.value))
+	}
+}
+
+func labelValues(l lifx.Light) []string {
+	return []string{lightLabels[0], l.Id, lightLabels[1], l.Label, lightLabels[2], l.Group.Name, lightLabels[3], l.Location.Name}
+}
+
+func (m *metrics) metric(name, help, typ string) {
+	fmt.Fprintf(m, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one value. labels alternates names and values.
+func (m *metrics) sample(name string, labels []string, v float64) {
+	m.WriteString(name)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", labels[i], quote(labels[i+1])))
+		}
+		m.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	m.WriteString(" " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
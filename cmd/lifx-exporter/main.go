@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/config"
+)
+
+func main() {
+	var (
+		listen   = flag.String("listen", ":9776", "address to serve metrics on")
+		path     = flag.String("config", "", "client configuration file, defaults to $LIFX_CONFIG")
+		selector = flag.String("selector", lifx.All().String(), "lights to export")
+		ttl      = flag.Duration("cache", 30*time.Second, "how long API responses are reused, which bounds the request rate however often Prometheus scrapes")
+	)
+	flag.Parse()
+
+	c, err := config.New(*path, lifx.WithUserAgent("lifx-exporter/"+lifx.Version))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	e := &exporter{lister: lifx.NewCachedLister(c, *ttl), selector: *selector}
+
+	http.Handle("/metrics", e)
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/metrics", http.StatusFound)
+	})
+
+	log.Printf("serving metrics on %s/metrics", *listen)
+	log.Fatal(http.ListenAndServe(*listen, nil))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/config"
+)
+
+func main() {
+	var (
+		listen = flag.String("listen", "127.0.0.1:8081", "address to serve the API on")
+		path   = flag.String("config", "", "client configuration file, defaults to $LIFX_CONFIG")
+		token  = flag.String("token", "", "access token local clients must send, empty to accept any")
+		ttl    = flag.Duration("cache", 5*time.Second, "how long GET responses are shared, zero to only coalesce concurrent requests")
+	)
+	flag.Parse()
+
+	conf, err := config.Load(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Every consumer shares the one token, so the proxy always paces and
+	// retries requests even when the configuration does not ask for it.
+	// Forwarded POSTs are only retried when that can't apply them twice.
+	if conf.RateLimit == nil {
+		conf.RateLimit = &config.RateLimit{}
+	}
+	if conf.Retry == nil {
+		conf.Retry = &config.Retry{
+			Attempts:   lifx.DefaultRetryPolicy.Attempts,
+			Backoff:    config.Duration(lifx.DefaultRetryPolicy.Backoff),
+			MaxBackoff: config.Duration(lifx.DefaultRetryPolicy.MaxBackoff),
+		}
+	}
+
+	c, err := conf.NewClient(lifx.WithUserAgent("lifx-proxy/" + lifx.Version))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("serving the LIFX API on %s/v1", *listen)
+	log.Fatal(http.ListenAndServe(*listen, newProxy(c, *token, *ttl)))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const apiPrefix = "/v1"
+
+type (
+	// proxy serves the LIFX HTTP API by forwarding to it with its own
+	// token. GET responses are shared between callers for a while, and
+	// identical GETs in flight at the same time are sent upstream once.
+	// Anything else empties the cache, since it may change what a GET
+	// returns.
+	proxy struct {
+		client   *lifx.Client
+		token    string
+		ttl      time.Duration
+		mu       sync.Mutex
+		cache    map[string]*response
+		inflight map[string]*fetch
+	}
+
+	response struct {
+		status  int
+		header  http.Header
+		body    []byte
+		fetched time.Time
+	}
+
+	fetch struct {
+		done chan struct{}
+		resp *response
+		err  error
+	}
+)
+
+// forwarded are the upstream response headers passed back to callers.
+var forwarded = []string{"Content-Type", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+func newProxy(c *lifx.Client, token string, ttl time.Duration) *proxy {
+	return &proxy{
+		client:   c,
+		token:    token,
+		ttl:      ttl,
+		cache:    make(map[string]*response),
+		inflight: make(map[string]*fetch),
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !p.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Bad access token")
+		return
+	}
+	if r.URL.Path != apiPrefix && !strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var (
+		resp  *response
+		cache string
+		err   error
+	)
+	if r.Method == http.MethodGet {
+		resp, cache, err = p.get(r.URL.RequestURI())
+	} else {
+		resp, err = p.forward(r)
+		cache = "BYPASS"
+		if err == nil && resp.status < 300 {
+			p.invalidate()
+		}
+	}
+	if err != nil {
+		log.Printf("%s %s: %s", r.Method, r.URL.Path, err)
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	for _, h := range forwarded {
+		if v := resp.header.Get(h); v != "" {
+			w.Header().Set(h, v)
+		}
+	}
+	w.Header().Set("X-Cache", cache)
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+func (p *proxy) authorized(r *http.Request) bool {
+	if p.token == "" {
+		return true
+	}
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(given), []byte(p.token)) == 1
+}
+
+// get answers from the cache, from a request already in flight for the
+// same URI, or by sending a new one, and reports which it was.
+func (p *proxy) get(uri string) (*response, string, error) {
+	p.mu.Lock()
+	if resp, ok := p.cache[uri]; ok && time.Since(resp.fetched) < p.ttl {
+		p.mu.Unlock()
+		return resp, "HIT", nil
+	}
+	if f, ok := p.inflight[uri]; ok {
+		p.mu.Unlock()
+		<-f.done
+		return f.resp, "COALESCED", f.err
+	}
+
+	f := &fetch{done: make(chan struct{})}
+	p.inflight[uri] = f
+	p.mu.Unlock()
+
+	f.resp, f.err = p.send(http.MethodGet, uri, nil)
+
+	p.mu.Lock()
+	delete(p.inflight, uri)
+	if f.err == nil && f.resp.status == http.StatusOK && p.ttl > 0 {
+		p.cache[uri] = f.resp
+	}
+	p.mu.Unlock()
+	close(f.done)
+
+	return f.resp, "MISS", f.err
+}
+
+func (p *proxy) forward(r *http.Request) (*response, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return p.send(r.Method, r.URL.RequestURI(), body)
+}
+
+func (p *proxy) send(method, uri string, body []byte) (*response, error) {
+	var b io.Reader
+	if body != nil {
+		// A bytes.Reader lets the client rewind the body to retry.
+		b = bytes.NewReader(body)
+	}
+
+	req, err := p.client.NewRequest(method, lifx.Endpoint+strings.TrimPrefix(uri, apiPrefix), b)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if body, err = ioutil.ReadAll(r.Body); err != nil {
+		return nil, err
+	}
+
+	return &response{
+		status:  r.StatusCode,
+		header:  r.Header,
+		body:    body,
+		fetched: time.Now(),
+	}, nil
+}
+
+func (p *proxy) invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cache = make(map[string]*response)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "{\"error\":%q}\n", msg)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/credentials"
+)
+
+func auth(_ *lifx.Client, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	if err := configure(global); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "login":
+		return login(args[1:])
+	case "logout":
+		if len(args) != 1 {
+			return errUsage
+		}
+		if err := credentials.Delete(account); err != nil {
+			return err
+		}
+		fmt.Printf("removed the token for profile %s\n", account)
+		return nil
+	case "status":
+		if len(args) != 1 {
+			return errUsage
+		}
+		_, err := credentials.Get(account)
+		switch {
+		case errors.Is(err, credentials.ErrNotFound):
+			fmt.Printf("no token is stored for profile %s\n", account)
+		case err != nil:
+			return err
+		default:
+			fmt.Printf("a token is stored for profile %s\n", account)
+		}
+		return nil
+	}
+	return errUsage
+}
+
+// login stores a token for the current profile after checking that the
+// API accepts it. The token is read from standard input, without echo when
+// that is a terminal, so it does not end up in the shell history.
+func login(args []string) error {
+	fs := flag.NewFlagSet("auth login", flag.ContinueOnError)
+	verify := fs.Bool("verify", true, "check the token against the API before storing it")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	token, err := readToken()
+	if err != nil {
+		return err
+	}
+
+	if *verify {
+		if _, err := lifx.NewClient(token, clientOptions()...).ListLights(lifx.All().String()); err != nil {
+			return fmt.Errorf("the token was not accepted: %w", err)
+		}
+	}
+
+	if err = credentials.Set(account, token); err != nil {
+		return err
+	}
+	fmt.Printf("stored the token for profile %s\n", account)
+	return nil
+}
+
+func readToken() (string, error) {
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "access token: ")
+		if restore, err := noEcho(); err == nil {
+			defer func() {
+				restore()
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no token was given")
+	}
+
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", errors.New("no token was given")
+	}
+	return token, nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func parse(fs *flag.FlagSet, sf *selectorFlags, args []string) (string, error) {
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		return "", errUsage
+	}
+	if err := validOutput(output); err != nil {
+		return "", err
+	}
+	return sf.selector(fs.Args())
+}
+
+func list(c *lifx.Client, args []string) error {
+	fs, sf := newFlagSet("list")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	lights, err := c.ListLights(selector)
+	if err != nil {
+		return err
+	}
+
+	records := make([]record, len(lights))
+	for i, l := range lights {
+		records[i] = lightRecord(l)
+	}
+	return writeRecords(records)
+}
+
+func lightRecord(l lifx.Light) record {
+	return record{
+		{name: "id", value: l.Id},
+		{name: "label", value: l.Label},
+		{name: "power", value: l.Power},
+		{name: "brightness", value: l.Brightness},
+		{name: "color", value: l.Color.ColorString()},
+		{name: "group", value: l.Group.Name},
+		{name: "location", value: l.Location.Name},
+		{name: "connected", value: l.Connected},
+		{name: "effect", value: l.Effect, wide: true},
+		{name: "product", value: l.Product.Name, wide: true},
+		{name: "group_id", value: l.Group.Id, wide: true},
+		{name: "location_id", value: l.Location.Id, wide: true},
+		{name: "last_seen", value: l.LastSeen, wide: true},
+	}
+}
+
+func power(state string) func(*lifx.Client, []string) error {
+	return func(c *lifx.Client, args []string) error {
+		fs, sf := newFlagSet(state)
+		duration := fs.Float64("duration", settings.Duration, "transition time in seconds")
+		selector, err := parse(fs, sf, args)
+		if err != nil {
+			return err
+		}
+
+		r, err := c.SetState(selector, lifx.State{Power: state, Duration: *duration})
+		if err != nil {
+			return err
+		}
+		return printResults(r)
+	}
+}
+
+func toggle(c *lifx.Client, args []string) error {
+	fs, sf := newFlagSet("toggle")
+	duration := fs.Float64("duration", settings.Duration, "transition time in seconds")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	r, err := c.Toggle(selector, *duration)
+	if err != nil {
+		return err
+	}
+	return printResults(r)
+}
+
+func set(c *lifx.Client, args []string) error {
+	var (
+		state lifx.State
+		color []string
+	)
+
+	fs, sf := newFlagSet("set")
+	fs.Func("color", "color such as red, #ff8000, hue:120 saturation:0.5 or rgb:0,0,255", func(v string) error {
+		color = append(color, v)
+		return nil
+	})
+	fs.Func("brightness", "brightness between 0.0 and 1.0", func(v string) error {
+		color = append(color, "brightness:"+v)
+		return nil
+	})
+	fs.Func("kelvin", "white temperature between 1500 and 9000", func(v string) error {
+		color = append(color, "kelvin:"+v)
+		return nil
+	})
+	fs.StringVar(&state.Power, "power", "", "also turn the lights on or off")
+	fs.Float64Var(&state.Duration, "duration", settings.Duration, "transition time in seconds")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	if len(color) == 0 && state.Power == "" {
+		return errUsage
+	}
+	if len(color) > 0 {
+		state.Color = lifx.NamedColor(strings.Join(color, " "))
+	}
+
+	r, err := c.SetState(selector, state)
+	if err != nil {
+		return err
+	}
+	return printResults(r)
+}
+
+func breathe(c *lifx.Client, args []string) error {
+	var color, from string
+
+	b := lifx.NewBreathe()
+	fs, sf := newFlagSet("breathe")
+	fs.StringVar(&color, "color", "", "color to breathe to")
+	fs.StringVar(&from, "from", "", "color to start from, defaults to the current color")
+	fs.Float64Var(&b.Period, "period", b.Period, "seconds per cycle")
+	fs.Float64Var(&b.Cycles, "cycles", b.Cycles, "number of cycles")
+	fs.Float64Var(&b.Peak, "peak", b.Peak, "where in the period the target color is reached, between 0.0 and 1.0")
+	fs.BoolVar(&b.Persist, "persist", b.Persist, "keep the final color when the effect ends")
+	fs.BoolVar(&b.PowerOn, "power-on", b.PowerOn, "turn lights on if they are off")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	if color == "" {
+		return errUsage
+	}
+	b.Color = lifx.NamedColor(color)
+	if from != "" {
+		b.FromColor = lifx.NamedColor(from)
+	}
+
+	r, err := c.Breathe(selector, b)
+	if err != nil {
+		return err
+	}
+	return printResults(r)
+}
+
+func printResults(r *lifx.LifxResponse) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, warn := range r.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warn.Warning)
+	}
+
+	// Only toggling reports the power lights were left with.
+	power := false
+	for _, res := range r.Results {
+		if res.Power != "" {
+			power = true
+		}
+	}
+
+	records := make([]record, len(r.Results))
+	for i, res := range r.Results {
+		records[i] = record{
+			{name: "id", value: res.Id},
+			{name: "label", value: res.Label},
+			{name: "status", value: string(res.Status)},
+		}
+		if power {
+			records[i] = append(records[i], field{name: "power", value: res.Power})
+		}
+	}
+	return writeRecords(records)
+}
+
+// printOperations prints a SetStates response, one line per light with the
+// selector that reached it.
+func printOperations(r *lifx.SetStatesResponse) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, warn := range r.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warn.Warning)
+	}
+
+	var records []record
+	for _, op := range r.Results {
+		for _, res := range op.Results {
+			records = append(records, record{
+				{name: "selector", value: op.Operation.Selector},
+				{name: "id", value: res.Id},
+				{name: "label", value: res.Label},
+				{name: "status", value: string(res.Status)},
+			})
+		}
+	}
+	return writeRecords(records)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+// completionTTL bounds how stale completed labels, groups and scenes may
+// be. Completion runs on every tab press, which would otherwise cost an
+// API request each time.
+var completionTTL = 5 * time.Minute
+
+type completionCache struct {
+	Time      time.Time `json:"time"`
+	Ids       []string  `json:"ids"`
+	Labels    []string  `json:"labels"`
+	Groups    []string  `json:"groups"`
+	Locations []string  `json:"locations"`
+	Scenes    []string  `json:"scenes"`
+}
+
+var (
+	globalFlags = map[string]bool{"-token": true, "-endpoint": true, "-config": true, "-profile": true, "-output": true, "-o": true}
+
+	colorNames = []string{"red", "orange", "yellow", "green", "cyan", "blue", "purple", "pink", "white"}
+
+	scripts = map[string]string{
+		"bash": `_lifx() {
+	local line="${COMP_LINE:0:COMP_POINT}" cur IFS
+	local -a words
+	read -ra words <<< "$line"
+	[[ $line == *" " ]] && words+=("")
+	cur="${words[${#words[@]}-1]}"
+	IFS=$'\n'
+	COMPREPLY=($(command lifx __complete "${words[@]:1}" 2>/dev/null))
+	# bash splits words at colons, so only the part after the last one is replaced.
+	if [[ $cur == *:* && $COMP_WORDBREAKS == *:* ]]; then
+		local prefix="${cur%"${cur##*:}"}"
+		COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+	fi
+}
+complete -o default -F _lifx lifx
+`,
+		"zsh": `#compdef lifx
+_lifx() {
+	local -a candidates
+	candidates=("${(@f)$(command lifx __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	compadd -- "${candidates[@]}"
+}
+compdef _lifx lifx
+`,
+		"fish": `function __lifx_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	command lifx __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c lifx -f -a '(__lifx_complete)'
+`,
+	}
+)
+
+func completion(_ *lifx.Client, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	script, ok := scripts[args[0]]
+	if !ok {
+		return errUsage
+	}
+	_, err := fmt.Print(script)
+	return err
+}
+
+// complete prints the candidates for the last of words, which are the
+// command line after the program name. Failures print nothing, since
+// there is nowhere useful to report them while the user is typing.
+func complete(_ *lifx.Client, words []string) error {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur, prev := words[len(words)-1], ""
+	if len(words) > 1 {
+		prev = words[len(words)-2]
+	}
+
+	var (
+		g    globals
+		args []string
+	)
+	for i := 0; i < len(words)-1; i++ {
+		if len(args) == 0 && globalFlags[words[i]] && i+1 < len(words)-1 {
+			switch words[i] {
+			case "-token":
+				g.token = words[i+1]
+			case "-endpoint":
+				g.endpoint = words[i+1]
+			case "-config":
+				g.config = words[i+1]
+			case "-profile":
+				g.profile = words[i+1]
+			}
+			i++
+			continue
+		}
+		args = append(args, words[i])
+	}
+	if g.profile == "" {
+		g.profile = os.Getenv("LIFX_PROFILE")
+	}
+	if g.endpoint == "" {
+		g.endpoint = os.Getenv("LIFX_ENDPOINT")
+	}
+
+	for _, c := range candidates(g, args, prev, cur) {
+		if strings.HasPrefix(c, cur) {
+			fmt.Println(c)
+		}
+	}
+	return nil
+}
+
+func candidates(g globals, args []string, prev, cur string) []string {
+	switch prev {
+	case "-output", "-o":
+		return outputs
+	case "-profile":
+		if conf, err := loadGlobalConfig(g); err == nil {
+			return conf.names()
+		}
+		return nil
+	case "-color", "-from":
+		return colorNames
+	case "-power":
+		return []string{"on", "off"}
+	case "-id":
+		return cached(g).Ids
+	case "-label":
+		return cached(g).Labels
+	case "-group":
+		return cached(g).Groups
+	case "-location":
+		return cached(g).Locations
+	}
+
+	if len(args) == 0 {
+		if strings.HasPrefix(cur, "-") {
+			return flagNames(globalFlags)
+		}
+		var names []string
+		for name := range commands {
+			if !strings.HasPrefix(name, "__") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	switch args[0] {
+	case "completion":
+		if len(args) == 1 {
+			return []string{"bash", "fish", "zsh"}
+		}
+		return nil
+	case "theme":
+		if len(args) == 1 {
+			return []string{"apply", "list"}
+		}
+		if args[1] == "apply" && len(args) == 2 && !strings.HasPrefix(cur, "-") {
+			if _, err := connect(g); err != nil {
+				return nil
+			}
+			palettes, err := themes()
+			if err != nil {
+				return nil
+			}
+			var names []string
+			for name := range palettes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return names
+		}
+	case "scenes":
+		if len(args) == 1 {
+			return []string{"activate", "list"}
+		}
+		if args[1] == "activate" && !strings.HasPrefix(cur, "-") {
+			return cached(g).Scenes
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		return []string{"-duration", "-group", "-id", "-label", "-location", "-output", "-random"}
+	}
+	return selectorCandidates(cached(g))
+}
+
+func selectorCandidates(c *completionCache) []string {
+	s := []string{"all"}
+	for _, v := range c.Ids {
+		s = append(s, lifx.ById(v).String())
+	}
+	for _, v := range c.Labels {
+		s = append(s, lifx.ByLabel(v).String())
+	}
+	for _, v := range c.Groups {
+		s = append(s, lifx.ByGroup(v).String())
+	}
+	for _, v := range c.Locations {
+		s = append(s, lifx.ByLocation(v).String())
+	}
+	return s
+}
+
+func flagNames(flags map[string]bool) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cached returns the account's lights and scenes, from the cache while it
+// is fresh and from the API otherwise. Each account and endpoint is cached
+// separately so that profiles do not see each other's lights.
+func cached(g globals) *completionCache {
+	var cache completionCache
+
+	c, err := connect(g)
+	if err != nil {
+		return &cache
+	}
+
+	path := completionCachePath()
+	if path != "" {
+		if b, err := ioutil.ReadFile(path); err == nil && json.Unmarshal(b, &cache) == nil && time.Since(cache.Time) < completionTTL {
+			return &cache
+		}
+	}
+
+	cache = completionCache{Time: time.Now()}
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return &cache
+	}
+	ids, labels, groups, locations := stringSet{}, stringSet{}, stringSet{}, stringSet{}
+	for _, l := range lights {
+		ids.add(l.Id)
+		labels.add(l.Label)
+		groups.add(l.Group.Name)
+		locations.add(l.Location.Name)
+	}
+	cache.Ids, cache.Labels, cache.Groups, cache.Locations = ids.sorted(), labels.sorted(), groups.sorted(), locations.sorted()
+
+	if scenes, err := c.ListScenes(); err == nil {
+		names := stringSet{}
+		for _, s := range scenes {
+			names.add(s.Name)
+		}
+		cache.Scenes = names.sorted()
+	}
+
+	if path != "" {
+		if b, err := json.Marshal(cache); err == nil && os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			ioutil.WriteFile(path, b, 0600)
+		}
+	}
+	return &cache
+}
+
+func completionCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	endpoint := settings.BaseURL
+	if endpoint == "" {
+		endpoint = lifx.Endpoint
+	}
+	sum := sha256.Sum256([]byte(endpoint + "\x00" + settings.Token))
+	return filepath.Join(dir, "lifx", "completion-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+type stringSet map[string]bool
+
+func (s stringSet) add(v string) {
+	if v != "" {
+		s[v] = true
+	}
+}
+
+func (s stringSet) sorted() []string {
+	v := make([]string, 0, len(s))
+	for k := range s {
+		v = append(v, k)
+	}
+	sort.Strings(v)
+	return v
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go/internal/miniyaml"
+)
+
+type (
+	// profile holds the settings for one account or home. Empty fields
+	// fall back to the environment and then to built-in defaults.
+	profile struct {
+		Token    string
+		BaseURL  string
+		Selector string
+		Duration float64
+	}
+
+	config struct {
+		DefaultProfile string
+		Profiles       map[string]profile
+
+		// Themes maps a name to its colors separated by semicolons.
+		Themes map[string]string
+	}
+)
+
+const defaultProfile = "default"
+
+func configPath() (string, error) {
+	if path := os.Getenv("LIFX_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lifx", "config.yaml"), nil
+}
+
+// loadConfig reads path, treating a missing file as an empty config.
+func loadConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &config{Profiles: map[string]profile{}, Themes: map[string]string{}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c, err := parseConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func parseConfig(r io.Reader) (*config, error) {
+	doc, err := miniyaml.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{Profiles: map[string]profile{}, Themes: map[string]string{}}
+	for k, v := range doc {
+		switch k {
+		case "default_profile":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("default_profile must be a string")
+			}
+			c.DefaultProfile = s
+		case "profiles":
+			profiles, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("profiles must be a mapping")
+			}
+			for name, v := range profiles {
+				p, err := parseProfile(v)
+				if err != nil {
+					return nil, fmt.Errorf("profile %s: %w", name, err)
+				}
+				c.Profiles[name] = p
+			}
+		case "themes":
+			themes, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("themes must be a mapping")
+			}
+			for name, v := range themes {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("theme %s must be a list of colors separated by semicolons", name)
+				}
+				c.Themes[name] = s
+			}
+		default:
+			return nil, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	return c, nil
+}
+
+func parseProfile(v interface{}) (profile, error) {
+	var p profile
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return p, fmt.Errorf("must be a mapping")
+	}
+
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return p, fmt.Errorf("%s must be a scalar", k)
+		}
+
+		switch k {
+		case "token":
+			p.Token = s
+		case "base_url":
+			p.BaseURL = s
+		case "selector":
+			p.Selector = s
+		case "duration":
+			d, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return p, fmt.Errorf("duration must be a number of seconds")
+			}
+			p.Duration = d
+		default:
+			return p, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	return p, nil
+}
+
+// profile picks the named profile, or the configured default when name is
+// empty. Only a profile that was asked for by name has to exist.
+func (c *config) profile(name string) (profile, error) {
+	if name != "" {
+		p, ok := c.Profiles[name]
+		if !ok {
+			return p, fmt.Errorf("no profile named %q, have %s", name, strings.Join(c.names(), ", "))
+		}
+		return p, nil
+	}
+
+	if c.DefaultProfile != "" {
+		return c.profile(c.DefaultProfile)
+	}
+	return c.Profiles[defaultProfile], nil
+}
+
+// profileName is the name of the profile that profile(name) selects.
+func (c *config) profileName(name string) string {
+	switch {
+	case name != "":
+		return name
+	case c.DefaultProfile != "":
+		return c.DefaultProfile
+	}
+	return defaultProfile
+}
+
+func (c *config) names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
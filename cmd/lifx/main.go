@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/credentials"
+)
+
+type (
+	command struct {
+		usage string
+		run   func(c *lifx.Client, args []string) error
+
+		// standalone commands run without a client.
+		standalone bool
+	}
+
+	globals struct {
+		token, endpoint, config, profile string
+	}
+)
+
+var commands = map[string]command{
+	"list":       {usage: "list [selector]", run: list},
+	"on":         {usage: "on [-duration s] [selector]", run: power("on")},
+	"off":        {usage: "off [-duration s] [selector]", run: power("off")},
+	"toggle":     {usage: "toggle [-duration s] [selector]", run: toggle},
+	"set":        {usage: "set [-color c] [-brightness b] [-kelvin k] [-power p] [-duration s] [selector]", run: set},
+	"watch":      {usage: "watch [-interval d] [-format f] [-webhook url] [selector]", run: watch},
+	"tui":        {usage: "tui [-interval d] [selector]", run: tui},
+	"scenes":     {usage: "scenes list | scenes activate [-duration s] <name|uuid>", run: scenes},
+	"theme":      {usage: "theme list | theme apply [-duration s] [-power-on] <name> [selector]", run: theme},
+	"breathe":    {usage: "breathe -color c [-from c] [-period s] [-cycles n] [-peak p] [-persist] [selector]", run: breathe},
+	"webhooks":   {usage: "webhooks [-listen addr] [-secret s] <actions.json>", run: webhooks},
+	"auth":       {usage: "auth login [-verify=false] | auth logout | auth status", run: auth, standalone: true},
+	"completion": {usage: "completion bash|zsh|fish", run: completion, standalone: true},
+}
+
+func init() {
+	// Completion looks commands up, so it cannot be part of their
+	// initializer.
+	commands["__complete"] = command{usage: "__complete [words]", run: complete, standalone: true}
+}
+
+var errUsage = errors.New("usage")
+
+var (
+	// conf is the configuration file, loaded before commands run.
+	conf *config
+
+	// settings is the active profile with flags and the environment
+	// applied, and account is the profile's name.
+	settings profile
+	account  string
+
+	global globals
+)
+
+func main() {
+	g := &global
+
+	flag.Usage = usage
+	flag.StringVar(&g.token, "token", "", "LIFX access token, defaults to $LIFX_TOKEN, the profile, the credential store or the token file")
+	flag.StringVar(&g.endpoint, "endpoint", os.Getenv("LIFX_ENDPOINT"), "API base URL, such as one served by lifx-emulator")
+	flag.StringVar(&g.config, "config", "", "configuration file, defaults to $LIFX_CONFIG or lifx/config.yaml in the user config directory")
+	flag.StringVar(&g.profile, "profile", os.Getenv("LIFX_PROFILE"), "configuration profile to use")
+	outputFlag(flag.CommandLine)
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lifx: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	var (
+		c   *lifx.Client
+		err error
+	)
+	if !cmd.standalone {
+		if c, err = connect(global); err != nil {
+			fatal(err)
+		}
+	}
+
+	if err = cmd.run(c, flag.Args()[1:]); err != nil {
+		if err == errUsage {
+			fmt.Fprintf(os.Stderr, "usage: lifx %s\n", cmd.usage)
+			os.Exit(2)
+		}
+		fatal(err)
+	}
+}
+
+// connect loads the configuration and builds a client, letting flags
+// override the environment and the environment override the profile.
+// Without a token anywhere else, the one stored for the profile in the
+// system credential store is used.
+func connect(g globals) (*lifx.Client, error) {
+	if err := configure(g); err != nil {
+		return nil, err
+	}
+
+	token := g.token
+	if token == "" {
+		token = os.Getenv("LIFX_TOKEN")
+	}
+	if token == "" {
+		token = settings.Token
+	}
+	if token == "" {
+		t, err := credentials.Get(account)
+		if err != nil && !errors.Is(err, credentials.ErrNotFound) && !errors.Is(err, credentials.ErrUnsupported) {
+			return nil, err
+		}
+		token = t
+	}
+
+	var err error
+	if settings.Token, err = loadToken(token); err != nil {
+		return nil, err
+	}
+
+	return lifx.NewClient(settings.Token, clientOptions()...), nil
+}
+
+// clientOptions points clients at the profile's endpoint.
+func clientOptions() []func(*lifx.Client) {
+	options := []func(*lifx.Client){lifx.WithUserAgent("lifx-cli/" + lifx.Version)}
+	if settings.BaseURL != "" {
+		options = append(options, lifx.WithEndpoint(settings.BaseURL))
+	}
+	return options
+}
+
+// configure loads the configuration file and selects the profile.
+func configure(g globals) error {
+	var err error
+
+	if conf, err = loadGlobalConfig(g); err != nil {
+		return err
+	}
+	account = conf.profileName(g.profile)
+	if settings, err = conf.profile(g.profile); err != nil {
+		return err
+	}
+
+	if g.endpoint != "" {
+		settings.BaseURL = g.endpoint
+	}
+	return nil
+}
+
+func loadGlobalConfig(g globals) (*config, error) {
+	if g.config == "" {
+		path, err := configPath()
+		if err != nil {
+			return nil, err
+		}
+		g.config = path
+	}
+	return loadConfig(g.config)
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: lifx [-profile p] [-config path] [-token t] [-endpoint url] [-output format] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nselectors may be given as arguments or built with -id, -label, -group and -location,")
+	fmt.Fprintln(os.Stderr, "and default to the profile's selector or to all lights")
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+	os.Exit(1)
+}
+
+// loadToken falls back to the first line of lifx/token in the user's
+// configuration directory when no token was given.
+func loadToken(token string) (string, error) {
+	if token != "" {
+		return token, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "lifx", "token")
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no access token: run lifx auth login, set LIFX_TOKEN, pass -token or write one to %s", path)
+	} else if err != nil {
+		return "", err
+	}
+
+	if token = strings.TrimSpace(string(b)); token == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type (
+	// field is one named value of a record. Fields keep the order they
+	// are declared in, in every format, so scripts can rely on it.
+	field struct {
+		name  string
+		value interface{}
+		wide  bool
+	}
+
+	record []field
+)
+
+var output = "table"
+
+var outputs = []string{"table", "wide", "json", "yaml"}
+
+// outputFlag lets the output format be given after the command as well as
+// before it.
+func outputFlag(fs *flag.FlagSet) {
+	fs.StringVar(&output, "output", output, "output format: "+strings.Join(outputs, ", "))
+	fs.StringVar(&output, "o", output, "shorthand for -output")
+}
+
+func validOutput(format string) error {
+	for _, o := range outputs {
+		if format == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, want one of %s", format, strings.Join(outputs, ", "))
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.name)
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func writeRecords(records []record) error {
+	return encodeRecords(os.Stdout, output, records)
+}
+
+func encodeRecords(w io.Writer, format string, records []record) error {
+	switch format {
+	case "json":
+		if records == nil {
+			records = []record{}
+		}
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	case "yaml":
+		return encodeYAML(w, records)
+	case "table", "wide":
+		return encodeTable(w, records, format == "wide")
+	}
+	return validOutput(format)
+}
+
+func encodeTable(w io.Writer, records []record, wide bool) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	var header []string
+	for _, f := range records[0] {
+		if wide || !f.wide {
+			header = append(header, strings.ToUpper(f.name))
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, r := range records {
+		var cells []string
+		for _, f := range r {
+			if wide || !f.wide {
+				cells = append(cells, text(f.value))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// encodeYAML writes records as a YAML sequence of mappings. Records only
+// hold scalars, which keeps this far short of a general YAML encoder.
+func encodeYAML(w io.Writer, records []record) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	for _, r := range records {
+		for i, f := range r {
+			prefix := "  "
+			if i == 0 {
+				prefix = "- "
+			}
+			if _, err := fmt.Fprintf(w, "%s%s: %s\n", prefix, f.name, yamlScalar(f.value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return text(v)
+}
+
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func scenes(c *lifx.Client, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return listScenes(c, args[1:])
+	case "activate":
+		return activateScene(c, args[1:])
+	}
+	return errUsage
+}
+
+func listScenes(c *lifx.Client, args []string) error {
+	fs := flag.NewFlagSet("scenes list", flag.ContinueOnError)
+	outputFlag(fs)
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+	if err := validOutput(output); err != nil {
+		return err
+	}
+
+	scenes, err := c.ListScenes()
+	if err != nil {
+		return err
+	}
+
+	records := make([]record, len(scenes))
+	for i, s := range scenes {
+		records[i] = record{
+			{name: "uuid", value: s.UUID},
+			{name: "name", value: s.Name},
+		}
+	}
+	return writeRecords(records)
+}
+
+func activateScene(c *lifx.Client, args []string) error {
+	var activate lifx.Activate
+
+	fs := flag.NewFlagSet("scenes activate", flag.ContinueOnError)
+	fs.Float64Var(&activate.Duration, "duration", settings.Duration, "transition time in seconds")
+	outputFlag(fs)
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	if err := validOutput(output); err != nil {
+		return err
+	}
+
+	scenes, err := c.ListScenes()
+	if err != nil {
+		return err
+	}
+
+	scene, err := lifx.FindScene(scenes, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	r, err := c.ActivateScene(scene.UUID, activate)
+	if err != nil {
+		return err
+	}
+	return printResults(r)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+// selectorFlags lets every command take a selector either as arguments in
+// the API's own syntax or as flags that are assembled with the library's
+// selector builder, so labels with spaces need no quoting tricks.
+type selectorFlags struct {
+	ids, labels, groups, locations multiFlag
+	random                         bool
+}
+
+type multiFlag []string
+
+func (m *multiFlag) String() string { return strings.Join(*m, ",") }
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *selectorFlags) {
+	var sf selectorFlags
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Var(&sf.ids, "id", "select the light with this id, may be repeated")
+	fs.Var(&sf.labels, "label", "select lights with this label, may be repeated")
+	fs.Var(&sf.groups, "group", "select lights in this group, may be repeated")
+	fs.Var(&sf.locations, "location", "select lights in this location, may be repeated")
+	fs.BoolVar(&sf.random, "random", false, "pick one of the selected lights at random")
+	outputFlag(fs)
+
+	return fs, &sf
+}
+
+// selector combines flags and arguments, defaulting to the profile's
+// selector or to every light.
+func (sf *selectorFlags) selector(args []string) (string, error) {
+	var parts []lifx.SelectorPart
+
+	for _, arg := range args {
+		p, err := lifx.ParseSelector(arg)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, p...)
+	}
+
+	for _, v := range sf.ids {
+		parts = append(parts, lifx.ById(v))
+	}
+	for _, v := range sf.labels {
+		parts = append(parts, lifx.ByLabel(v))
+	}
+	for _, v := range sf.groups {
+		parts = append(parts, lifx.ByGroup(v))
+	}
+	for _, v := range sf.locations {
+		parts = append(parts, lifx.ByLocation(v))
+	}
+
+	if len(parts) == 0 && settings.Selector != "" {
+		p, err := lifx.ParseSelector(settings.Selector)
+		if err != nil {
+			return "", fmt.Errorf("profile selector: %w", err)
+		}
+		parts = p
+	}
+	if len(parts) == 0 {
+		parts = append(parts, lifx.All())
+	}
+	if sf.random {
+		for i := range parts {
+			parts[i] = parts[i].Randomly()
+		}
+	}
+
+	return lifx.BuildSelector(parts...)
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// makeRaw puts the terminal into raw mode with stty, which avoids a
+// dependency on a terminal package for the one command that needs it.
+func makeRaw() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("the dashboard needs an interactive terminal: %w", err)
+	}
+	if _, err = stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+
+	return func() { stty(strings.TrimSpace(state)) }, nil
+}
+
+// noEcho stops the terminal echoing input while leaving it line buffered.
+func noEcho() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = stty("-echo"); err != nil {
+		return nil, err
+	}
+
+	return func() { stty(strings.TrimSpace(state)) }, nil
+}
+
+func termSize() (rows, cols int) {
+	out, err := stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	if _, err = fmt.Sscan(out, &rows, &cols); err != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
@@ -0,0 +1,15 @@
+package main
+
+import "errors"
+
+func makeRaw() (func(), error) {
+	return nil, errors.New("the dashboard is not supported on windows")
+}
+
+func noEcho() (func(), error) {
+	return nil, errors.New("cannot hide input on windows")
+}
+
+func termSize() (rows, cols int) {
+	return 24, 80
+}
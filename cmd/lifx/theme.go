@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+// themes returns the built-in palettes with any defined in the config file,
+// which take precedence.
+func themes() (map[string]lifx.Palette, error) {
+	palettes := make(map[string]lifx.Palette, len(lifx.Palettes))
+	for name, p := range lifx.Palettes {
+		palettes[name] = p
+	}
+
+	for name, s := range conf.Themes {
+		p, err := lifx.NewPalette(name, strings.Split(s, ";")...)
+		if err != nil {
+			return nil, err
+		}
+		palettes[name] = p
+	}
+
+	return palettes, nil
+}
+
+func theme(c *lifx.Client, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return listThemes(args[1:])
+	case "apply":
+		return applyTheme(c, args[1:])
+	}
+	return errUsage
+}
+
+func listThemes(args []string) error {
+	fs := flag.NewFlagSet("theme list", flag.ContinueOnError)
+	outputFlag(fs)
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+	if err := validOutput(output); err != nil {
+		return err
+	}
+
+	palettes, err := themes()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	records := make([]record, len(names))
+	for i, name := range names {
+		records[i] = record{
+			{name: "name", value: name},
+			{name: "colors", value: palettes[name].String()},
+		}
+	}
+	return writeRecords(records)
+}
+
+func applyTheme(c *lifx.Client, args []string) error {
+	var defaults lifx.State
+
+	fs, sf := newFlagSet("theme apply")
+	fs.Float64Var(&defaults.Duration, "duration", settings.Duration, "transition time in seconds")
+	powerOn := fs.Bool("power-on", false, "turn the lights on as well")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return errUsage
+	}
+	name := fs.Arg(0)
+
+	// Flags may also follow the theme name.
+	selector, err := parse(fs, sf, fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	palettes, err := themes()
+	if err != nil {
+		return err
+	}
+	p, ok := palettes[name]
+	if !ok {
+		return fmt.Errorf("no theme named %q, see lifx theme list", name)
+	}
+
+	if *powerOn {
+		defaults.Power = "on"
+	}
+
+	r, err := c.ApplyPalette(selector, p, defaults)
+	if err != nil {
+		return err
+	}
+	return printOperations(r)
+}
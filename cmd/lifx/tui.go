@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	keyUp = iota + 0x100
+	keyDown
+	keyLeft
+	keyRight
+	keyQuit
+)
+
+const sliderWidth = 20
+
+type (
+	dashboard struct {
+		client   *lifx.Client
+		store    *lifx.StateStore
+		selected string
+		status   string
+		updated  time.Time
+		rows     int
+		cols     int
+	}
+
+	// row is a line of the dashboard, either a room heading or a light.
+	row struct {
+		heading string
+		light   *lifx.Light
+	}
+)
+
+func tui(c *lifx.Client, args []string) error {
+	fs, sf := newFlagSet("tui")
+	interval := fs.Duration("interval", lifx.DefaultWatchInterval, "how often to poll")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	d := &dashboard{client: c, store: lifx.NewStateStore(c, selector, lifx.WithInterval(*interval))}
+	if err = d.store.Refresh(); err != nil {
+		return err
+	}
+	d.updated = time.Now()
+
+	restore, err := makeRaw()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	return d.run(*interval)
+}
+
+func (d *dashboard) run(interval time.Duration) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, unsubscribe := d.store.Subscribe()
+	defer unsubscribe()
+	go d.store.Run(ctx)
+
+	keys := make(chan int)
+	go readKeys(keys)
+
+	statuses := make(chan string, 8)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	d.rows, d.cols = termSize()
+	for {
+		d.render()
+
+		select {
+		case e := <-events:
+			if e.Type == lifx.WatchError {
+				d.status = e.Err.Error()
+			} else {
+				d.updated = time.Now()
+			}
+		case s := <-statuses:
+			d.status = s
+		case <-ticker.C:
+			d.rows, d.cols = termSize()
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				return nil
+			}
+			d.handle(k, statuses)
+		}
+	}
+}
+
+func (d *dashboard) handle(key int, statuses chan<- string) {
+	lights := d.store.Lights()
+	if len(lights) == 0 {
+		return
+	}
+
+	i := 0
+	for j, l := range lights {
+		if l.Id == d.selected {
+			i = j
+		}
+	}
+	l := lights[i]
+
+	act := func(what string, fn func() (*lifx.LifxResponse, error), update func(*lifx.Light)) {
+		d.status = what + "..."
+		go func() {
+			r, err := fn()
+			if err != nil {
+				statuses <- what + ": " + err.Error()
+				return
+			}
+			failed := 0
+			if r != nil {
+				for _, res := range r.Results {
+					if !res.Status.IsOK() {
+						failed++
+						continue
+					}
+					d.store.Update(res.Id, update)
+				}
+			}
+			if failed > 0 {
+				statuses <- fmt.Sprintf("%s: %d lights did not respond", what, failed)
+				return
+			}
+			statuses <- what
+		}()
+	}
+
+	switch key {
+	case keyUp, 'k':
+		if i > 0 {
+			i--
+		}
+		d.selected = lights[i].Id
+	case keyDown, 'j':
+		if i < len(lights)-1 {
+			i++
+		}
+		d.selected = lights[i].Id
+	case ' ', '\r', 't':
+		act("toggled "+l.Label, func() (*lifx.LifxResponse, error) {
+			return d.client.Toggle(lifx.ById(l.Id).String(), 0)
+		}, flipPower)
+	case 'g':
+		act("toggled "+l.Group.Name, func() (*lifx.LifxResponse, error) {
+			return d.client.Toggle(lifx.ByGroupId(l.Group.Id).String(), 0)
+		}, flipPower)
+	case keyLeft, 'h', keyRight, 'l':
+		step := 0.1
+		if key == keyLeft || key == 'h' {
+			step = -step
+		}
+		act(fmt.Sprintf("brightness of %s %+.0f%%", l.Label, step*100), func() (*lifx.LifxResponse, error) {
+			return d.client.StateDelta(lifx.ById(l.Id).String(), lifx.StateDelta{Brightness: &step})
+		}, func(l *lifx.Light) {
+			l.Brightness = math.Max(0, math.Min(1, l.Brightness+step))
+			l.Color.B = lifx.Float32Ptr(float32(l.Brightness))
+		})
+	case 'r':
+		if err := d.store.Refresh(); err != nil {
+			d.status = err.Error()
+		} else {
+			d.status, d.updated = "refreshed", time.Now()
+		}
+	default:
+		if key < '1' || key > '9' || int(key-'1') >= len(colorNames) {
+			return
+		}
+		name := colorNames[key-'1']
+		c, _ := lifx.ParseColor(name)
+		act(name+" "+l.Label, func() (*lifx.LifxResponse, error) {
+			return d.client.SetState(lifx.ById(l.Id).String(), lifx.State{Color: lifx.NamedColor(name)})
+		}, func(l *lifx.Light) {
+			if c.H != nil {
+				l.Color.H = c.H
+			}
+			if c.S != nil {
+				l.Color.S = c.S
+			}
+		})
+	}
+}
+
+func flipPower(l *lifx.Light) {
+	if l.Power == "on" {
+		l.Power = "off"
+	} else {
+		l.Power = "on"
+	}
+}
+
+func (d *dashboard) render() {
+	var (
+		b        strings.Builder
+		rows     []row
+		selected int
+		room     string
+	)
+
+	lights := d.store.Lights()
+	if d.selected == "" && len(lights) > 0 {
+		d.selected = lights[0].Id
+	}
+	for i := range lights {
+		l := &lights[i]
+		if r := l.Location.Name + " / " + l.Group.Name; r != room {
+			room = r
+			rows = append(rows, row{heading: r})
+		}
+		if l.Id == d.selected {
+			selected = len(rows)
+		}
+		rows = append(rows, row{light: l})
+	}
+
+	// Keep the selected light visible when there are more rows than fit
+	// between the title and the help line.
+	height := d.rows - 4
+	if height < 1 {
+		height = 1
+	}
+	first := 0
+	if selected >= height {
+		first = selected - height + 1
+	}
+	last := first + height
+	if last > len(rows) {
+		last = len(rows)
+	}
+
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "\x1b[1mLIFX\x1b[0m  %d lights  updated %s ago\r\n\r\n",
+		len(lights), time.Since(d.updated).Truncate(time.Second))
+
+	for _, r := range rows[first:last] {
+		if r.light == nil {
+			fmt.Fprintf(&b, "\x1b[1m%s\x1b[0m\r\n", truncate(r.heading, d.cols))
+			continue
+		}
+		b.WriteString(d.renderLight(*r.light))
+		b.WriteString("\r\n")
+	}
+
+	fmt.Fprintf(&b, "\x1b[%d;1H\x1b[7m%s\x1b[0m", d.rows-1, truncate(d.status, d.cols))
+	fmt.Fprintf(&b, "\x1b[%d;1H%s", d.rows,
+		truncate("↑↓ select  space toggle  g group  ←→ brightness  1-9 color  r refresh  q quit", d.cols))
+
+	os.Stdout.WriteString(b.String())
+}
+
+func (d *dashboard) renderLight(l lifx.Light) string {
+	cursor := "  "
+	if l.Id == d.selected {
+		cursor = "\x1b[7m>\x1b[0m "
+	}
+
+	power, state := "○", l.Power
+	if !l.Connected {
+		state = "offline"
+	} else if l.Power == "on" {
+		power = "●"
+	}
+
+	filled := int(math.Round(l.Brightness * sliderWidth))
+	slider := strings.Repeat("█", filled) + strings.Repeat("░", sliderWidth-filled)
+
+	return fmt.Sprintf("%s%s %-20s %-7s %s %3.0f%% %s",
+		cursor, power, truncate(l.Label, 20), state, slider, l.Brightness*100, swatch(l.Color))
+}
+
+// swatch draws the light's hue and saturation at full brightness, since
+// the brightness already has a slider.
+func swatch(c lifx.HSBKColor) string {
+	var h, s float64
+	if c.H != nil {
+		h = float64(*c.H)
+	}
+	if c.S != nil {
+		s = float64(*c.S)
+	}
+	r, g, b := hsvToRGB(h, s, 1)
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm    \x1b[0m", r, g, b)
+}
+
+func hsvToRGB(h, s, v float64) (r, g, b uint8) {
+	h = math.Mod(h, 360) / 60
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var rf, gf, bf float64
+	switch int(h) {
+	case 0:
+		rf, gf = c, x
+	case 1:
+		rf, gf = x, c
+	case 2:
+		gf, bf = c, x
+	case 3:
+		gf, bf = x, c
+	case 4:
+		rf, bf = x, c
+	default:
+		rf, bf = c, x
+	}
+
+	m := v - c
+	return uint8(math.Round((rf + m) * 255)), uint8(math.Round((gf + m) * 255)), uint8(math.Round((bf + m) * 255))
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n < 0 || len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// readKeys decodes key presses from the raw terminal, translating arrow
+// keys and treating a lone escape or ctrl-c as a request to quit.
+func readKeys(keys chan<- int) {
+	defer close(keys)
+
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+
+		switch s := string(buf[:n]); s {
+		case "\x1b[A", "\x1bOA":
+			keys <- keyUp
+		case "\x1b[B", "\x1bOB":
+			keys <- keyDown
+		case "\x1b[C", "\x1bOC":
+			keys <- keyRight
+		case "\x1b[D", "\x1bOD":
+			keys <- keyLeft
+		case "\x1b", "\x03", "q":
+			keys <- keyQuit
+		default:
+			for _, c := range s {
+				keys <- int(c)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/template"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/webhook"
+)
+
+func watch(c *lifx.Client, args []string) error {
+	fs, sf := newFlagSet("watch")
+	interval := fs.Duration("interval", lifx.DefaultWatchInterval, "how often to poll")
+	format := fs.String("format", "", "text, json, yaml, or a Go template applied to each event, defaults to following -output")
+	var hooks multiFlag
+	fs.Var(&hooks, "webhook", "URL to post each event to, repeatable")
+	secret := fs.String("webhook-secret", os.Getenv("LIFX_WEBHOOK_SECRET"), "key for signing webhook deliveries, defaults to $LIFX_WEBHOOK_SECRET")
+	selector, err := parse(fs, sf, args)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = "text"
+		if output == "json" || output == "yaml" {
+			*format = output
+		}
+	}
+
+	print, err := eventPrinter(*format)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var notifier *webhook.Notifier
+	if len(hooks) > 0 {
+		notifier = webhook.NewNotifier(hooks, *secret, webhook.WithErrorHandler(func(op string, err error) {
+			fmt.Fprintf(os.Stderr, "lifx: webhook: %s\n", err)
+		}))
+		go notifier.Run(ctx)
+	}
+
+	w := lifx.NewWatcher(c, selector, lifx.WithInterval(*interval))
+	err = w.Run(ctx, func(e lifx.Event) {
+		if notifier != nil {
+			notifier.Handle(e)
+		}
+		if e.Type == lifx.WatchError {
+			fmt.Fprintf(os.Stderr, "lifx: %s\n", e.Err)
+			return
+		}
+		if err := print(e); err != nil {
+			fmt.Fprintf(os.Stderr, "lifx: %s\n", err)
+		}
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+func eventPrinter(format string) (func(lifx.Event) error, error) {
+	switch format {
+	case "text":
+		return printEvent, nil
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		return func(e lifx.Event) error { return enc.Encode(e) }, nil
+	case "yaml":
+		return func(e lifx.Event) error {
+			if _, err := fmt.Println("---"); err != nil {
+				return err
+			}
+			return encodeRecords(os.Stdout, "yaml", []record{eventRecord(e)})
+		}, nil
+	}
+
+	t, err := template.New("event").Parse(format)
+	if err != nil {
+		return nil, err
+	}
+	return func(e lifx.Event) error {
+		if err := t.Execute(os.Stdout, e); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(os.Stdout)
+		return err
+	}, nil
+}
+
+func printEvent(e lifx.Event) error {
+	changes := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = fmt.Sprintf("%s=%q", c.Field, c.New)
+	}
+
+	_, err := fmt.Printf("%s %-7s %s %q %s\n",
+		e.Time.Format(time.RFC3339), e.Type, e.Light.Id, e.Light.Label, strings.Join(changes, " "))
+	return err
+}
+
+// eventRecord flattens an event, giving each changed field its new value.
+func eventRecord(e lifx.Event) record {
+	r := record{
+		{name: "time", value: e.Time},
+		{name: "type", value: string(e.Type)},
+		{name: "id", value: e.Light.Id},
+		{name: "label", value: e.Light.Label},
+	}
+	for _, c := range e.Changes {
+		r = append(r, field{name: c.Field, value: c.New})
+	}
+	return r
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/webhook"
+)
+
+func webhooks(c *lifx.Client, args []string) error {
+	fs := flag.NewFlagSet("webhooks", flag.ContinueOnError)
+	listen := fs.String("listen", ":8080", "address to serve hooks on")
+	secret := fs.String("secret", os.Getenv("LIFX_WEBHOOK_SECRET"), "shared secret callers must send, defaults to $LIFX_WEBHOOK_SECRET")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	if *secret == "" {
+		return errors.New("a secret is required, use -secret or $LIFX_WEBHOOK_SECRET")
+	}
+
+	actions, err := webhook.LoadActions(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	s, err := webhook.NewServer(c, *secret, actions)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "serving hooks on %s: %s\n", *listen, strings.Join(names, ", "))
+	return http.ListenAndServe(*listen, s)
+}
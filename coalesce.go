@@ -0,0 +1,115 @@
+package lifx
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// StateSetter changes lights. Client, Router and the LAN client all
+	// satisfy it.
+	StateSetter interface {
+		SetState(selector string, state State) (*LifxResponse, error)
+	}
+
+	// UpdateCoalescer sends states to each selector no more often than its
+	// interval allows. States that arrive while a selector is waiting
+	// replace one another, so only the latest is sent, which keeps sliders
+	// and color pickers from flooding the API. States are sent in fast
+	// mode, since the results of superseded updates are of no interest.
+	UpdateCoalescer struct {
+		setter   StateSetter
+		interval time.Duration
+		onError  func(selector string, err error)
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		pending  map[string]*pendingUpdate
+	}
+
+	pendingUpdate struct {
+		state   State
+		dirty   bool
+		sending bool
+		last    time.Time
+	}
+)
+
+func NewUpdateCoalescer(setter StateSetter, interval time.Duration, options ...func(*UpdateCoalescer)) *UpdateCoalescer {
+	u := &UpdateCoalescer{
+		setter:   setter,
+		interval: interval,
+		pending:  make(map[string]*pendingUpdate),
+	}
+
+	for _, option := range options {
+		option(u)
+	}
+
+	return u
+}
+
+// WithUpdateErrors calls fn with the errors from sending states, which
+// would otherwise be dropped.
+func WithUpdateErrors(fn func(selector string, err error)) func(*UpdateCoalescer) {
+	return func(u *UpdateCoalescer) {
+		u.onError = fn
+	}
+}
+
+// Update queues state for selector without blocking. It is sent straight
+// away if the selector has not been sent anything for an interval, and
+// otherwise when the interval is up, unless a newer state replaces it.
+func (u *UpdateCoalescer) Update(selector string, state State) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	p, ok := u.pending[selector]
+	if !ok {
+		p = &pendingUpdate{}
+		u.pending[selector] = p
+	}
+	p.state, p.dirty = state, true
+
+	if !p.sending {
+		p.sending = true
+		u.wg.Add(1)
+		go u.send(selector, p)
+	}
+}
+
+// Flush waits until every queued state has been sent.
+func (u *UpdateCoalescer) Flush() {
+	u.wg.Wait()
+}
+
+func (u *UpdateCoalescer) send(selector string, p *pendingUpdate) {
+	defer u.wg.Done()
+
+	for {
+		u.mu.Lock()
+		wait := time.Until(p.last.Add(u.interval))
+		u.mu.Unlock()
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+
+		u.mu.Lock()
+		if !p.dirty {
+			p.sending = false
+			u.mu.Unlock()
+			return
+		}
+		state := p.state
+		p.dirty, p.last = false, time.Now()
+		u.mu.Unlock()
+
+		state.Fast = true
+		err := safely(func() error {
+			_, err := u.setter.SetState(selector, state)
+			return err
+		})
+		if err != nil && u.onError != nil {
+			u.onError(selector, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package lifx_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestUpdateCoalescer(t *testing.T) {
+	f := lifxtest.NewFakeClient(lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+	u := lifx.NewUpdateCoalescer(f, 50*time.Millisecond)
+
+	u.Update("label:Desk", lifx.State{Brightness: 0.1})
+	waitFor(t, func() bool { return len(f.CallsTo("SetState")) == 1 })
+
+	// These arrive while the selector waits out its interval, so only the
+	// last of them is sent.
+	for _, b := range []float64{0.2, 0.3, 0.4} {
+		u.Update("label:Desk", lifx.State{Brightness: b})
+	}
+	u.Flush()
+
+	calls := f.CallsTo("SetState")
+	if len(calls) != 2 {
+		t.Fatalf("sent %d states, want 2: %+v", len(calls), calls)
+	}
+	for i, want := range []float64{0.1, 0.4} {
+		s := calls[i].Arg.(lifx.State)
+		if s.Brightness != want || !s.Fast {
+			t.Errorf("state %d = %+v, want brightness %v in fast mode", i, s, want)
+		}
+	}
+}
+
+func TestUpdateCoalescerErrors(t *testing.T) {
+	f := lifxtest.NewFakeClient(lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+	f.FailWith("SetState", lifx.ErrServer)
+
+	var got []string
+	u := lifx.NewUpdateCoalescer(f, time.Millisecond, lifx.WithUpdateErrors(func(selector string, err error) {
+		if !errors.Is(err, lifx.ErrServer) {
+			t.Errorf("err = %v, want ErrServer", err)
+		}
+		got = append(got, selector)
+	}))
+	u.Update("label:Desk", lifx.State{Power: "on"})
+	u.Flush()
+
+	if len(got) != 1 || got[0] != "label:Desk" {
+		t.Errorf("errors reported for %v, want [label:Desk]", got)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -18,11 +22,14 @@ type (
 		R, G, B uint8
 	}
 
+	// HSBKColor leaves unset components as they are. RandomHue asks the
+	// API to pick a hue itself, and takes the place of H.
 	HSBKColor struct {
-		H *float32 `json:"hue"`
-		S *float32 `json:"saturation"`
-		B *float32 `json:"brightness"`
-		K *int16   `json:"kelvin"`
+		H         *float32 `json:"hue"`
+		S         *float32 `json:"saturation"`
+		B         *float32 `json:"brightness"`
+		K         *int16   `json:"kelvin"`
+		RandomHue bool     `json:"-"`
 	}
 
 	NamedColor string
@@ -125,6 +132,145 @@ func NewHSBColor(h, s, b float32) (HSBKColor, error) {
 	return c, nil
 }
 
+// randomHue is the hue the API replaces with one of its choosing.
+const randomHue = "random"
+
+var namedHues = map[string]float32{
+	"red":    HueRed,
+	"orange": HueOrange,
+	"yellow": HueYellow,
+	"green":  HueGreen,
+	"cyan":   HueCyan,
+	"blue":   HueBlue,
+	"purple": HuePurple,
+	"pink":   HuePink,
+}
+
+// ParseColor parses the color strings accepted by the API, such as
+// "red", "#ff0000", "rgb:255,0,0" or "hue:120 saturation:1 kelvin:3500",
+// into their HSBK components. Components the string does not mention are
+// left nil.
+func ParseColor(s string) (HSBKColor, error) {
+	var c HSBKColor
+
+	for _, field := range strings.Fields(strings.ToLower(s)) {
+		if h, ok := namedHues[field]; ok {
+			c.H, c.S, c.RandomHue = Float32Ptr(h), Float32Ptr(1), false
+			continue
+		}
+
+		// "random" is a random hue at full saturation, as a named hue is.
+		if field == randomHue {
+			c.H, c.S, c.RandomHue = nil, Float32Ptr(1), true
+			continue
+		}
+
+		if field == "white" {
+			c.S = Float32Ptr(0)
+			continue
+		}
+
+		if strings.HasPrefix(field, "#") {
+			rgb, err := parseHex(field[1:])
+			if err != nil {
+				return HSBKColor{}, err
+			}
+			c = mergeHSB(c, rgb.HSBKColor())
+			continue
+		}
+
+		i := strings.IndexByte(field, ':')
+		if i < 0 {
+			return HSBKColor{}, fmt.Errorf("'%s' is not a valid color", field)
+		}
+		key, value := field[:i], field[i+1:]
+
+		if key == "rgb" {
+			rgb, err := parseRGB(value)
+			if err != nil {
+				return HSBKColor{}, err
+			}
+			c = mergeHSB(c, rgb.HSBKColor())
+			continue
+		}
+
+		if key == "kelvin" {
+			k, err := strconv.ParseInt(value, 10, 16)
+			if err != nil || k < 1500 || k > 9000 {
+				return HSBKColor{}, errors.New("kelvin must be between 1500-9000")
+			}
+			c.K = Int16Ptr(int16(k))
+			continue
+		}
+
+		if key == "hue" && value == randomHue {
+			c.H, c.RandomHue = nil, true
+			continue
+		}
+
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil || math.IsNaN(f) {
+			return HSBKColor{}, fmt.Errorf("'%s' is not a valid %s", value, key)
+		}
+
+		switch key {
+		case "hue":
+			if f < 0 || f > 360 {
+				return HSBKColor{}, errors.New("hue must be between 0.0-360.0")
+			}
+			c.H, c.RandomHue = Float32Ptr(float32(f)), false
+		case "saturation":
+			if f < 0 || f > 1 {
+				return HSBKColor{}, errors.New("saturation must be between 0.0-1.0")
+			}
+			c.S = Float32Ptr(float32(f))
+		case "brightness":
+			if f < 0 || f > 1 {
+				return HSBKColor{}, errors.New("brightness must be between 0.0-1.0")
+			}
+			c.B = Float32Ptr(float32(f))
+		default:
+			return HSBKColor{}, fmt.Errorf("'%s' is not a valid color component", key)
+		}
+	}
+
+	return c, nil
+}
+
+func parseRGB(s string) (RGBColor, error) {
+	var v [3]uint8
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return RGBColor{}, fmt.Errorf("'%s' is not a valid rgb color", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 8)
+		if err != nil {
+			return RGBColor{}, errors.New("values must be between 0-255")
+		}
+		v[i] = uint8(n)
+	}
+
+	return RGBColor{R: v[0], G: v[1], B: v[2]}, nil
+}
+
+func parseHex(s string) (RGBColor, error) {
+	if len(s) != 6 {
+		return RGBColor{}, fmt.Errorf("'#%s' is not a valid hex color", s)
+	}
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return RGBColor{}, fmt.Errorf("'#%s' is not a valid hex color", s)
+	}
+	return RGBColor{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n)}, nil
+}
+
+func mergeHSB(c, rgb HSBKColor) HSBKColor {
+	c.H, c.S, c.B, c.RandomHue = rgb.H, rgb.S, rgb.B, false
+	return c
+}
+
 func NewRed() (HSBKColor, error)    { return NewHSColor(HueRed, 1) }
 func NewOrange() (HSBKColor, error) { return NewHSColor(HueOrange, 1) }
 func NewYellow() (HSBKColor, error) { return NewHSColor(HueYellow, 1) }
@@ -133,6 +279,11 @@ func NewCyan() (HSBKColor, error)   { return NewHSColor(HueCyan, 1) }
 func NewPurple() (HSBKColor, error) { return NewHSColor(HuePurple, 1) }
 func NewPink() (HSBKColor, error)   { return NewHSColor(HuePink, 1) }
 
+// NewRandom is full saturation in a hue the API picks for each request.
+func NewRandom() HSBKColor {
+	return HSBKColor{S: Float32Ptr(1), RandomHue: true}
+}
+
 func NewWhite(k int16) (HSBKColor, error) {
 	var c HSBKColor
 
@@ -171,9 +322,42 @@ func (c RGBColor) Hex() string {
 	return fmt.Sprintf("#%x%x%x", c.R, c.G, c.B)
 }
 
+func (c RGBColor) HSBKColor() HSBKColor {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	delta := max - min
+
+	var h, s float64
+	switch {
+	case delta == 0:
+		h = 0
+	case max == r:
+		h = math.Mod((g-b)/delta, 6)
+	case max == g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	if max > 0 {
+		s = delta / max
+	}
+
+	return HSBKColor{
+		H: Float32Ptr(float32(h)),
+		S: Float32Ptr(float32(s)),
+		B: Float32Ptr(float32(max)),
+	}
+}
+
 func (c HSBKColor) ColorString() string {
 	var s []string
-	if c.H != nil {
+	if c.RandomHue {
+		s = append(s, "hue:"+randomHue)
+	} else if c.H != nil {
 		s = append(s, fmt.Sprintf("hue:%g", *c.H))
 	}
 	if c.S != nil {
@@ -196,6 +380,58 @@ func (c HSBKColor) MarshalText() ([]byte, error) {
 	return []byte(c.ColorString()), nil
 }
 
+// MarshalJSON encodes the color as the object the API reports it as, so a
+// Light written out decodes back unchanged. A random hue is written as
+// "hue": "random", which the API never reports. Requests carry colors as
+// strings instead, which State and Breathe take care of.
+func (c HSBKColor) MarshalJSON() ([]byte, error) {
+	type hsbk HSBKColor
+	if !c.RandomHue {
+		return json.Marshal(hsbk(c))
+	}
+	return json.Marshal(struct {
+		H string `json:"hue"`
+		hsbk
+	}{"random", hsbk(c)})
+}
+
+// UnmarshalJSON decodes the object MarshalJSON writes.
+func (c *HSBKColor) UnmarshalJSON(b []byte) error {
+	type hsbk HSBKColor
+	var v struct {
+		H json.RawMessage `json:"hue"`
+		*hsbk
+	}
+
+	*c = HSBKColor{}
+	v.hsbk = (*hsbk)(c)
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	c.H = nil
+	switch string(v.H) {
+	case "", "null":
+	case `"random"`:
+		c.RandomHue = true
+	default:
+		var h float32
+		if err := json.Unmarshal(v.H, &h); err != nil {
+			return fmt.Errorf("hue: %w", err)
+		}
+		c.H = &h
+	}
+	return nil
+}
+
+// colorString is how a color is sent in a request.
+func colorString(c Color) string {
+	if c == nil {
+		return ""
+	}
+	return c.ColorString()
+}
+
 func (c RGBColor) MarshalText() ([]byte, error) {
 	return []byte(c.ColorString()), nil
 }
@@ -209,25 +445,11 @@ func (c NamedColor) String() string {
 }
 
 func (c *Client) ValidateColor(color Color) (Color, error) {
-	var (
-		err  error
-		s    *HSBKColor
-		resp *Response
-	)
-
-	if resp, err = c.validateColor(color); err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
+	q := url.Values{"string": {color.ColorString()}}
 
-	if resp.IsError() {
-		return nil, resp.GetLifxError()
-	}
-
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+	s, err := doRequest[*HSBKColor](c, http.MethodGet, EndpointColor()+"?"+q.Encode(), nil)
+	if err != nil {
 		return nil, err
 	}
-
 	return s, nil
 }
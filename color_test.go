@@ -0,0 +1,47 @@
+package lifx
+
+import "testing"
+
+func FuzzParseColor(f *testing.F) {
+	for _, s := range []string{
+		"red",
+		"white",
+		"#ff8000",
+		"rgb:0,128,255",
+		"hue:120 saturation:1",
+		"kelvin:3500 brightness:0.5",
+		"blue saturation:0.5",
+		"random",
+		"hue:random kelvin:3500",
+		"hue:-1",
+		"rgb:1,2",
+		"",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := ParseColor(s)
+		if err != nil {
+			return
+		}
+
+		// Whatever parses must serialize to a string that parses back to
+		// the same components.
+		again, err := ParseColor(c.ColorString())
+		if err != nil {
+			t.Fatalf("%q parsed but its color string %q did not: %v", s, c.ColorString(), err)
+		}
+		if !equalFloat32(c.H, again.H) || !equalFloat32(c.S, again.S) || !equalFloat32(c.B, again.B) || !equalInt16(c.K, again.K) || c.RandomHue != again.RandomHue {
+			t.Fatalf("%q round-tripped through %q to %q", s, c.ColorString(), again.ColorString())
+		}
+	})
+}
+
+func equalFloat32(a, b *float32) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func equalInt16(a, b *int16) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
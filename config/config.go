@@ -0,0 +1,315 @@
+// Package config builds a lifx.Client from a configuration file and the
+// environment, so that every service sets clients up the same way.
+//
+// A file may be JSON or, with a .yaml or .yml extension, YAML:
+//
+//	token: c87c32a0...
+//	base_url: https://api.lifx.com/v1
+//	timeout: 10s
+//	retry:
+//	  attempts: 3
+//	  backoff: 500ms
+//	  max_backoff: 10s
+//	rate_limit:
+//	  requests: 120
+//	  window: 1m
+//
+// Retry values left out are those of lifx.DefaultRetryPolicy, and rate
+// limit values left out are the API's own limit.
+//
+// Environment variables override the file: LIFX_TOKEN, LIFX_ENDPOINT,
+// LIFX_USER_AGENT, LIFX_TIMEOUT, LIFX_RETRY_ATTEMPTS, LIFX_RETRY_BACKOFF,
+// LIFX_RETRY_MAX_BACKOFF, LIFX_RATE_LIMIT and LIFX_RATE_LIMIT_WINDOW.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/internal/miniyaml"
+)
+
+type (
+	Config struct {
+		Token     string     `json:"token"`
+		BaseURL   string     `json:"base_url"`
+		UserAgent string     `json:"user_agent"`
+		Timeout   Duration   `json:"timeout"`
+		Retry     *Retry     `json:"retry"`
+		RateLimit *RateLimit `json:"rate_limit"`
+	}
+
+	Retry struct {
+		Attempts   int      `json:"attempts"`
+		Backoff    Duration `json:"backoff"`
+		MaxBackoff Duration `json:"max_backoff"`
+	}
+
+	RateLimit struct {
+		Requests int      `json:"requests"`
+		Window   Duration `json:"window"`
+	}
+
+	// Duration accepts either a number of seconds or a string such as
+	// "1m30s".
+	Duration time.Duration
+)
+
+var ErrNoToken = errors.New("no access token configured, set LIFX_TOKEN or add a token to the config file")
+
+// Load reads the file at path, or at $LIFX_CONFIG when path is empty, and
+// applies the environment on top. Without either file, the configuration
+// comes from the environment alone.
+func Load(path string) (*Config, error) {
+	var c Config
+
+	if path == "" {
+		path = os.Getenv("LIFX_CONFIG")
+	}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = c.parse(b, filepath.Ext(path)); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := c.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// New is Load followed by NewClient.
+func New(path string, options ...func(*lifx.Client)) (*lifx.Client, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewClient(options...)
+}
+
+func (c *Config) parse(b []byte, ext string) error {
+	if ext != ".yaml" && ext != ".yml" {
+		dec := json.NewDecoder(strings.NewReader(string(b)))
+		dec.DisallowUnknownFields()
+		return dec.Decode(c)
+	}
+
+	doc, err := miniyaml.Parse(strings.NewReader(string(b)))
+	if err != nil {
+		return err
+	}
+
+	// Scalars come back as strings, so counts are restored to numbers
+	// before the document goes through the same decoding as JSON.
+	j, err := json.Marshal(typed(doc, ""))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(j)))
+	dec.DisallowUnknownFields()
+	return dec.Decode(c)
+}
+
+func typed(v interface{}, key string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = typed(e, k)
+		}
+		return m
+	case string:
+		// An empty mapping such as a bare "retry:" comes back as an
+		// empty string.
+		if v == "" && (key == "retry" || key == "rate_limit") {
+			return map[string]interface{}{}
+		}
+		if key != "attempts" && key != "requests" {
+			return v
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// ApplyEnv overrides the configuration with any LIFX_ environment
+// variables that are set.
+func (c *Config) ApplyEnv() error {
+	var err error
+
+	str := func(name string, dst *string) {
+		if v := os.Getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	duration := func(name string, dst *Duration) {
+		if v := os.Getenv(name); v != "" && err == nil {
+			if err = dst.parse(v); err != nil {
+				err = fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	integer := func(name string, dst *int) {
+		if v := os.Getenv(name); v != "" && err == nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				err = fmt.Errorf("%s must be a whole number", name)
+			}
+		}
+	}
+
+	str("LIFX_TOKEN", &c.Token)
+	str("LIFX_ENDPOINT", &c.BaseURL)
+	str("LIFX_USER_AGENT", &c.UserAgent)
+	duration("LIFX_TIMEOUT", &c.Timeout)
+
+	if os.Getenv("LIFX_RETRY_ATTEMPTS") != "" || os.Getenv("LIFX_RETRY_BACKOFF") != "" || os.Getenv("LIFX_RETRY_MAX_BACKOFF") != "" {
+		if c.Retry == nil {
+			r := defaultRetry()
+			c.Retry = &r
+		}
+		integer("LIFX_RETRY_ATTEMPTS", &c.Retry.Attempts)
+		duration("LIFX_RETRY_BACKOFF", &c.Retry.Backoff)
+		duration("LIFX_RETRY_MAX_BACKOFF", &c.Retry.MaxBackoff)
+	}
+
+	if os.Getenv("LIFX_RATE_LIMIT") != "" || os.Getenv("LIFX_RATE_LIMIT_WINDOW") != "" {
+		if c.RateLimit == nil {
+			c.RateLimit = &RateLimit{}
+		}
+		integer("LIFX_RATE_LIMIT", &c.RateLimit.Requests)
+		duration("LIFX_RATE_LIMIT_WINDOW", &c.RateLimit.Window)
+	}
+
+	return err
+}
+
+// UnmarshalJSON starts from the default policy, so that a retry section
+// only needs the values it changes.
+func (r *Retry) UnmarshalJSON(b []byte) error {
+	type retry Retry
+
+	v := retry(defaultRetry())
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	*r = Retry(v)
+	return nil
+}
+
+func defaultRetry() Retry {
+	return Retry{
+		Attempts:   lifx.DefaultRetryPolicy.Attempts,
+		Backoff:    Duration(lifx.DefaultRetryPolicy.Backoff),
+		MaxBackoff: Duration(lifx.DefaultRetryPolicy.MaxBackoff),
+	}
+}
+
+func (c *Config) Valid() error {
+	if c.Token == "" {
+		return ErrNoToken
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if c.Retry != nil && c.Retry.Attempts < 0 {
+		return errors.New("retry attempts cannot be negative")
+	}
+	if c.RateLimit != nil && c.RateLimit.Requests < 0 {
+		return errors.New("rate limit requests cannot be negative")
+	}
+	return nil
+}
+
+// Options returns the client options the configuration describes. A rate
+// limit section without values uses the API's own limit.
+func (c *Config) Options() []func(*lifx.Client) {
+	var options []func(*lifx.Client)
+
+	if c.BaseURL != "" {
+		options = append(options, lifx.WithEndpoint(c.BaseURL))
+	}
+	if c.UserAgent != "" {
+		options = append(options, lifx.WithUserAgent(c.UserAgent))
+	}
+	if c.Timeout > 0 {
+		options = append(options, lifx.WithTimeout(time.Duration(c.Timeout)))
+	}
+	if c.Retry != nil {
+		options = append(options, lifx.WithRetry(lifx.RetryPolicy{
+			Attempts:   c.Retry.Attempts,
+			Backoff:    time.Duration(c.Retry.Backoff),
+			MaxBackoff: time.Duration(c.Retry.MaxBackoff),
+		}))
+	}
+	if c.RateLimit != nil {
+		requests, window := c.RateLimit.Requests, time.Duration(c.RateLimit.Window)
+		if requests == 0 {
+			requests = lifx.DefaultRateLimit
+		}
+		if window == 0 {
+			window = lifx.DefaultRateLimitWindow
+		}
+		options = append(options, lifx.WithRateLimit(requests, window))
+	}
+
+	return options
+}
+
+// NewClient builds a client from the configuration. Options given here
+// are applied last, so they win over the configuration.
+func (c *Config) NewClient(options ...func(*lifx.Client)) (*lifx.Client, error) {
+	if err := c.Valid(); err != nil {
+		return nil, err
+	}
+	return lifx.NewClient(c.Token, append(c.Options(), options...)...), nil
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(v * float64(time.Second))
+		return nil
+	case string:
+		return d.parse(v)
+	}
+	return fmt.Errorf("invalid duration %s", b)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) parse(s string) error {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		*d = Duration(n * float64(time.Second))
+		return nil
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*d = Duration(v)
+	return nil
+}
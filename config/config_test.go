@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	def := defaultRetry()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    Config
+	}{
+		{
+			name: "yaml",
+			file: "lifx.yaml",
+			content: `token: abc
+timeout: 10s
+retry:
+  attempts: 5
+  backoff: 1s
+  max_backoff: 30s
+rate_limit:
+  requests: 60
+  window: 1m
+`,
+			want: Config{
+				Token:     "abc",
+				Timeout:   Duration(10 * time.Second),
+				Retry:     &Retry{Attempts: 5, Backoff: Duration(time.Second), MaxBackoff: Duration(30 * time.Second)},
+				RateLimit: &RateLimit{Requests: 60, Window: Duration(time.Minute)},
+			},
+		},
+		{
+			name: "yaml partial retry",
+			file: "lifx.yml",
+			content: `token: abc
+retry:
+  attempts: 5
+`,
+			want: Config{
+				Token: "abc",
+				Retry: &Retry{Attempts: 5, Backoff: def.Backoff, MaxBackoff: def.MaxBackoff},
+			},
+		},
+		{
+			name: "yaml empty sections",
+			file: "lifx.yaml",
+			content: `token: abc
+retry:
+rate_limit:
+`,
+			want: Config{Token: "abc", Retry: &def, RateLimit: &RateLimit{}},
+		},
+		{
+			name:    "json partial retry",
+			file:    "lifx.json",
+			content: `{"token": "abc", "retry": {"backoff": 2}}`,
+			want: Config{
+				Token: "abc",
+				Retry: &Retry{Attempts: def.Attempts, Backoff: Duration(2 * time.Second), MaxBackoff: def.MaxBackoff},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Load(writeConfig(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*c, tt.want) {
+				t.Errorf("Load = %+v, want %+v", *c, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown field", "lifx.json", `{"token": "abc", "colour": "red"}`},
+		{"unknown retry field", "lifx.json", `{"retry": {"tries": 3}}`},
+		{"unknown yaml retry field", "lifx.yaml", "retry:\n  tries: 3\n"},
+		{"bad duration", "lifx.yaml", "timeout: soon\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tt.file, tt.content)); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	path := writeConfig(t, "lifx.yaml", `token: abc
+retry:
+  attempts: 5
+  backoff: 1s
+`)
+	t.Setenv("LIFX_TOKEN", "xyz")
+	t.Setenv("LIFX_RETRY_ATTEMPTS", "2")
+	t.Setenv("LIFX_RATE_LIMIT_WINDOW", "30")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "xyz" {
+		t.Errorf("token = %q, want the environment's", c.Token)
+	}
+	if want := (Retry{Attempts: 2, Backoff: Duration(time.Second), MaxBackoff: defaultRetry().MaxBackoff}); *c.Retry != want {
+		t.Errorf("retry = %+v, want %+v", *c.Retry, want)
+	}
+	if want := (RateLimit{Window: Duration(30 * time.Second)}); c.RateLimit == nil || *c.RateLimit != want {
+		t.Errorf("rate limit = %+v, want %+v", c.RateLimit, want)
+	}
+
+	t.Setenv("LIFX_TIMEOUT", "soon")
+	if _, err := Load(path); err == nil {
+		t.Error("Load succeeded with a bad LIFX_TIMEOUT")
+	}
+}
+
+func TestNewClientNoToken(t *testing.T) {
+	t.Setenv("LIFX_TOKEN", "")
+	t.Setenv("LIFX_CONFIG", "")
+
+	if _, err := New(""); !errors.Is(err, ErrNoToken) {
+		t.Errorf("err = %v, want ErrNoToken", err)
+	}
+	if _, err := (&Config{Token: "abc", Timeout: -1}).NewClient(); err == nil {
+		t.Error("NewClient succeeded with a negative timeout")
+	}
+
+	c, err := (&Config{Token: "abc", RateLimit: &RateLimit{}}).NewClient(lifx.WithTimeout(time.Second))
+	if err != nil || c == nil {
+		t.Fatalf("NewClient = %v, %v", c, err)
+	}
+}
@@ -0,0 +1,223 @@
+//go:build contract
+
+package lifx
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// The contract tests run against the real API with the account behind
+// LIFX_TOKEN:
+//
+//	LIFX_TOKEN=... go test -tags contract -run Contract
+//
+// Every test that changes a light restores it afterwards. LIFX_SELECTOR
+// picks the light to use; by default it is the first connected one.
+
+func contractClient(t *testing.T) *Client {
+	t.Helper()
+
+	token := os.Getenv("LIFX_TOKEN")
+	if token == "" {
+		t.Skip("LIFX_TOKEN is not set")
+	}
+	return NewClient(token, WithUserAgent(userAgent))
+}
+
+func contractLight(t *testing.T, c *Client) Light {
+	t.Helper()
+
+	selector := os.Getenv("LIFX_SELECTOR")
+	if selector == "" {
+		selector = "all"
+	}
+
+	lights, err := c.ListLights(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range lights {
+		if l.Connected {
+			return l
+		}
+	}
+
+	t.Skip("no connected light to test against")
+	return Light{}
+}
+
+// restore puts the light back the way it was when the test started.
+func restore(t *testing.T, c *Client, l Light) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		_, err := c.SetState("id:"+l.Id, State{
+			Power:      l.Power,
+			Color:      l.Color,
+			Brightness: l.Brightness,
+		})
+		if err != nil {
+			t.Errorf("restoring %s: %v", l.Label, err)
+		}
+	})
+}
+
+func checkResults(t *testing.T, s *LifxResponse, id string) {
+	t.Helper()
+
+	if s == nil {
+		t.Fatal("no response body")
+	}
+	for _, r := range s.Results {
+		if r.Id == id {
+			if r.Status != OK {
+				t.Errorf("light %s reported %s", id, r.Status)
+			}
+			return
+		}
+	}
+	t.Errorf("light %s missing from results %+v", id, s.Results)
+}
+
+func TestContractListLights(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+
+	if l.Id == "" || l.Label == "" || l.Product.Identifier == "" {
+		t.Errorf("light is missing identity fields: %+v", l)
+	}
+	if l.Color.H == nil || l.Color.S == nil || l.Color.K == nil {
+		t.Errorf("light color did not decode: %+v", l.Color)
+	}
+	if l.LastSeen.IsZero() {
+		t.Error("last_seen did not decode")
+	}
+
+	lights, err := c.ListLights("id:" + l.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lights) != 1 || lights[0].Id != l.Id {
+		t.Errorf("id selector returned %d lights", len(lights))
+	}
+}
+
+func TestContractListLightsNoMatch(t *testing.T) {
+	c := contractClient(t)
+
+	if _, err := c.ListLights("id:000000000000"); err == nil {
+		t.Error("expected an error for a selector that matches nothing")
+	}
+}
+
+func TestContractValidateColor(t *testing.T) {
+	c := contractClient(t)
+
+	color, err := c.ValidateColor(NamedColor("red"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsbk, ok := color.(*HSBKColor)
+	if !ok || hsbk.H == nil || *hsbk.H != HueRed {
+		t.Errorf("red validated as %v", color)
+	}
+
+	if _, err = c.ValidateColor(NamedColor("not-a-color")); err == nil {
+		t.Error("expected an error for an invalid color")
+	}
+}
+
+func TestContractSetState(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	s, err := c.SetState("id:"+l.Id, State{Power: "on", Brightness: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, s, l.Id)
+}
+
+func TestContractFastSetState(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	if _, err := c.FastSetState("id:"+l.Id, State{Brightness: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContractSetStates(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	s, err := c.SetStates("", States{
+		States: []StateWithSelector{
+			{Selector: "id:" + l.Id, State: State{Brightness: 0.5}},
+		},
+		Defaults: State{Duration: 0.5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil {
+		t.Fatal("no response body")
+	}
+	op, ok := s.Operation("id:" + l.Id)
+	if !ok {
+		t.Fatalf("operation missing from results %+v", s.Results)
+	}
+	checkResults(t, &LifxResponse{Results: op.Results}, l.Id)
+}
+
+func TestContractStateDelta(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	s, err := c.StateDelta("id:"+l.Id, StateDelta{Brightness: Float64Ptr(-0.1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, s, l.Id)
+}
+
+func TestContractToggle(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	for i := 0; i < 2; i++ {
+		s, err := c.Toggle("id:"+l.Id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkResults(t, s, l.Id)
+	}
+}
+
+func TestContractBreathe(t *testing.T) {
+	c := contractClient(t)
+	l := contractLight(t, c)
+	restore(t, c, l)
+
+	b := NewBreathe()
+	b.Color = NamedColor("blue")
+	b.Period = 0.5
+	b.Cycles = 1
+	b.PowerOn = false
+
+	s, err := c.Breathe("id:"+l.Id, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, s, l.Id)
+
+	// Let the effect finish before the light is restored.
+	time.Sleep(time.Second)
+}
@@ -0,0 +1,32 @@
+// Package credentials keeps LIFX access tokens in the operating system's
+// credential store: the Keychain on macOS, Credential Manager on Windows,
+// and the Secret Service (through libsecret's secret-tool) elsewhere.
+package credentials
+
+import "errors"
+
+// Service names the tokens among the other secrets in the store.
+const Service = "lifx"
+
+var (
+	ErrNotFound    = errors.New("no token is stored for this account")
+	ErrUnsupported = errors.New("no credential store is available")
+)
+
+// Get returns the token stored for account.
+func Get(account string) (string, error) {
+	return get(account)
+}
+
+// Set stores token for account, replacing any token already stored.
+func Set(account, token string) error {
+	if token == "" {
+		return errors.New("token is empty")
+	}
+	return set(account, token)
+}
+
+// Delete removes the token stored for account.
+func Delete(account string) error {
+	return remove(account)
+}
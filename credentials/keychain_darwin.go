@@ -0,0 +1,77 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errItemNotFound is the exit status security uses for a missing item.
+const errItemNotFound = 44
+
+func get(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", keychainError(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func set(account, token string) error {
+	// The command goes to security -i on stdin, so that the token never
+	// appears in the process list the way an argument would. -U updates an
+	// existing item instead of failing.
+	line, err := command("add-generic-password", "-U", "-s", Service, "-a", account, "-l", "LIFX access token", "-w", token)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(line)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return keychainError(err)
+	}
+
+	// Interactive mode carries on after a failed command, so the failure
+	// only shows on stderr.
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("security: %s", msg)
+	}
+	return nil
+}
+
+func remove(account string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", Service, "-a", account).Run()
+	return keychainError(err)
+}
+
+// command quotes args as a line for security -i, which splits lines on
+// spaces outside double quotes. A line break would end the command early,
+// so no argument may contain one.
+func command(args ...string) (string, error) {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if strings.ContainsAny(a, "\r\n") {
+			return "", errors.New("keychain values cannot contain a line break")
+		}
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+	}
+	return strings.Join(quoted, " ") + "\n", nil
+}
+
+func keychainError(err error) error {
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exit) && exit.ExitCode() == errItemNotFound:
+		return ErrNotFound
+	case errors.Is(err, exec.ErrNotFound):
+		return ErrUnsupported
+	}
+	return err
+}
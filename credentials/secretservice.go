@@ -0,0 +1,54 @@
+//go:build !darwin && !windows
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func attributes(account string) []string {
+	return []string{"service", Service, "account", account}
+}
+
+func get(account string) (string, error) {
+	out, err := exec.Command("secret-tool", append([]string{"lookup"}, attributes(account)...)...).Output()
+	if err != nil {
+		// secret-tool exits with 1 and prints nothing for a missing item.
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && len(exit.Stderr) == 0 {
+			return "", ErrNotFound
+		}
+		return "", secretToolError(err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func set(account, token string) error {
+	args := append([]string{"store", "--label=LIFX access token"}, attributes(account)...)
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(token)
+	return secretToolError(cmd.Run())
+}
+
+func remove(account string) error {
+	if _, err := get(account); err != nil {
+		return err
+	}
+	return secretToolError(exec.Command("secret-tool", append([]string{"clear"}, attributes(account)...)...).Run())
+}
+
+func secretToolError(err error) error {
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, exec.ErrNotFound):
+		return fmt.Errorf("%w: install secret-tool from libsecret", ErrUnsupported)
+	case errors.As(err, &exit) && len(exit.Stderr) > 0:
+		return fmt.Errorf("secret-tool: %s", strings.TrimSpace(string(exit.Stderr)))
+	}
+	return err
+}
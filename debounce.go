@@ -0,0 +1,173 @@
+package lifx
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// DebounceConfig controls how a Debouncer treats a burst of states,
+	// which lasts until no state has arrived for Wait. Leading sends the
+	// first state of a burst straight away and Trailing the last once the
+	// burst is over; with neither set, Trailing is assumed. MaxWait, if
+	// set, bounds how long a pending state waits during a long burst, so a
+	// MaxWait equal to Wait throttles instead of debouncing.
+	DebounceConfig struct {
+		Wait     time.Duration
+		MaxWait  time.Duration
+		Leading  bool
+		Trailing bool
+	}
+
+	// Debouncer applies states from noisy sources, such as screen sync or
+	// a sensor, only once they settle. Each selector is debounced on its
+	// own and may have its own configuration.
+	Debouncer struct {
+		setter    StateSetter
+		config    DebounceConfig
+		selectors map[string]DebounceConfig
+		onError   func(selector string, err error)
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		bursts    map[string]*burst
+	}
+
+	burst struct {
+		timer      *time.Timer
+		state      State
+		pending    bool
+		firstQueue time.Time
+		lastCall   time.Time
+	}
+)
+
+func NewDebouncer(setter StateSetter, config DebounceConfig, options ...func(*Debouncer)) *Debouncer {
+	d := &Debouncer{
+		setter:    setter,
+		config:    config,
+		selectors: make(map[string]DebounceConfig),
+		bursts:    make(map[string]*burst),
+	}
+
+	for _, option := range options {
+		option(d)
+	}
+
+	return d
+}
+
+// WithSelectorDebounce gives states for selector their own configuration.
+func WithSelectorDebounce(selector string, config DebounceConfig) func(*Debouncer) {
+	return func(d *Debouncer) {
+		d.selectors[selector] = config
+	}
+}
+
+// WithDebounceErrors calls fn with the errors from sending states, which
+// would otherwise be dropped.
+func WithDebounceErrors(fn func(selector string, err error)) func(*Debouncer) {
+	return func(d *Debouncer) {
+		d.onError = fn
+	}
+}
+
+func (d *Debouncer) configFor(selector string) DebounceConfig {
+	c, ok := d.selectors[selector]
+	if !ok {
+		c = d.config
+	}
+	if !c.Leading && !c.Trailing {
+		c.Trailing = true
+	}
+	return c
+}
+
+// Apply hands state to the debouncer without blocking.
+func (d *Debouncer) Apply(selector string, state State) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.configFor(selector)
+	now := time.Now()
+
+	b, ok := d.bursts[selector]
+	if !ok {
+		b = &burst{}
+		d.bursts[selector] = b
+		d.wg.Add(1)
+		if c.Leading {
+			d.send(selector, state)
+		}
+	}
+	if ok || !c.Leading {
+		b.state, b.pending = state, true
+		if b.firstQueue.IsZero() {
+			b.firstQueue = now
+		}
+	}
+	b.lastCall = now
+
+	d.arm(selector, b, c, now)
+}
+
+// arm sets the burst's timer for when it ends, or earlier if MaxWait
+// would run out first.
+func (d *Debouncer) arm(selector string, b *burst, c DebounceConfig, now time.Time) {
+	delay := b.lastCall.Add(c.Wait).Sub(now)
+	if c.MaxWait > 0 && b.pending {
+		if until := b.firstQueue.Add(c.MaxWait).Sub(now); until < delay {
+			delay = until
+		}
+	}
+
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(delay, func() { d.fire(selector, b) })
+}
+
+func (d *Debouncer) fire(selector string, b *burst) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.bursts[selector] != b {
+		return
+	}
+
+	c := d.configFor(selector)
+	now := time.Now()
+	over := !now.Before(b.lastCall.Add(c.Wait))
+
+	// Reaching MaxWait sends even a leading-only burst's latest state, as
+	// otherwise it would never be sent while the burst lasts.
+	if b.pending && (c.Trailing || !over) {
+		d.send(selector, b.state)
+	}
+	b.pending, b.firstQueue = false, time.Time{}
+
+	if over {
+		delete(d.bursts, selector)
+		d.wg.Done()
+		return
+	}
+	d.arm(selector, b, c, now)
+}
+
+// Flush waits until every burst has ended and its states are sent.
+func (d *Debouncer) Flush() {
+	d.wg.Wait()
+}
+
+func (d *Debouncer) send(selector string, state State) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		err := safely(func() error {
+			_, err := d.setter.SetState(selector, state)
+			return err
+		})
+		if err != nil && d.onError != nil {
+			d.onError(selector, err)
+		}
+	}()
+}
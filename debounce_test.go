@@ -0,0 +1,72 @@
+package lifx_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestDebouncerEdges(t *testing.T) {
+	tests := []struct {
+		name   string
+		config lifx.DebounceConfig
+		want   []float64
+	}{
+		{"default", lifx.DebounceConfig{}, []float64{0.3}},
+		{"trailing", lifx.DebounceConfig{Trailing: true}, []float64{0.3}},
+		{"leading", lifx.DebounceConfig{Leading: true}, []float64{0.1}},
+		{"both", lifx.DebounceConfig{Leading: true, Trailing: true}, []float64{0.1, 0.3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := lifxtest.NewFakeClient(lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+			tt.config.Wait = 20 * time.Millisecond
+			d := lifx.NewDebouncer(f, tt.config)
+
+			for _, b := range []float64{0.1, 0.2, 0.3} {
+				d.Apply("label:Desk", lifx.State{Brightness: b})
+			}
+			d.Flush()
+
+			if got := sentBrightness(f); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sent %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebouncerSelectorConfig(t *testing.T) {
+	f := lifxtest.NewFakeClient(
+		lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"),
+		lifxtest.NewLight("d073d5000002", "Porch", "Outside", "Home"),
+	)
+	d := lifx.NewDebouncer(f, lifx.DebounceConfig{Wait: 20 * time.Millisecond},
+		lifx.WithSelectorDebounce("label:Porch", lifx.DebounceConfig{Wait: 20 * time.Millisecond, Leading: true}))
+
+	for _, b := range []float64{0.1, 0.2} {
+		d.Apply("label:Desk", lifx.State{Brightness: b})
+		d.Apply("label:Porch", lifx.State{Brightness: b})
+	}
+	d.Flush()
+
+	got := map[string]float64{}
+	for _, c := range f.CallsTo("SetState") {
+		got[c.Selector] = c.Arg.(lifx.State).Brightness
+	}
+	want := map[string]float64{"label:Desk": 0.2, "label:Porch": 0.1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %v, want %v", got, want)
+	}
+}
+
+func sentBrightness(f *lifxtest.FakeClient) []float64 {
+	var b []float64
+	for _, c := range f.CallsTo("SetState") {
+		b = append(b, c.Arg.(lifx.State).Brightness)
+	}
+	return b
+}
@@ -0,0 +1,114 @@
+package lifx
+
+import (
+	"errors"
+	"math"
+)
+
+// DeltaMerge combines two deltas into one that has the effect of sending a
+// and then b. Changes to levels add up, while b's power and duration win
+// over a's.
+func DeltaMerge(a, b StateDelta) StateDelta {
+	d := a
+
+	if b.Power != nil {
+		d.Power = b.Power
+	}
+	if b.Duration != nil {
+		d.Duration = b.Duration
+	}
+	d.Infrared = addFloat(a.Infrared, b.Infrared)
+	d.Hue = addFloat(a.Hue, b.Hue)
+	d.Saturation = addFloat(a.Saturation, b.Saturation)
+	d.Brightness = addFloat(a.Brightness, b.Brightness)
+	if b.Kelvin != nil {
+		k := *b.Kelvin
+		if a.Kelvin != nil {
+			k += *a.Kelvin
+		}
+		d.Kelvin = &k
+	}
+
+	return d
+}
+
+func addFloat(a, b *float64) *float64 {
+	switch {
+	case b == nil:
+		return a
+	case a == nil:
+		return b
+	}
+	return Float64Ptr(*a + *b)
+}
+
+// ApplyDelta works out the state that results from applying d to s, the
+// way the API applies a delta to a light: hue wraps around, and the other
+// levels stop at their limits. Changing the brightness, hue, saturation
+// or kelvin needs s to have that value to start from.
+func (s State) ApplyDelta(d StateDelta) (State, error) {
+	if d.Power != nil {
+		s.Power = *d.Power
+	}
+	if d.Duration != nil {
+		s.Duration = *d.Duration
+	}
+	if d.Infrared != nil {
+		s.Infrared = clamp(s.Infrared+*d.Infrared, 0, 1)
+	}
+
+	if d.Hue == nil && d.Saturation == nil && d.Kelvin == nil && d.Brightness == nil {
+		return s, nil
+	}
+
+	var c HSBKColor
+	if s.Color != nil {
+		var err error
+		if c, err = ParseColor(s.Color.ColorString()); err != nil {
+			return s, err
+		}
+	}
+
+	if d.Brightness != nil {
+		if s.Brightness == 0 && c.B == nil {
+			return s, errors.New("state has no brightness to change")
+		}
+		if s.Brightness != 0 {
+			s.Brightness = clamp(s.Brightness+*d.Brightness, 0, 1)
+		}
+		if c.B != nil {
+			c.B = Float32Ptr(float32(clamp(float64(*c.B)+*d.Brightness, 0, 1)))
+		}
+	}
+	if d.Hue != nil {
+		if c.H == nil {
+			return s, errors.New("state has no hue to change")
+		}
+		h := math.Mod(float64(*c.H)+*d.Hue, 360)
+		if h < 0 {
+			h += 360
+		}
+		c.H = Float32Ptr(float32(h))
+	}
+	if d.Saturation != nil {
+		if c.S == nil {
+			return s, errors.New("state has no saturation to change")
+		}
+		c.S = Float32Ptr(float32(clamp(float64(*c.S)+*d.Saturation, 0, 1)))
+	}
+	if d.Kelvin != nil {
+		if c.K == nil {
+			return s, errors.New("state has no kelvin to change")
+		}
+		c.K = Int16Ptr(int16(clamp(float64(int(*c.K)+*d.Kelvin), KelvinCandlelight, KelvinBlueIce)))
+	}
+
+	if s.Color != nil {
+		s.Color = c
+	}
+	return s, nil
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
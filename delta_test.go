@@ -0,0 +1,108 @@
+package lifx_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func TestDeltaMerge(t *testing.T) {
+	a := lifx.StateDelta{
+		Power:      lifx.StringPtr("on"),
+		Duration:   lifx.Float64Ptr(1),
+		Brightness: lifx.Float64Ptr(0.25),
+		Kelvin:     lifx.IntPtr(500),
+	}
+	b := lifx.StateDelta{
+		Power:      lifx.StringPtr("off"),
+		Hue:        lifx.Float64Ptr(30),
+		Brightness: lifx.Float64Ptr(0.5),
+		Kelvin:     lifx.IntPtr(-200),
+	}
+
+	got := lifx.DeltaMerge(a, b)
+	want := lifx.StateDelta{
+		Power:      lifx.StringPtr("off"),
+		Duration:   lifx.Float64Ptr(1),
+		Hue:        lifx.Float64Ptr(30),
+		Brightness: lifx.Float64Ptr(0.75),
+		Kelvin:     lifx.IntPtr(300),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeltaMerge = %s, want %s", deltaString(got), deltaString(want))
+	}
+	if *a.Brightness != 0.25 || *a.Kelvin != 500 {
+		t.Errorf("DeltaMerge changed a: %s", deltaString(a))
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	tests := []struct {
+		name  string
+		color string
+		delta lifx.StateDelta
+		want  string
+	}{
+		{"hue wraps", "hue:350 saturation:0.5", lifx.StateDelta{Hue: lifx.Float64Ptr(20)}, "hue:10 saturation:0.5"},
+		{"hue wraps below", "hue:10 saturation:0.5", lifx.StateDelta{Hue: lifx.Float64Ptr(-20)}, "hue:350 saturation:0.5"},
+		{"saturation stops", "hue:120 saturation:0.8", lifx.StateDelta{Saturation: lifx.Float64Ptr(0.5)}, "hue:120 saturation:1"},
+		{"kelvin stops", "kelvin:8500", lifx.StateDelta{Kelvin: lifx.IntPtr(1000)}, "kelvin:9000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := lifx.State{Color: lifx.NamedColor(tt.color)}.ApplyDelta(tt.delta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := lifx.ParseColor(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Color.ColorString(); got != want.ColorString() {
+				t.Errorf("color = %s, want %s", got, want.ColorString())
+			}
+		})
+	}
+}
+
+func TestApplyDeltaLevels(t *testing.T) {
+	s, err := lifx.State{Power: "off", Brightness: 0.9, Infrared: 0.5}.ApplyDelta(lifx.StateDelta{
+		Power:      lifx.StringPtr("on"),
+		Brightness: lifx.Float64Ptr(0.5),
+		Infrared:   lifx.Float64Ptr(-1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Power != "on" || s.Brightness != 1 || s.Infrared != 0 {
+		t.Errorf("state = %+v, want on at full brightness with no infrared", s)
+	}
+}
+
+func TestApplyDeltaMissing(t *testing.T) {
+	tests := []struct {
+		name  string
+		state lifx.State
+		delta lifx.StateDelta
+	}{
+		{"brightness", lifx.State{}, lifx.StateDelta{Brightness: lifx.Float64Ptr(0.1)}},
+		{"hue", lifx.State{Color: lifx.NamedColor("kelvin:3500")}, lifx.StateDelta{Hue: lifx.Float64Ptr(10)}},
+		{"saturation", lifx.State{}, lifx.StateDelta{Saturation: lifx.Float64Ptr(0.1)}},
+		{"kelvin", lifx.State{Color: lifx.NamedColor("hue:120")}, lifx.StateDelta{Kelvin: lifx.IntPtr(100)}},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.state.ApplyDelta(tt.delta); err == nil {
+			t.Errorf("%s: got no error changing a level the state lacks", tt.name)
+		}
+	}
+}
+
+// deltaString shows d as the API would be sent it.
+func deltaString(d lifx.StateDelta) string {
+	b, _ := json.Marshal(d)
+	return string(b)
+}
@@ -0,0 +1,213 @@
+package lifx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+)
+
+// MaxPalette is the most colors the API accepts in a morph palette.
+const MaxPalette = 16
+
+// MaxEffectPeriod is the longest period, in seconds, an effect can have.
+// The HTTP API documents no limit, but devices take the period as
+// milliseconds in a uint32: see SetWaveform, SetMultiZoneEffect and
+// SetTileEffect in the LAN protocol.
+const MaxEffectPeriod = math.MaxUint32 / 1000.0
+
+// FromCurrent is a FromColor that starts an effect from whatever color
+// each light shows at the time, so that it crossfades from there. Leaving
+// FromColor nil does the same; FromCurrent says so explicitly.
+var FromCurrent Color = currentColor{}
+
+type currentColor struct{}
+
+// Directions a Move effect can take.
+const (
+	MoveForward  = "forward"
+	MoveBackward = "backward"
+)
+
+type (
+	// Pulse switches between Color and FromColor Cycles times, each taking
+	// Period seconds. Without a FromColor, or with FromCurrent, it starts
+	// from each light's current color.
+	Pulse struct {
+		Color     Color   `json:"color,omitempty"`
+		FromColor Color   `json:"from_color,omitempty"`
+		Period    float64 `json:"period,omitempty"`
+		Cycles    float64 `json:"cycles,omitempty"`
+		Persist   bool    `json:"persist,omitempty"`
+		PowerOn   bool    `json:"power_on,omitempty"`
+	}
+
+	// Move shifts the colors along a multizone strip or beam. It runs
+	// until stopped unless Cycles is set.
+	Move struct {
+		Direction string  `json:"direction,omitempty"`
+		Period    float64 `json:"period,omitempty"`
+		Cycles    float64 `json:"cycles,omitempty"`
+		PowerOn   bool    `json:"power_on,omitempty"`
+		Fast      bool    `json:"fast,omitempty"`
+	}
+
+	// Morph blends the colors of Palette across a tile. It runs until
+	// stopped unless Duration is set.
+	Morph struct {
+		Period   float64 `json:"period,omitempty"`
+		Duration float64 `json:"duration,omitempty"`
+		Palette  []Color `json:"palette,omitempty"`
+		PowerOn  bool    `json:"power_on,omitempty"`
+		Fast     bool    `json:"fast,omitempty"`
+	}
+
+	// Flame flickers a tile like a fire. It runs until stopped unless
+	// Duration is set.
+	Flame struct {
+		Period   float64 `json:"period,omitempty"`
+		Duration float64 `json:"duration,omitempty"`
+		PowerOn  bool    `json:"power_on,omitempty"`
+		Fast     bool    `json:"fast,omitempty"`
+	}
+
+	// EffectsOff stops whatever effect is running, and turns the lights off
+	// as well when PowerOff is set.
+	EffectsOff struct {
+		PowerOff bool `json:"power_off,omitempty"`
+	}
+)
+
+func (c *Client) Pulse(selector string, pulse Pulse) (*LifxResponse, error) {
+	if err := pulse.Valid(); err != nil {
+		return nil, err
+	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointEffect(selector, "pulse"), pulse)
+}
+
+func (c *Client) Move(selector string, move Move) (*LifxResponse, error) {
+	if err := move.Valid(); err != nil {
+		return nil, err
+	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointEffect(selector, "move"), move)
+}
+
+func (c *Client) Morph(selector string, morph Morph) (*LifxResponse, error) {
+	if err := morph.Valid(); err != nil {
+		return nil, err
+	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointEffect(selector, "morph"), morph)
+}
+
+func (c *Client) Flame(selector string, flame Flame) (*LifxResponse, error) {
+	if err := flame.Valid(); err != nil {
+		return nil, err
+	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointEffect(selector, "flame"), flame)
+}
+
+func (c *Client) EffectsOff(selector string, off EffectsOff) (*LifxResponse, error) {
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointEffect(selector, "off"), off)
+}
+
+func (p *Pulse) Valid() error {
+	if p.Color == nil {
+		return errors.New("pulse needs a color")
+	}
+	if err := validFromColor("pulse", p.FromColor); err != nil {
+		return err
+	}
+	return validTiming("pulse", p.Period, p.Cycles, 0)
+}
+
+func (m *Move) Valid() error {
+	switch m.Direction {
+	case "", MoveForward, MoveBackward:
+	default:
+		return fmt.Errorf("move direction must be %s or %s, not '%s'", MoveForward, MoveBackward, m.Direction)
+	}
+	return validTiming("move", m.Period, m.Cycles, 0)
+}
+
+func (m *Morph) Valid() error {
+	if len(m.Palette) > MaxPalette {
+		return fmt.Errorf("morph palette has %d colors, at most %d are allowed", len(m.Palette), MaxPalette)
+	}
+	for i, c := range m.Palette {
+		if c == nil {
+			return fmt.Errorf("morph palette color %d is missing", i)
+		}
+	}
+	return validTiming("morph", m.Period, 0, m.Duration)
+}
+
+func (f *Flame) Valid() error {
+	return validTiming("flame", f.Period, 0, f.Duration)
+}
+
+// StartsFromCurrent reports whether an effect with from as its FromColor
+// starts from the lights' current color.
+func StartsFromCurrent(from Color) bool {
+	return from == nil || from == FromCurrent
+}
+
+// ColorString is empty, which leaves from_color out of a request.
+func (currentColor) ColorString() string { return "" }
+
+func validFromColor(effect string, from Color) error {
+	if StartsFromCurrent(from) {
+		return nil
+	}
+	if _, err := ParseColor(from.ColorString()); err != nil {
+		return fmt.Errorf("%s from_color: %w", effect, err)
+	}
+	return nil
+}
+
+// validTiming checks the timings effects share. Zero leaves a timing to
+// the API's default. Cycles and durations have no limit beyond being
+// finite.
+func validTiming(effect string, period, cycles, duration float64) error {
+	for _, t := range []struct {
+		name  string
+		value float64
+	}{
+		{"period", period},
+		{"cycles", cycles},
+		{"duration", duration},
+	} {
+		switch {
+		case math.IsNaN(t.value) || math.IsInf(t.value, 0):
+			return fmt.Errorf("%s %s must be a finite number", effect, t.name)
+		case t.value < 0:
+			return fmt.Errorf("%s %s must not be negative", effect, t.name)
+		}
+	}
+	if period > MaxEffectPeriod {
+		return fmt.Errorf("%s period must be at most %.3f seconds", effect, MaxEffectPeriod)
+	}
+	return nil
+}
+
+func (p Pulse) MarshalJSON() ([]byte, error) {
+	type pulse Pulse
+	return json.Marshal(struct {
+		pulse
+		Color     string `json:"color,omitempty"`
+		FromColor string `json:"from_color,omitempty"`
+	}{pulse(p), colorString(p.Color), colorString(p.FromColor)})
+}
+
+func (m Morph) MarshalJSON() ([]byte, error) {
+	type morph Morph
+
+	var palette []string
+	for _, c := range m.Palette {
+		palette = append(palette, colorString(c))
+	}
+	return json.Marshal(struct {
+		morph
+		Palette []string `json:"palette,omitempty"`
+	}{morph(m), palette})
+}
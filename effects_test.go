@@ -0,0 +1,64 @@
+package lifx
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEffectValid(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	red := NamedColor("red")
+
+	tests := []struct {
+		name   string
+		effect interface{ Valid() error }
+		err    string
+	}{
+		{"breathe", &Breathe{Color: red, Period: 1, Cycles: 2, Peak: 0.5}, ""},
+		{"breathe without color", &Breathe{}, "needs a color"},
+		{"breathe peak above one", &Breathe{Color: red, Peak: 1.5}, "peak"},
+		{"breathe peak nan", &Breathe{Color: red, Peak: nan}, "peak"},
+		{"breathe bad from color", &Breathe{Color: red, FromColor: NamedColor("nope")}, "from_color"},
+		{"breathe negative period", &Breathe{Color: red, Period: -1}, "period must not be negative"},
+		{"breathe nan period", &Breathe{Color: red, Period: nan}, "period must be a finite number"},
+		{"breathe infinite cycles", &Breathe{Color: red, Cycles: inf}, "cycles must be a finite number"},
+		{"breathe longest period", &Breathe{Color: red, Period: MaxEffectPeriod}, ""},
+		{"breathe period too long", &Breathe{Color: red, Period: MaxEffectPeriod + 1}, "period must be at most"},
+
+		{"pulse", &Pulse{Color: red, FromColor: FromCurrent, Period: 1, Cycles: 3}, ""},
+		{"pulse without color", &Pulse{}, "needs a color"},
+		{"pulse negative cycles", &Pulse{Color: red, Cycles: -1}, "cycles must not be negative"},
+		{"pulse nan cycles", &Pulse{Color: red, Cycles: nan}, "cycles must be a finite number"},
+		{"pulse infinite period", &Pulse{Color: red, Period: math.Inf(-1)}, "period must be a finite number"},
+
+		{"move", &Move{Direction: MoveBackward, Period: 2}, ""},
+		{"move default direction", &Move{}, ""},
+		{"move bad direction", &Move{Direction: "sideways"}, "direction"},
+		{"move nan period", &Move{Period: nan}, "period must be a finite number"},
+		{"move infinite cycles", &Move{Cycles: inf}, "cycles must be a finite number"},
+
+		{"morph", &Morph{Period: 5, Duration: 60, Palette: []Color{red, NamedColor("blue")}}, ""},
+		{"morph palette too long", &Morph{Palette: make([]Color, MaxPalette+1)}, "at most"},
+		{"morph missing color", &Morph{Palette: []Color{red, nil}}, "color 1 is missing"},
+		{"morph negative duration", &Morph{Duration: -1}, "duration must not be negative"},
+		{"morph infinite duration", &Morph{Duration: inf}, "duration must be a finite number"},
+
+		{"flame", &Flame{Period: 3, Duration: 10}, ""},
+		{"flame negative period", &Flame{Period: -3}, "period must not be negative"},
+		{"flame nan duration", &Flame{Duration: nan}, "duration must be a finite number"},
+		{"flame infinite period", &Flame{Period: inf}, "period must be a finite number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.effect.Valid()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Valid() = %v, want nil", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("Valid() = %v, want an error mentioning %q", err, tt.err)
+			}
+		})
+	}
+}
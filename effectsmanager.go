@@ -0,0 +1,139 @@
+package lifx
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// RunningEffect is an effect an EffectsManager started. Ends is the
+	// zero time for effects that run until they are stopped.
+	RunningEffect struct {
+		Selector string
+		Effect   Effect
+		Started  time.Time
+		Ends     time.Time
+	}
+
+	// EffectsManager starts effects through a client and remembers which
+	// are running where, so that they can be stopped together later. It
+	// only knows about effects it started: one started elsewhere, or
+	// stopped by changing the lights' state, is not noticed. Selectors are
+	// compared as written, and a new effect on a selector replaces the
+	// one before it.
+	EffectsManager struct {
+		client  *Client
+		mu      sync.Mutex
+		running map[string]RunningEffect
+	}
+)
+
+func NewEffectsManager(c *Client) *EffectsManager {
+	return &EffectsManager{client: c, running: make(map[string]RunningEffect)}
+}
+
+func (m *EffectsManager) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
+	r, err := m.client.Breathe(selector, breathe)
+	return r, m.started(selector, EffectBreathe, cyclesFor(breathe.Period, breathe.Cycles, 1), err)
+}
+
+func (m *EffectsManager) Pulse(selector string, pulse Pulse) (*LifxResponse, error) {
+	r, err := m.client.Pulse(selector, pulse)
+	return r, m.started(selector, EffectPulse, cyclesFor(pulse.Period, pulse.Cycles, 1), err)
+}
+
+func (m *EffectsManager) Move(selector string, move Move) (*LifxResponse, error) {
+	r, err := m.client.Move(selector, move)
+	return r, m.started(selector, EffectMove, cyclesFor(move.Period, move.Cycles, 0), err)
+}
+
+func (m *EffectsManager) Morph(selector string, morph Morph) (*LifxResponse, error) {
+	r, err := m.client.Morph(selector, morph)
+	return r, m.started(selector, EffectMorph, seconds(morph.Duration), err)
+}
+
+func (m *EffectsManager) Flame(selector string, flame Flame) (*LifxResponse, error) {
+	r, err := m.client.Flame(selector, flame)
+	return r, m.started(selector, EffectFlame, seconds(flame.Duration), err)
+}
+
+// Running returns the effects still running, ordered by selector.
+// Effects that have run their course are forgotten.
+func (m *EffectsManager) Running() []RunningEffect {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.client.now()
+	effects := make([]RunningEffect, 0, len(m.running))
+	for s, e := range m.running {
+		if !e.Ends.IsZero() && !now.Before(e.Ends) {
+			delete(m.running, s)
+			continue
+		}
+		effects = append(effects, e)
+	}
+
+	sort.Slice(effects, func(i, j int) bool {
+		return effects[i].Selector < effects[j].Selector
+	})
+	return effects
+}
+
+// StopSelector stops the effect on selector, whoever started it.
+func (m *EffectsManager) StopSelector(selector string) (*LifxResponse, error) {
+	r, err := m.client.EffectsOff(selector, EffectsOff{})
+	if err == nil {
+		m.mu.Lock()
+		delete(m.running, selector)
+		m.mu.Unlock()
+	}
+	return r, err
+}
+
+// StopAll stops every effect the manager knows to be running. Selectors
+// that fail to stop are still remembered, and the first error is
+// returned once all have been tried.
+func (m *EffectsManager) StopAll() error {
+	var first error
+
+	for _, e := range m.Running() {
+		if _, err := m.StopSelector(e.Selector); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *EffectsManager) started(selector string, effect Effect, length time.Duration, err error) error {
+	if err != nil {
+		return err
+	}
+
+	e := RunningEffect{Selector: selector, Effect: effect, Started: m.client.now()}
+	if length > 0 {
+		e.Ends = e.Started.Add(length)
+	}
+
+	m.mu.Lock()
+	m.running[selector] = e
+	m.mu.Unlock()
+	return nil
+}
+
+// cyclesFor is how long an effect of cycles periods lasts, using the
+// API's default of one second a period and defaultCycles, where zero
+// means it runs until stopped.
+func cyclesFor(period, cycles, defaultCycles float64) time.Duration {
+	if period == 0 {
+		period = 1
+	}
+	if cycles == 0 {
+		cycles = defaultCycles
+	}
+	return seconds(period * cycles)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -35,4 +35,13 @@ var (
 	EndpointBreathe = func(selector string) string {
 		return BuildURL(Endpoint, fmt.Sprintf("/lights/%s/effects/breathe", selector))
 	}
+	EndpointEffect = func(selector, name string) string {
+		return BuildURL(Endpoint, fmt.Sprintf("/lights/%s/effects/%s", selector, name))
+	}
+	EndpointScenes = func() string {
+		return BuildURL(Endpoint, "/scenes")
+	}
+	EndpointActivateScene = func(selector string) string {
+		return BuildURL(Endpoint, fmt.Sprintf("/scenes/%s/activate", selector))
+	}
 )
@@ -0,0 +1,126 @@
+package lifx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// exportVersion is the version of AccountExport written by Export. Import
+// reads it and every version before it.
+const exportVersion = 1
+
+// AccountExport is everything the API tells about an account at one
+// moment, for backups and for moving a setup to another account. It is
+// written out as JSON, which YAML tools read as well.
+type AccountExport struct {
+	Version   int       `json:"version"`
+	Exported  time.Time `json:"exported"`
+	Lights    []Light   `json:"lights"`
+	Groups    []Summary `json:"groups"`
+	Locations []Summary `json:"locations"`
+	Scenes    []Scene   `json:"scenes"`
+}
+
+// Export gathers the account's lights, with their current states, and its
+// groups, locations and scenes.
+func (c *Client) Export() (*AccountExport, error) {
+	c = c.correlated()
+
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return nil, err
+	}
+	scenes, err := c.ListScenes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountExport{
+		Version:   exportVersion,
+		Exported:  c.now(),
+		Lights:    lights,
+		Groups:    Groups(lights),
+		Locations: Locations(lights),
+		Scenes:    scenes,
+	}, nil
+}
+
+// Import reads an export written out as JSON.
+func Import(r io.Reader) (*AccountExport, error) {
+	var e AccountExport
+
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, err
+	}
+	switch {
+	case e.Version == 0:
+		return nil, errors.New("not an account export, it has no version")
+	case e.Version > exportVersion:
+		return nil, fmt.Errorf("account export version %d is newer than this library, which reads up to %d", e.Version, exportVersion)
+	}
+	return &e, nil
+}
+
+// ApplyExport returns the account's lights to the power, color and
+// brightness recorded in e over duration seconds. Lights are matched by id
+// and then by label, so an export can be applied to replacement bulbs or
+// another account as long as labels are unique. Lights that were not
+// connected when e was taken, or that match no light now, are left out.
+//
+// The API cannot rename lights, regroup them or create scenes, so the
+// rest of e is only a record; its scenes can still be applied with
+// ApplyLocalScene through Scene.Local.
+func (c *Client) ApplyExport(e *AccountExport, duration float64) (*SetStatesResponse, error) {
+	c = c.correlated()
+
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(lights))
+	labels := make(map[string][]string)
+	for _, l := range lights {
+		ids[l.Id] = true
+		labels[l.Label] = append(labels[l.Label], l.Id)
+	}
+
+	var states States
+	for _, l := range e.Lights {
+		if !l.Connected {
+			continue
+		}
+
+		id := l.Id
+		if !ids[id] {
+			if same := labels[l.Label]; len(same) == 1 {
+				id = same[0]
+			} else {
+				continue
+			}
+		}
+		states.States = append(states.States, StateWithSelector{
+			State:    l.ToState(),
+			Selector: ById(id).String(),
+		})
+	}
+	if len(states.States) == 0 {
+		return nil, errors.New("no light in the export matches a light on the account")
+	}
+	states.Defaults.Duration = duration
+
+	return c.SetStates("", states)
+}
+
+// Local turns s into a LocalScene, so that a scene from an export can be
+// applied where it doesn't exist.
+func (s Scene) Local() LocalScene {
+	l := LocalScene{Name: s.Name}
+	for _, st := range s.States {
+		l.States = append(l.States, StateWithSelector{State: st.ToState(), Selector: st.Selector})
+	}
+	return l
+}
@@ -0,0 +1,187 @@
+package lifx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	// LightMatch is a light that a query could refer to. Score runs from
+	// zero to one, where one is an exact match.
+	LightMatch struct {
+		Light Light
+		Score float64
+	}
+
+	// AmbiguousError is returned by FindLight when several lights match a
+	// query equally well. Candidates are ranked best first.
+	AmbiguousError struct {
+		Query      string
+		Candidates []LightMatch
+	}
+)
+
+// minSimilarity is how close a misspelling has to be to count as a match.
+const minSimilarity = 0.6
+
+const (
+	scoreExact  = 1
+	scorePrefix = 0.9
+	scoreWords  = 0.8
+)
+
+// FindLight lists every light and resolves query to one of them with
+// FindLight.
+func (c *Client) FindLight(query string) (Light, error) {
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return Light{}, err
+	}
+	return FindLight(lights, query)
+}
+
+// FindLight resolves a spoken or typed name, such as "kitchen lamp", to a
+// light. Labels are compared ignoring case, punctuation and spacing, and
+// may also be prefixed by the light's group. When no label matches
+// exactly, prefixes, labels containing every word of the query and close
+// misspellings are tried in turn. Lights matching equally well are
+// reported as an *AmbiguousError listing the candidates.
+func FindLight(lights []Light, query string) (Light, error) {
+	matches := MatchLights(lights, query)
+
+	switch {
+	case len(matches) == 0:
+		return Light{}, fmt.Errorf("no light matches '%s'", query)
+	case len(matches) == 1 || matches[0].Score > matches[1].Score:
+		return matches[0].Light, nil
+	}
+
+	var tied []LightMatch
+	for _, m := range matches {
+		if m.Score == matches[0].Score {
+			tied = append(tied, m)
+		}
+	}
+	return Light{}, &AmbiguousError{Query: query, Candidates: tied}
+}
+
+// MatchLights ranks the lights that query could refer to, best first.
+// Lights that do not match at all are left out.
+func MatchLights(lights []Light, query string) []LightMatch {
+	var matches []LightMatch
+
+	q := normalizeLabel(query)
+	if q == "" {
+		return nil
+	}
+
+	for _, l := range lights {
+		label := normalizeLabel(l.Label)
+		score := matchScore(label, q)
+		if l.Group.Name != "" {
+			if s := matchScore(normalizeLabel(l.Group.Name)+" "+label, q); s > score {
+				score = s
+			}
+		}
+		if score > 0 {
+			matches = append(matches, LightMatch{Light: l, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+func (e *AmbiguousError) Error() string {
+	labels := make([]string, len(e.Candidates))
+	for i, m := range e.Candidates {
+		labels[i] = m.Light.Label
+	}
+	return fmt.Sprintf("'%s' could be any of %s", e.Query, strings.Join(labels, ", "))
+}
+
+func matchScore(label, query string) float64 {
+	switch {
+	case label == query:
+		return scoreExact
+	case strings.HasPrefix(label, query):
+		return scorePrefix
+	case containsWords(label, query):
+		return scoreWords
+	}
+
+	n := len([]rune(label))
+	if m := len([]rune(query)); m > n {
+		n = m
+	}
+	sim := 1 - float64(levenshtein(label, query))/float64(n)
+	if sim < minSimilarity {
+		return 0
+	}
+	// Scaled below the other kinds of match, which are always better than
+	// a misspelling.
+	return sim * scoreWords * 0.99
+}
+
+// containsWords reports whether every word of query starts a word of
+// label, in any order.
+func containsWords(label, query string) bool {
+	words := strings.Fields(label)
+	for _, q := range strings.Fields(query) {
+		found := false
+		for _, w := range words {
+			if strings.HasPrefix(w, q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLabel lower-cases s and reduces anything but letters and digits
+// to single spaces.
+func normalizeLabel(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
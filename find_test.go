@@ -0,0 +1,82 @@
+package lifx_test
+
+import (
+	"errors"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func findLights() []lifx.Light {
+	return []lifx.Light{
+		lifxtest.NewLight("d073d5000001", "Kitchen Lamp", "Kitchen", "Home"),
+		lifxtest.NewLight("d073d5000002", "Kitchen Ceiling", "Kitchen", "Home"),
+		lifxtest.NewLight("d073d5000003", "Desk", "Office", "Home"),
+		lifxtest.NewLight("d073d5000004", "Lamp", "Bedroom", "Home"),
+		lifxtest.NewLight("d073d5000005", "Lamp", "Lounge", "Home"),
+	}
+}
+
+func TestFindLight(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"kitchen lamp", "d073d5000001"},
+		{"KITCHEN-lamp!", "d073d5000001"},
+		{"desk", "d073d5000003"},
+		{"de", "d073d5000003"},
+		{"ceiling kitchen", "d073d5000002"},
+		{"bedroom lamp", "d073d5000004"},
+		{"dezk", "d073d5000003"},
+	}
+
+	for _, tt := range tests {
+		l, err := lifx.FindLight(findLights(), tt.query)
+		if err != nil {
+			t.Errorf("FindLight(%q): %v", tt.query, err)
+			continue
+		}
+		if l.Id != tt.want {
+			t.Errorf("FindLight(%q) = %s (%s), want %s", tt.query, l.Id, l.Label, tt.want)
+		}
+	}
+}
+
+func TestFindLightAmbiguous(t *testing.T) {
+	_, err := lifx.FindLight(findLights(), "lamp")
+
+	var e *lifx.AmbiguousError
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %v, want an AmbiguousError", err)
+	}
+	if len(e.Candidates) != 2 {
+		t.Fatalf("candidates = %+v, want the two lamps", e.Candidates)
+	}
+	for _, m := range e.Candidates {
+		if m.Light.Label != "Lamp" || m.Score != 1 {
+			t.Errorf("candidate %s scored %v, want an exact Lamp", m.Light.Label, m.Score)
+		}
+	}
+}
+
+func TestFindLightNoMatch(t *testing.T) {
+	for _, q := range []string{"garage", "", "  !! "} {
+		if l, err := lifx.FindLight(findLights(), q); err == nil {
+			t.Errorf("FindLight(%q) = %s, want an error", q, l.Label)
+		}
+	}
+}
+
+func TestMatchLightsRanking(t *testing.T) {
+	matches := lifx.MatchLights(findLights(), "kitchen")
+	if len(matches) != 2 {
+		t.Fatalf("got %d matches, want 2", len(matches))
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("match %d scored %v above %v", i, matches[i].Score, matches[i-1].Score)
+		}
+	}
+}
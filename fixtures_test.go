@@ -0,0 +1,392 @@
+package lifx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func decodeFixture(t *testing.T, name string, v interface{}, strict bool) {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", "fixtures", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	d := json.NewDecoder(f)
+	if strict {
+		d.DisallowUnknownFields()
+	}
+	if err = d.Decode(v); err != nil {
+		t.Fatalf("decoding %s: %v", name, err)
+	}
+}
+
+// TestFixturesStrict fails when a fixture carries a field none of the
+// structs model, which is how new API fields are noticed.
+func TestFixturesStrict(t *testing.T) {
+	tests := []struct {
+		file string
+		v    interface{}
+	}{
+		{"lights_color.json", &[]Light{}},
+		{"lights_multizone.json", &[]Light{}},
+		{"lights_tile.json", &[]Light{}},
+		{"lights_hev.json", &[]Light{}},
+		{"lights_effect.json", &[]Light{}},
+		{"error_not_found.json", &LifxResponse{}},
+		{"error_validation.json", &LifxResponse{}},
+		{"results_warnings.json", &LifxResponse{}},
+		{"results_status_unknown.json", &LifxResponse{}},
+		{"results_states.json", &SetStatesResponse{}},
+		// Scene decodes through sceneJSON, which is where the strict
+		// decoder has to look.
+		{"scenes.json", &[]sceneJSON{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			decodeFixture(t, tt.file, tt.v, true)
+		})
+	}
+}
+
+func TestFixtureLights(t *testing.T) {
+	var lights []Light
+
+	decodeFixture(t, "lights_color.json", &lights, false)
+
+	if len(lights) != 2 {
+		t.Fatalf("got %d lights, want 2", len(lights))
+	}
+
+	l := lights[0]
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"id", l.Id, "d073d5000001"},
+		{"uuid", l.UUID, "8fa5f072-af97-44ed-ae54-e70fd7bd9d20"},
+		{"label", l.Label, "Left Lamp"},
+		{"connected", l.Connected, true},
+		{"power", l.Power, "on"},
+		{"hue", *l.Color.H, float32(250)},
+		{"saturation", *l.Color.S, float32(0.5)},
+		{"color brightness", *l.Color.B, float32(0.75)},
+		{"kelvin", *l.Color.K, int16(3500)},
+		{"brightness", l.Brightness, 0.75},
+		{"effect", l.Effect, EffectNone},
+		{"effect running", l.IsEffectRunning(), false},
+		{"group id", l.Group.Id, "1c8de82b81f445e7cfaafae49b259c71"},
+		{"group name", l.Group.Name, "Lounge"},
+		{"location id", l.Location.Id, "1d6fe8ef0fde4c6d77b0012dc736662c"},
+		{"location name", l.Location.Name, "Home"},
+		{"product name", l.Product.Name, "LIFX A19"},
+		{"product identifier", l.Product.Identifier, "lifx_a19"},
+		{"product company", l.Product.Company, "LIFX"},
+		{"vendor id", l.Product.VendorId, 1},
+		{"product id", l.Product.ProductId, 43},
+		{"has color", l.Product.Capabilities.HasColor, true},
+		{"has variable color temp", l.Product.Capabilities.HasVariableColorTemp, true},
+		{"min kelvin", l.Product.Capabilities.MinKelvin, 2500.0},
+		{"max kelvin", l.Product.Capabilities.MaxKelvin, 9000.0},
+		{"last seen", l.LastSeen, time.Date(2021, 3, 2, 8, 53, 2, 0, time.UTC)},
+		{"seconds since seen", l.SecondsLastSeen, 12.0},
+		{"zones", l.Zones == nil, true},
+		{"chain", l.Chain == nil, true},
+		{"offline", lights[1].Connected, false},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestFixtureRoundTrip checks that lights written back out as JSON, such as
+// for a snapshot, decode to the same values.
+func TestFixtureRoundTrip(t *testing.T) {
+	for _, file := range []string{"lights_color.json", "lights_multizone.json", "lights_tile.json", "lights_effect.json"} {
+		var lights, again []Light
+
+		decodeFixture(t, file, &lights, true)
+		b, err := json.Marshal(lights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		if err = d.Decode(&again); err != nil {
+			t.Fatalf("%s: decoding marshaled lights: %v", file, err)
+		}
+		if !reflect.DeepEqual(lights, again) {
+			t.Errorf("%s: got %+v, want %+v", file, again, lights)
+		}
+	}
+}
+
+func TestFixtureScenes(t *testing.T) {
+	var scenes, again []Scene
+
+	decodeFixture(t, "scenes.json", &scenes, false)
+
+	if len(scenes) != 1 {
+		t.Fatalf("got %d scenes, want 1", len(scenes))
+	}
+	s := scenes[0]
+	if s.Name != "Evening" || s.Account.UUID != "4f2c6a9d-61f3-4a0e-8d2b-0c5e7b1a9f33" {
+		t.Errorf("got scene %+v", s)
+	}
+	if want := time.Unix(1439503528, 0); !s.CreatedAt.Equal(want) {
+		t.Errorf("created at %v, want %v", s.CreatedAt, want)
+	}
+	if want := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC); !s.UpdatedAt.Equal(want) {
+		t.Errorf("updated at %v, want %v", s.UpdatedAt, want)
+	}
+	if len(s.States) != 2 {
+		t.Fatalf("got %d states, want 2", len(s.States))
+	}
+	st := s.States[0].ToState()
+	if st.Power != "on" || st.Brightness != 0.4 || st.Color.ColorString() != "hue:30 saturation:0.8 kelvin:2700" {
+		t.Errorf("got state %+v with color %s", st, st.Color.ColorString())
+	}
+
+	b, err := json.Marshal(scenes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal(b, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scenes, again) {
+		t.Errorf("got %+v, want %+v", again, scenes)
+	}
+}
+
+func TestFixtureMultizone(t *testing.T) {
+	var lights []Light
+
+	decodeFixture(t, "lights_multizone.json", &lights, false)
+
+	l := lights[0]
+	if !l.Product.Capabilities.HasMultizone {
+		t.Error("has_multizone did not decode")
+	}
+	if l.Zones == nil {
+		t.Fatal("zones did not decode")
+	}
+	if l.Zones.Count != 3 || len(l.Zones.Zones) != 3 {
+		t.Fatalf("got %d zones (count %d), want 3", len(l.Zones.Zones), l.Zones.Count)
+	}
+
+	want := Zone{Zone: 2, Hue: 240, Saturation: 1, Brightness: 0.5, Kelvin: 3500}
+	if got := l.Zones.Zones[2]; got != want {
+		t.Errorf("got zone %+v, want %+v", got, want)
+	}
+}
+
+func TestFixtureTile(t *testing.T) {
+	var lights []Light
+
+	decodeFixture(t, "lights_tile.json", &lights, false)
+
+	l := lights[0]
+	if !l.Product.Capabilities.HasChain || !l.Product.Capabilities.HasMatrix {
+		t.Error("has_chain or has_matrix did not decode")
+	}
+	if l.Chain == nil || len(l.Chain.Children) != 2 {
+		t.Fatal("chain did not decode")
+	}
+
+	if l.Effect != EffectMorph || !l.IsEffectRunning() {
+		t.Errorf("got effect %s, want %s", l.Effect, EffectMorph)
+	}
+
+	want := ChainChild{Index: 1, UserX: 1, UserY: 0, Width: 8, Height: 8}
+	if got := l.Chain.Children[1]; got != want {
+		t.Errorf("got child %+v, want %+v", got, want)
+	}
+}
+
+// TestFixtureEffect covers newer responses, which describe the effect as
+// an object.
+func TestFixtureEffect(t *testing.T) {
+	var lights []Light
+
+	decodeFixture(t, "lights_effect.json", &lights, true)
+
+	if got := lights[0].Effect; got != EffectFlame {
+		t.Errorf("got effect %s, want %s", got, EffectFlame)
+	}
+}
+
+func TestFixtureHEV(t *testing.T) {
+	var lights []Light
+
+	decodeFixture(t, "lights_hev.json", &lights, false)
+
+	if !lights[0].Product.Capabilities.HasHEV {
+		t.Error("has_hev did not decode")
+	}
+}
+
+func TestFixtureErrors(t *testing.T) {
+	var notFound, validation LifxResponse
+
+	decodeFixture(t, "error_not_found.json", &notFound, false)
+	if notFound.Error != "Could not find light with label:Nope" {
+		t.Errorf("got error %q", notFound.Error)
+	}
+
+	decodeFixture(t, "error_validation.json", &validation, false)
+	if len(validation.Errors) != 1 {
+		t.Fatalf("got %d errors, want 1", len(validation.Errors))
+	}
+	if e := validation.Errors[0]; e.Field != "color" || len(e.Message) != 1 || e.Message[0] != "Unable to parse color: blurple" {
+		t.Errorf("got field error %+v", e)
+	}
+}
+
+func TestFixtureAPIErrors(t *testing.T) {
+	tests := []struct {
+		file   string
+		status int
+		want   error
+	}{
+		{"error_not_found.json", http.StatusNotFound, ErrNotFound},
+		{"error_validation.json", http.StatusUnprocessableEntity, ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "fixtures", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			err = (&Response{StatusCode: tt.status, Body: f}).GetLifxError()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+
+			var e *APIError
+			if !errors.As(err, &e) || e.StatusCode != tt.status {
+				t.Fatalf("got %#v, want an APIError", err)
+			}
+			if tt.status == http.StatusUnprocessableEntity && len(e.Field("color")) != 1 {
+				t.Errorf("color field errors missing from %+v", e.Errors)
+			}
+		})
+	}
+}
+
+func TestFixtureWarnings(t *testing.T) {
+	var s LifxResponse
+
+	decodeFixture(t, "results_warnings.json", &s, false)
+
+	if len(s.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(s.Results))
+	}
+	if r := s.Results[1]; r.Id != "d073d5000002" || r.Label != "Porch" || r.Status != Offline {
+		t.Errorf("got result %+v", r)
+	}
+	if len(s.Warnings) != 1 || s.Warnings[0].Warning != "Unknown parameter: 'speed'" {
+		t.Errorf("got warnings %+v", s.Warnings)
+	}
+	if f := s.Failed(); len(f) != 1 || f[0].Id != "d073d5000002" || s.OK() {
+		t.Errorf("got failed results %+v", f)
+	}
+}
+
+func TestFixtureUnknownStatus(t *testing.T) {
+	var s LifxResponse
+
+	decodeFixture(t, "results_status_unknown.json", &s, false)
+
+	if len(s.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(s.Results))
+	}
+	if r := s.Results[0]; !r.Status.IsOK() || !r.Status.IsKnown() {
+		t.Errorf("status %q is not ok", r.Status)
+	}
+	r := s.Results[1]
+	if r.Status != "firmware_updating" || r.Status.IsKnown() || r.Status.IsOK() || r.Status.IsOffline() || r.Status.IsTimedOut() {
+		t.Errorf("got status %q", r.Status)
+	}
+	if f := s.Failed(); len(f) != 1 || f[0].Id != "d073d5000002" {
+		t.Errorf("got failed results %+v", f)
+	}
+}
+
+func TestFixtureStates(t *testing.T) {
+	var s SetStatesResponse
+
+	decodeFixture(t, "results_states.json", &s, false)
+
+	if len(s.Results) != 2 {
+		t.Fatalf("got %d operations, want 2", len(s.Results))
+	}
+
+	op, ok := s.Operation("label:Porch")
+	if !ok {
+		t.Fatal("operation for label:Porch not found")
+	}
+	want := Operation{Selector: "label:Porch", Color: "blue", Brightness: 0.5, Duration: 1}
+	if op.Operation != want {
+		t.Errorf("got operation %+v, want %+v", op.Operation, want)
+	}
+	if len(op.Results) != 1 || op.Results[0].Status != Offline {
+		t.Errorf("got results %+v", op.Results)
+	}
+
+	if all := s.AllResults(); len(all) != 3 || all[1].Status != TimedOut {
+		t.Errorf("got all results %+v", all)
+	}
+}
+
+// TestFixtureRandomHue checks that a random hue survives being written out,
+// such as in a persisted StateStore, and read back.
+func TestFixtureRandomHue(t *testing.T) {
+	var colors []HSBKColor
+
+	decodeFixture(t, "colors_random.json", &colors, false)
+
+	if len(colors) != 3 {
+		t.Fatalf("got %d colors, want 3", len(colors))
+	}
+	if c := colors[0]; !c.RandomHue || c.H != nil || !equalFloat32(c.S, Float32Ptr(1)) || !equalInt16(c.K, Int16Ptr(3500)) {
+		t.Errorf("random hue decoded as %+v", c)
+	}
+	if c := colors[1]; c.RandomHue || !equalFloat32(c.H, Float32Ptr(120)) || c.B != nil {
+		t.Errorf("fixed hue decoded as %+v", c)
+	}
+	if c := colors[2]; c.RandomHue || c.H != nil {
+		t.Errorf("missing hue decoded as %+v", c)
+	}
+
+	b, err := json.Marshal(colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again []HSBKColor
+	if err = json.Unmarshal(b, &again); err != nil {
+		t.Fatalf("decoding marshaled colors %s: %v", b, err)
+	}
+	for i := range colors {
+		c, a := colors[i], again[i]
+		if !equalFloat32(c.H, a.H) || !equalFloat32(c.S, a.S) || !equalFloat32(c.B, a.B) || !equalInt16(c.K, a.K) || c.RandomHue != a.RandomHue {
+			t.Errorf("color %d round-tripped through %s to %+v", i, b, a)
+		}
+	}
+}
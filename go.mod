@@ -1,3 +1,3 @@
 module git.kill0.net/chill9/lifx-go
 
-go 1.16
+go 1.18
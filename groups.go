@@ -0,0 +1,200 @@
+package lifx
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	PowerAllOn  PowerState = "on"
+	PowerMixed  PowerState = "mixed"
+	PowerAllOff PowerState = "off"
+)
+
+type (
+	// Summary describes a group or a location by the lights in it.
+	Summary struct {
+		Selector
+		Lights    int `json:"lights"`
+		On        int `json:"on"`
+		Connected int `json:"connected"`
+	}
+
+	PowerState string
+
+	// GroupState is the state of a group taken as a whole, such as for a
+	// single dashboard tile. Brightness is the mean of the lights that
+	// are on and Color the one most of them show, so both describe what
+	// the group looks like rather than what the off lights would return
+	// to.
+	GroupState struct {
+		Selector
+		Power      PowerState `json:"power"`
+		Brightness float64    `json:"brightness"`
+		Color      HSBKColor  `json:"color"`
+	}
+)
+
+// ListGroups summarizes every group on the account. The API has no
+// endpoint for groups, so they are gathered from the lights.
+func (c *Client) ListGroups() ([]Summary, error) {
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return nil, err
+	}
+	return Groups(lights), nil
+}
+
+// ListLocations summarizes every location on the account.
+func (c *Client) ListLocations() ([]Summary, error) {
+	lights, err := c.ListLights("all")
+	if err != nil {
+		return nil, err
+	}
+	return Locations(lights), nil
+}
+
+// Groups summarizes the groups lights belong to, ordered by name.
+func Groups(lights []Light) []Summary {
+	return summarize(lights, func(l Light) Selector { return l.Group })
+}
+
+// Locations summarizes the locations lights belong to, ordered by name.
+func Locations(lights []Light) []Summary {
+	return summarize(lights, func(l Light) Selector { return l.Location })
+}
+
+func summarize(lights []Light, key func(Light) Selector) []Summary {
+	var (
+		summaries []Summary
+		index     = make(map[string]int)
+	)
+
+	for _, l := range lights {
+		k := key(l)
+		i, ok := index[k.Id]
+		if !ok {
+			i = len(summaries)
+			index[k.Id] = i
+			summaries = append(summaries, Summary{Selector: k})
+		}
+
+		s := &summaries[i]
+		s.Lights++
+		if l.Power == "on" {
+			s.On++
+		}
+		if l.Connected {
+			s.Connected++
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// GroupState fetches the lights in the named group and combines their
+// state.
+func (c *Client) GroupState(name string) (GroupState, error) {
+	lights, err := c.ListLights(ByGroup(name).String())
+	if err != nil {
+		return GroupState{}, err
+	}
+	return NewGroupState(lights), nil
+}
+
+// NewGroupState combines the state of lights, which are expected to share
+// a group. When they are all off, the color is the one most of them would
+// come back on with.
+func NewGroupState(lights []Light) GroupState {
+	var (
+		g  GroupState
+		on []Light
+	)
+
+	if len(lights) == 0 {
+		g.Power = PowerAllOff
+		return g
+	}
+	g.Selector = lights[0].Group
+
+	for _, l := range lights {
+		if l.Power == "on" {
+			on = append(on, l)
+		}
+	}
+
+	switch len(on) {
+	case 0:
+		g.Power = PowerAllOff
+		g.Color = dominantColor(lights)
+		return g
+	case len(lights):
+		g.Power = PowerAllOn
+	default:
+		g.Power = PowerMixed
+	}
+
+	for _, l := range on {
+		g.Brightness += l.Brightness
+	}
+	g.Brightness /= float64(len(on))
+	g.Color = dominantColor(on)
+	return g
+}
+
+// dominantColor picks the color shown by the most lights. Colors count as
+// the same when they are close enough to look alike: whites by their
+// color temperature and other colors by hue and saturation. Ties go to the
+// color seen first.
+func dominantColor(lights []Light) HSBKColor {
+	type bucket struct {
+		color HSBKColor
+		n     int
+	}
+
+	var (
+		buckets []bucket
+		index   = make(map[[3]int]int)
+	)
+
+	for _, l := range lights {
+		k := colorKey(l.Color)
+		i, ok := index[k]
+		if !ok {
+			i = len(buckets)
+			index[k] = i
+			buckets = append(buckets, bucket{color: l.Color})
+		}
+		buckets[i].n++
+	}
+
+	var best bucket
+	for _, b := range buckets {
+		if b.n > best.n {
+			best = b
+		}
+	}
+	return best.color
+}
+
+func colorKey(c HSBKColor) [3]int {
+	var h, s, k float64
+
+	if c.H != nil {
+		h = float64(*c.H)
+	}
+	if c.S != nil {
+		s = float64(*c.S)
+	}
+	if c.K != nil {
+		k = float64(*c.K)
+	}
+
+	if s < 0.1 {
+		return [3]int{-1, 0, int(math.Round(k / 100))}
+	}
+	return [3]int{int(math.Round(h/10)) % 36, int(math.Round(s * 10)), 0}
+}
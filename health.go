@@ -0,0 +1,168 @@
+package lifx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var DefaultHealthInterval = 30 * time.Second
+
+// Targets a HealthMonitor probes.
+const (
+	TargetCloud = "cloud"
+	TargetLAN   = "lan"
+)
+
+type (
+	// Health is what a HealthMonitor last found out about a target. Since
+	// is when it last became healthy or unhealthy.
+	Health struct {
+		Target  string
+		Healthy bool
+		Err     error
+		Checked time.Time
+		Since   time.Time
+	}
+
+	// HealthMonitor probes the API, and optionally the LAN, by listing
+	// lights, so that daemons can tell when the LIFX cloud is down. A
+	// target counts as unhealthy until its first probe succeeds.
+	HealthMonitor struct {
+		cloud    Lister
+		local    Lister
+		selector string
+		interval time.Duration
+		onChange func(Health)
+		mu       sync.Mutex
+		health   map[string]Health
+	}
+)
+
+func NewHealthMonitor(cloud Lister, options ...func(*HealthMonitor)) *HealthMonitor {
+	m := &HealthMonitor{
+		cloud:    cloud,
+		selector: "all",
+		interval: DefaultHealthInterval,
+		health:   make(map[string]Health),
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// WithLANProbe also probes local, such as the LAN client.
+func WithLANProbe(local Lister) func(*HealthMonitor) {
+	return func(m *HealthMonitor) {
+		m.local = local
+	}
+}
+
+// WithProbeSelector probes by listing selector instead of every light,
+// which keeps the probe cheap on large accounts.
+func WithProbeSelector(selector string) func(*HealthMonitor) {
+	return func(m *HealthMonitor) {
+		m.selector = selector
+	}
+}
+
+func WithHealthInterval(interval time.Duration) func(*HealthMonitor) {
+	return func(m *HealthMonitor) {
+		m.interval = interval
+	}
+}
+
+// WithHealthCallback calls fn whenever a target becomes healthy or
+// unhealthy, including when it is first probed.
+func WithHealthCallback(fn func(Health)) func(*HealthMonitor) {
+	return func(m *HealthMonitor) {
+		m.onChange = fn
+	}
+}
+
+// Run probes straight away and then every interval until ctx is done.
+func (m *HealthMonitor) Run(ctx context.Context) error {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+
+	for {
+		m.Check()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Check probes every target once and returns whether all are healthy.
+func (m *HealthMonitor) Check() bool {
+	m.probe(TargetCloud, m.cloud)
+	if m.local != nil {
+		m.probe(TargetLAN, m.local)
+	}
+	return m.Healthy()
+}
+
+// Healthy reports whether every target was healthy when last probed.
+func (m *HealthMonitor) Healthy() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.health[TargetCloud]; !ok {
+		return false
+	}
+	for _, h := range m.health {
+		if !h.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// LastError returns the error from the last failed probe of a target that
+// is still unhealthy, the cloud's first, or nil if there is none.
+func (m *HealthMonitor) LastError() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, target := range []string{TargetCloud, TargetLAN} {
+		if h, ok := m.health[target]; ok && h.Err != nil {
+			return h.Err
+		}
+	}
+	return nil
+}
+
+// Status returns what is known about target, which is false if it has not
+// been probed yet.
+func (m *HealthMonitor) Status(target string) (Health, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.health[target]
+	return h, ok
+}
+
+func (m *HealthMonitor) probe(target string, lister Lister) {
+	_, err := lister.ListLights(m.selector)
+	now := time.Now()
+
+	m.mu.Lock()
+	prev, probed := m.health[target]
+	h := Health{Target: target, Healthy: err == nil, Err: err, Checked: now, Since: prev.Since}
+	changed := !probed || prev.Healthy != h.Healthy
+	if changed {
+		h.Since = now
+	}
+	m.health[target] = h
+	m.mu.Unlock()
+
+	if changed && m.onChange != nil {
+		m.onChange(h)
+	}
+}
@@ -0,0 +1,359 @@
+// Package homekit exposes LIFX lights to Apple's Home app and Siri through
+// the HomeKit Accessory Protocol. It lives in its own module so that the
+// root module keeps no dependencies.
+package homekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"github.com/brutella/hap"
+	"github.com/brutella/hap/accessory"
+	"github.com/brutella/hap/characteristic"
+	"github.com/brutella/hap/service"
+)
+
+type (
+	// Bridge is a HomeKit bridge with a lightbulb accessory for every light
+	// on the account. Accessories are kept in sync with the lights through
+	// a lifx.StateStore, and changes made in the Home app are applied
+	// through the backend. HomeKit only reads a bridge's accessories when it
+	// starts, so the bridge restarts whenever a light is added or removed.
+	Bridge struct {
+		backend  lifx.Backend
+		store    *lifx.StateStore
+		hapStore hap.Store
+		name     string
+		pin      string
+		addr     string
+		interval time.Duration
+		onError  lifx.ErrorHandler
+		lights   map[string]*lightbulb
+	}
+
+	// lightbulb is a light's accessory. Hue and Saturation are nil for
+	// lights without color, and Temperature for lights with a fixed white.
+	lightbulb struct {
+		*accessory.A
+		On          *characteristic.On
+		Brightness  *characteristic.Brightness
+		Hue         *characteristic.Hue
+		Saturation  *characteristic.Saturation
+		Temperature *characteristic.ColorTemperature
+	}
+)
+
+var (
+	DefaultBridgeName = "LIFX"
+	DefaultPin        = "00102003"
+)
+
+// errRestart stops the accessory server so that it can be started again
+// with the current lights.
+var errRestart = errors.New("homekit: lights added or removed")
+
+// NewBridge returns a bridge that keeps its pairings and keys in store,
+// which must survive restarts for the Home app to keep the pairing. Use
+// hap.NewFsStore for a directory on disk.
+func NewBridge(backend lifx.Backend, store hap.Store, options ...func(*Bridge)) *Bridge {
+	b := &Bridge{
+		backend:  backend,
+		hapStore: store,
+		name:     DefaultBridgeName,
+		pin:      DefaultPin,
+		interval: lifx.DefaultWatchInterval,
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	b.store = lifx.NewStateStore(backend, lifx.All().String(), lifx.WithInterval(b.interval))
+	return b
+}
+
+// WithBridgeName sets the name the bridge is shown under when pairing.
+func WithBridgeName(name string) func(*Bridge) {
+	return func(b *Bridge) {
+		b.name = name
+	}
+}
+
+// WithPin sets the eight digit code entered in the Home app to pair.
+func WithPin(pin string) func(*Bridge) {
+	return func(b *Bridge) {
+		b.pin = pin
+	}
+}
+
+// WithAddr sets the address the accessory server listens on. By default
+// it picks a free port on every interface.
+func WithAddr(addr string) func(*Bridge) {
+	return func(b *Bridge) {
+		b.addr = addr
+	}
+}
+
+func WithPollInterval(interval time.Duration) func(*Bridge) {
+	return func(b *Bridge) {
+		b.interval = interval
+	}
+}
+
+// WithErrorHandler passes errors that don't stop the bridge to fn: failed
+// refreshes with the op "refresh", and changes from the Home app that
+// could not be applied with the op "set".
+func WithErrorHandler(fn lifx.ErrorHandler) func(*Bridge) {
+	return func(b *Bridge) {
+		b.onError = fn
+	}
+}
+
+// Run serves the accessories until ctx is done or the accessory server
+// fails.
+func (b *Bridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := b.store.Refresh(); err != nil {
+		return err
+	}
+
+	events, unsubscribe := b.store.Subscribe()
+	defer unsubscribe()
+
+	go b.store.Run(ctx)
+
+	for {
+		if err := b.serve(ctx, events); err != errRestart {
+			return err
+		}
+	}
+}
+
+// serve runs an accessory server for the lights currently in the store,
+// returning errRestart once the set of lights changes.
+func (b *Bridge) serve(ctx context.Context, events <-chan lifx.Event) error {
+	bridge := accessory.NewBridge(accessory.Info{Name: b.name, Manufacturer: "LIFX"})
+	bridge.Id = 1
+
+	b.lights = make(map[string]*lightbulb)
+	var as []*accessory.A
+	for _, l := range b.store.Lights() {
+		lb := b.newLightbulb(l)
+		b.lights[l.Id] = lb
+		as = append(as, lb.A)
+	}
+
+	server, err := hap.NewServer(b.hapStore, bridge.A, as...)
+	if err != nil {
+		return err
+	}
+	server.Pin = b.pin
+	server.Addr = b.addr
+
+	serverCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServe(serverCtx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			return ctx.Err()
+		case err := <-done:
+			return err
+		case e := <-events:
+			switch e.Type {
+			case lifx.LightAdded, lifx.LightRemoved:
+				stop()
+				<-done
+				return errRestart
+			case lifx.LightChanged:
+				if l, ok := b.store.Light(e.Light.Id); ok {
+					if lb := b.lights[l.Id]; lb != nil {
+						lb.update(l)
+					}
+				}
+			case lifx.WatchError:
+				b.reportError("refresh", e.Err)
+			}
+		}
+	}
+}
+
+func (b *Bridge) newLightbulb(l lifx.Light) *lightbulb {
+	name := l.Label
+	if name == "" {
+		name = l.Id
+	}
+	manufacturer := l.Product.Company
+	if manufacturer == "" {
+		manufacturer = "LIFX"
+	}
+
+	lb := &lightbulb{
+		A: accessory.New(accessory.Info{
+			Name:         name,
+			SerialNumber: l.Id,
+			Manufacturer: manufacturer,
+			Model:        l.Product.Name,
+		}, accessory.TypeLightbulb),
+		On:         characteristic.NewOn(),
+		Brightness: characteristic.NewBrightness(),
+	}
+	lb.Id = accessoryId(l.Id)
+
+	s := service.New(service.TypeLightbulb)
+	s.AddC(lb.On.C)
+	s.AddC(lb.Brightness.C)
+
+	capabilities := l.Product.Capabilities
+	if capabilities.HasColor {
+		lb.Hue = characteristic.NewHue()
+		lb.Saturation = characteristic.NewSaturation()
+		s.AddC(lb.Hue.C)
+		s.AddC(lb.Saturation.C)
+	}
+	if capabilities.HasColor || capabilities.HasVariableColorTemp {
+		lb.Temperature = characteristic.NewColorTemperature()
+		if capabilities.MinKelvin > 0 && capabilities.MaxKelvin > 0 {
+			lb.Temperature.SetMinValue(mireds(int16(capabilities.MaxKelvin)))
+			lb.Temperature.SetMaxValue(mireds(int16(capabilities.MinKelvin)))
+		}
+		s.AddC(lb.Temperature.C)
+	}
+	lb.AddS(s)
+
+	lb.update(l)
+	b.handle(l, lb)
+	return lb
+}
+
+// update shows l's state in the Home app.
+func (lb *lightbulb) update(l lifx.Light) {
+	lb.On.SetValue(l.Power == "on")
+	lb.Brightness.SetValue(int(math.Round(l.Brightness * 100)))
+
+	c := l.Color
+	if lb.Hue != nil && c.H != nil {
+		lb.Hue.SetValue(float64(*c.H))
+	}
+	if lb.Saturation != nil && c.S != nil {
+		lb.Saturation.SetValue(float64(*c.S) * 100)
+	}
+	if lb.Temperature != nil && c.K != nil && *c.K > 0 {
+		lb.Temperature.SetValue(mireds(*c.K))
+	}
+}
+
+// handle applies the changes the Home app makes to lb's characteristics.
+// The Home app sets each characteristic on its own, so each one becomes a
+// SetState of its own.
+func (b *Bridge) handle(l lifx.Light, lb *lightbulb) {
+	id := l.Id
+	capabilities := l.Product.Capabilities
+
+	lb.On.OnSetRemoteValue(func(on bool) error {
+		power := "off"
+		if on {
+			power = "on"
+		}
+		return b.set(id, lifx.State{Power: power})
+	})
+	lb.Brightness.OnSetRemoteValue(func(v int) error {
+		if v <= 0 {
+			return b.set(id, lifx.State{Power: "off"})
+		}
+		return b.set(id, lifx.State{Brightness: math.Min(float64(v)/100, 1)})
+	})
+	if lb.Hue != nil {
+		lb.Hue.OnSetRemoteValue(func(v float64) error {
+			return b.set(id, lifx.State{Color: lifx.HSBKColor{H: lifx.Float32Ptr(float32(v))}})
+		})
+		lb.Saturation.OnSetRemoteValue(func(v float64) error {
+			return b.set(id, lifx.State{Color: lifx.HSBKColor{S: lifx.Float32Ptr(float32(v / 100))}})
+		})
+	}
+	if lb.Temperature != nil {
+		lb.Temperature.OnSetRemoteValue(func(v int) error {
+			k := capabilities.ClampKelvin(int16(math.Round(1e6 / float64(v))))
+			return b.set(id, lifx.State{Color: lifx.HSBKColor{K: lifx.Int16Ptr(k), S: lifx.Float32Ptr(0)}})
+		})
+	}
+}
+
+func (b *Bridge) reportError(op string, err error) {
+	if b.onError != nil {
+		b.onError(op, err)
+	}
+}
+
+// set applies st to the light and records it in the store straight away,
+// so that the next poll isn't needed to keep the Home app's controls
+// where they were left.
+func (b *Bridge) set(id string, st lifx.State) error {
+	r, err := b.backend.SetState(lifx.ById(id).String(), st)
+	if err != nil {
+		b.reportError("set", fmt.Errorf("setting %s: %w", id, err))
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+
+	color, _ := st.Color.(lifx.HSBKColor)
+	for _, res := range r.Results {
+		if !res.Status.IsOK() {
+			continue
+		}
+		b.store.Update(res.Id, func(l *lifx.Light) {
+			if st.Power != "" {
+				l.Power = st.Power
+			}
+			if st.Brightness > 0 {
+				l.Brightness = st.Brightness
+				l.Color.B = lifx.Float32Ptr(float32(st.Brightness))
+			}
+			if color.H != nil {
+				l.Color.H = color.H
+			}
+			if color.S != nil {
+				l.Color.S = color.S
+			}
+			if color.K != nil {
+				l.Color.K = color.K
+			}
+		})
+	}
+	return nil
+}
+
+// accessoryId keeps a light's accessory id the same across restarts, so
+// that HomeKit keeps its room and automations. Light ids are the bulb's
+// MAC address in hex.
+func accessoryId(id string) uint64 {
+	if n, err := strconv.ParseUint(id, 16, 64); err == nil && n > 1 {
+		return n
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return h.Sum64() | 2
+}
+
+// mireds converts a color temperature in kelvin to the mireds HomeKit
+// uses, within the range HomeKit accepts.
+func mireds(k int16) int {
+	m := int(math.Round(1e6 / float64(k)))
+	return int(math.Max(140, math.Min(500, float64(m))))
+}
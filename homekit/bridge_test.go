@@ -0,0 +1,96 @@
+package homekit
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+	"github.com/brutella/hap"
+)
+
+func TestLightbulb(t *testing.T) {
+	l := lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home")
+	srv := lifxtest.NewServer(l)
+	defer srv.Close()
+
+	b := NewBridge(lifx.NewClient("x", lifxtest.WithServer(srv)), hap.NewMemStore())
+	if err := b.store.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	lb := b.newLightbulb(l)
+	if lb.Id != 0xd073d5000001 {
+		t.Errorf("accessory id = %x, want the light's MAC address", lb.Id)
+	}
+	if lb.On.Value() || lb.Brightness.Value() != 100 || lb.Temperature.Value() != 286 {
+		t.Errorf("on, brightness, mireds = %v, %v, %v, want false, 100, 286", lb.On.Value(), lb.Brightness.Value(), lb.Temperature.Value())
+	}
+
+	// A request marks a change as coming from the Home app.
+	req := &http.Request{RemoteAddr: "controller"}
+	if _, code := lb.On.SetValueRequest(true, req); code != 0 {
+		t.Fatalf("setting on: code %d", code)
+	}
+	if _, code := lb.Brightness.SetValueRequest(40, req); code != 0 {
+		t.Fatalf("setting brightness: code %d", code)
+	}
+	if _, code := lb.Temperature.SetValueRequest(400, req); code != 0 {
+		t.Fatalf("setting color temperature: code %d", code)
+	}
+
+	got, _ := srv.Light(l.Id)
+	if got.Power != "on" || got.Brightness != 0.4 || got.Color.K == nil || *got.Color.K != 2500 {
+		t.Errorf("light after Home app changes = %s, %v, %v, want on, 0.4, 2500", got.Power, got.Brightness, got.Color.K)
+	}
+	if stored, _ := b.store.Light(l.Id); stored.Power != "on" || stored.Brightness != 0.4 {
+		t.Errorf("store after Home app changes = %s, %v, want on, 0.4", stored.Power, stored.Brightness)
+	}
+
+	got.Power = "off"
+	lb.update(got)
+	if lb.On.Value() {
+		t.Error("update left the accessory on")
+	}
+}
+
+func TestMireds(t *testing.T) {
+	tests := []struct {
+		kelvin int16
+		want   int
+	}{
+		{2500, 400},
+		{1500, 500},
+		{9000, 140},
+	}
+
+	for _, tt := range tests {
+		if got := mireds(tt.kelvin); got != tt.want {
+			t.Errorf("mireds(%d) = %d, want %d", tt.kelvin, got, tt.want)
+		}
+	}
+}
+
+func TestBridgeErrorHandler(t *testing.T) {
+	l := lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home")
+	f := lifxtest.NewFakeClient(l)
+	failed := errors.New("no route to bulb")
+	f.FailWith("SetState", failed)
+
+	var ops []string
+	b := NewBridge(f, hap.NewMemStore(), WithErrorHandler(func(op string, err error) {
+		ops = append(ops, op)
+		if !errors.Is(err, failed) {
+			t.Errorf("%s reported %v, want %v", op, err, failed)
+		}
+	}))
+
+	lb := b.newLightbulb(l)
+	if _, code := lb.On.SetValueRequest(true, &http.Request{RemoteAddr: "controller"}); code == 0 {
+		t.Error("setting on succeeded with a failing backend")
+	}
+	if len(ops) != 1 || ops[0] != "set" {
+		t.Errorf("reported ops %v, want [set]", ops)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/config"
+	"git.kill0.net/chill9/lifx-go/homekit"
+	"github.com/brutella/hap"
+)
+
+func main() {
+	var (
+		path  = flag.String("config", "", "client configuration file, defaults to $LIFX_CONFIG")
+		dir   = flag.String("store", defaultStore(), "directory keeping the bridge's pairings and keys")
+		name  = flag.String("name", homekit.DefaultBridgeName, "name the bridge is shown under in the Home app")
+		pin   = flag.String("pin", homekit.DefaultPin, "eight digit code to pair with")
+		addr  = flag.String("listen", "", "address to serve accessories on, empty for any free port")
+		every = flag.Duration("interval", lifx.DefaultWatchInterval, "how often to poll the lights")
+	)
+	flag.Parse()
+
+	conf, err := config.Load(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := conf.NewClient(lifx.WithUserAgent("lifx-homekit/" + lifx.Version))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b := homekit.NewBridge(c, hap.NewFsStore(*dir),
+		homekit.WithBridgeName(*name),
+		homekit.WithPin(*pin),
+		homekit.WithAddr(*addr),
+		homekit.WithPollInterval(*every),
+		homekit.WithErrorHandler(func(op string, err error) {
+			log.Printf("%s: %s", op, err)
+		}),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("bridging lights to HomeKit, pair with code %s", *pin)
+	if err := b.Run(ctx); err != nil && err != context.Canceled {
+		log.Fatal(err)
+	}
+}
+
+func defaultStore() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "lifx-homekit"
+	}
+	return filepath.Join(dir, "lifx-homekit")
+}
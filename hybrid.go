@@ -0,0 +1,176 @@
+package lifx
+
+import (
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultHybridRefresh = time.Minute
+
+type (
+	// Locator is implemented by local backends that can report the
+	// hardware addresses of the devices they are able to reach.
+	Locator interface {
+		HardwareAddrs() []net.HardwareAddr
+	}
+
+	Hybrid struct {
+		cloud   *Client
+		local   Backend
+		refresh time.Duration
+		mu      sync.Mutex
+		ids     map[string]hybridIds
+	}
+
+	hybridIds struct {
+		ids     []string
+		fetched time.Time
+	}
+)
+
+var _ Backend = (*Hybrid)(nil)
+
+// LightID returns the cloud light ID of the device with the given hardware
+// address. The API uses the MAC address in lowercase hex without
+// separators.
+func LightID(mac net.HardwareAddr) string {
+	return hex.EncodeToString(mac)
+}
+
+// NewHybrid returns a Backend that sends state changes over local whenever
+// every light the selector resolves to is reachable there, and everything
+// else, including selector resolution, over cloud.
+func NewHybrid(cloud *Client, local Backend) *Hybrid {
+	return &Hybrid{
+		cloud:   cloud,
+		local:   local,
+		refresh: DefaultHybridRefresh,
+		ids:     make(map[string]hybridIds),
+	}
+}
+
+// localSelector translates selector into an id selector the local backend
+// understands, or returns false when any of the lights is not known
+// locally.
+func (h *Hybrid) localSelector(selector string) (string, bool) {
+	locator, ok := h.local.(Locator)
+	if !ok {
+		return "", false
+	}
+
+	ids, err := h.lightIds(selector)
+	if err != nil || len(ids) == 0 {
+		return "", false
+	}
+
+	known := make(map[string]bool)
+	for _, mac := range locator.HardwareAddrs() {
+		known[LightID(mac)] = true
+	}
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		if !known[strings.ToLower(id)] {
+			return "", false
+		}
+		parts[i] = "id:" + id
+	}
+
+	return strings.Join(parts, ","), true
+}
+
+// lightIds resolves selector through the cloud, reusing the ids for the
+// refresh interval on the cloud client's clock. A selector with a random
+// part is resolved every time, so that each call can pick another light.
+func (h *Hybrid) lightIds(selector string) ([]string, error) {
+	now := h.cloud.now()
+	cacheable := !randomSelector(selector)
+
+	if cacheable {
+		h.mu.Lock()
+		cached, ok := h.ids[selector]
+		h.mu.Unlock()
+
+		if ok && now.Sub(cached.fetched) < h.refresh {
+			return cached.ids, nil
+		}
+	}
+
+	lights, err := h.cloud.ListLights(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(lights))
+	for i, l := range lights {
+		ids[i] = l.Id
+	}
+
+	if cacheable {
+		h.mu.Lock()
+		h.ids[selector] = hybridIds{ids: ids, fetched: now}
+		h.mu.Unlock()
+	}
+
+	return ids, nil
+}
+
+func randomSelector(selector string) bool {
+	parts, err := ParseSelector(selector)
+	if err != nil {
+		return false
+	}
+	for _, p := range parts {
+		if p.Random {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Hybrid) ListLights(selector string) ([]Light, error) {
+	return h.cloud.ListLights(selector)
+}
+
+func (h *Hybrid) SetState(selector string, state State) (*LifxResponse, error) {
+	if local, ok := h.localSelector(selector); ok {
+		if s, err := h.local.SetState(local, state); err == nil {
+			return s, nil
+		}
+	}
+	return h.cloud.SetState(selector, state)
+}
+
+// Toggle falls back to the cloud the way Router.Toggle does, setting the
+// power each light should end up with instead of toggling it again.
+func (h *Hybrid) Toggle(selector string, duration float64) (*LifxResponse, error) {
+	local, ok := h.localSelector(selector)
+	if !ok {
+		return h.cloud.Toggle(selector, duration)
+	}
+
+	lights, err := h.local.ListLights(local)
+	if err != nil || len(lights) == 0 {
+		return h.cloud.Toggle(selector, duration)
+	}
+
+	if s, err := h.local.Toggle(local, duration); err == nil {
+		return s, nil
+	}
+	return setToggled(h.cloud, lights, duration)
+}
+
+func (h *Hybrid) PowerOn(selector string) (*LifxResponse, error) {
+	return h.SetState(selector, State{Power: "on"})
+}
+
+func (h *Hybrid) PowerOff(selector string) (*LifxResponse, error) {
+	return h.SetState(selector, State{Power: "off"})
+}
+
+func (h *Hybrid) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
+	return h.cloud.Breathe(selector, breathe)
+}
@@ -0,0 +1,126 @@
+// Package miniyaml reads the small subset of YAML used by configuration
+// files, which saves depending on a full YAML implementation.
+package miniyaml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parse reads nested mappings of scalars, comments, and single or double
+// quoted strings. Scalars are returned as strings and mappings as
+// map[string]interface{}.
+func Parse(r io.Reader) (map[string]interface{}, error) {
+	type level struct {
+		indent int
+		m      map[string]interface{}
+	}
+
+	root := map[string]interface{}{}
+	stack := []level{{indent: 0, m: root}}
+
+	var pending string // key waiting for a nested mapping
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		content := strings.TrimSpace(stripComment(line))
+		if content == "" || content == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", n)
+		}
+
+		if pending != "" {
+			parent := stack[len(stack)-1]
+			if indent <= parent.indent {
+				parent.m[pending] = ""
+			} else {
+				m := map[string]interface{}{}
+				parent.m[pending] = m
+				stack = append(stack, level{indent: indent, m: m})
+			}
+			pending = ""
+		}
+
+		for indent < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		if indent != stack[len(stack)-1].indent {
+			return nil, fmt.Errorf("line %d: inconsistent indentation", n)
+		}
+
+		if strings.HasPrefix(content, "- ") || content == "-" {
+			return nil, fmt.Errorf("line %d: lists are not supported", n)
+		}
+
+		i := strings.Index(content, ":")
+		if i <= 0 || (i+1 < len(content) && content[i+1] != ' ') {
+			return nil, fmt.Errorf("line %d: expected key: value", n)
+		}
+		key, value := strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:])
+
+		m := stack[len(stack)-1].m
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", n, key)
+		}
+
+		if value == "" {
+			pending = key
+			continue
+		}
+
+		s, err := unquote(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		m[key] = s
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if pending != "" {
+		stack[len(stack)-1].m[pending] = ""
+	}
+	return root, nil
+}
+
+// stripComment removes a trailing comment, leaving '#' inside quotes alone.
+func stripComment(line string) string {
+	var quote byte
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquote(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
@@ -0,0 +1,348 @@
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+var (
+	_ lifx.Backend = (*Client)(nil)
+	_ lifx.Reacher = (*Client)(nil)
+	_ lifx.Locator = (*Client)(nil)
+)
+
+var (
+	ErrUnsupportedColor = errors.New("only HSBK and RGB colors can be sent over the LAN")
+	ErrInfrared         = errors.New("infrared is not supported over the LAN")
+)
+
+func (c *Client) HardwareAddrs() []net.HardwareAddr {
+	devices := c.Devices()
+
+	addrs := make([]net.HardwareAddr, len(devices))
+	for i, d := range devices {
+		addrs[i] = d.MAC
+	}
+	return addrs
+}
+
+// metadata returns the label, group and location of d, asking the device
+// only the first time since they rarely change.
+func (c *Client) metadata(d Device) (lifx.Light, error) {
+	c.mu.RLock()
+	light, ok := c.lights[d.Id()]
+	c.mu.RUnlock()
+
+	if ok {
+		return light, nil
+	}
+
+	return c.refresh(d)
+}
+
+func (c *Client) refresh(d Device) (lifx.Light, error) {
+	light, err := c.GetLight(d)
+	if err != nil {
+		return light, err
+	}
+
+	c.mu.Lock()
+	c.lights[d.Id()] = light
+	c.mu.Unlock()
+
+	return light, nil
+}
+
+// resolve returns the discovered devices selector picks out, with each
+// ":random" part picking one of the devices it matches. Zones and scenes
+// can't be selected over the LAN.
+func (c *Client) resolve(selector string) ([]Device, error) {
+	var (
+		devices []Device
+		seen    = make(map[string]bool)
+	)
+
+	parts, err := lifx.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorted so that a seeded source picks the same random device.
+	discovered := c.Devices()
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].Id() < discovered[j].Id() })
+
+	for _, p := range parts {
+		var candidates []Device
+
+		switch p.Kind {
+		case "scene_id":
+			return nil, fmt.Errorf("%s: scenes can't be selected over the LAN", p)
+		case "id":
+			if strings.ContainsRune(p.Value, '|') {
+				return nil, fmt.Errorf("%s: zones can't be selected over the LAN", p)
+			}
+			c.mu.RLock()
+			d, ok := c.devices[strings.ToLower(p.Value)]
+			c.mu.RUnlock()
+			if !ok {
+				return nil, fmt.Errorf("light %s has not been discovered", p.Value)
+			}
+			candidates = append(candidates, d)
+		default:
+			for _, d := range discovered {
+				if p.Kind != "all" {
+					light, err := c.metadata(d)
+					if err != nil || !p.Match(light) {
+						continue
+					}
+				}
+				candidates = append(candidates, d)
+			}
+		}
+
+		if p.Random && len(candidates) > 0 {
+			c.randMu.Lock()
+			candidates = candidates[c.rand.Intn(len(candidates)):][:1]
+			c.randMu.Unlock()
+		}
+		for _, d := range candidates {
+			if !seen[d.Id()] {
+				seen[d.Id()] = true
+				devices = append(devices, d)
+			}
+		}
+	}
+
+	return devices, nil
+}
+
+func (c *Client) Reachable(selector string) bool {
+	devices, err := c.resolve(selector)
+	return err == nil && len(devices) > 0
+}
+
+func (c *Client) ListLights(selector string) ([]lifx.Light, error) {
+	var lights []lifx.Light
+
+	devices, err := c.resolve(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range devices {
+		light, err := c.refresh(d)
+		if err != nil {
+			if !errors.Is(err, ErrTimeout) {
+				return nil, err
+			}
+			light, _ = c.metadata(d)
+			light.Id = d.Id()
+			light.Connected = false
+		}
+		lights = append(lights, light)
+	}
+
+	return lights, nil
+}
+
+func (c *Client) SetState(selector string, state lifx.State) (*lifx.LifxResponse, error) {
+	if state.Infrared != 0 {
+		return nil, ErrInfrared
+	}
+
+	return c.each(selector, func(d Device) error {
+		return c.setState(d, state)
+	})
+}
+
+func (c *Client) setState(d Device, state lifx.State) error {
+	duration := seconds(state.Duration)
+
+	if state.Power == "on" {
+		if err := c.SetPower(d, true, duration); err != nil {
+			return err
+		}
+	}
+
+	if state.Color != nil || state.Brightness != 0 {
+		color, err := c.hsbkColor(state.Color)
+		if err != nil {
+			return err
+		}
+		if state.Brightness != 0 {
+			color.B = lifx.Float32Ptr(float32(state.Brightness))
+		}
+
+		// SetColor replaces every component, so anything the caller left
+		// out is carried over from the device's current color.
+		if color.H == nil || color.S == nil || color.B == nil || color.K == nil {
+			current, err := c.GetColor(d)
+			if err != nil {
+				return err
+			}
+			color = mergeColor(color, current.Color)
+		}
+
+		if err = c.SetColor(d, color, duration); err != nil {
+			return err
+		}
+	}
+
+	if state.Power == "off" {
+		return c.SetPower(d, false, duration)
+	}
+
+	return nil
+}
+
+func (c *Client) Toggle(selector string, duration float64) (*lifx.LifxResponse, error) {
+	powers := make(map[string]string)
+
+	s, err := c.each(selector, func(d Device) error {
+		power, err := c.GetPower(d)
+		if err != nil {
+			return err
+		}
+		if err = c.SetPower(d, power == "off", seconds(duration)); err != nil {
+			return err
+		}
+
+		powers[d.Id()] = "on"
+		if power == "on" {
+			powers[d.Id()] = "off"
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range s.Results {
+		s.Results[i].Power = powers[s.Results[i].Id]
+	}
+	return s, nil
+}
+
+func (c *Client) PowerOn(selector string) (*lifx.LifxResponse, error) {
+	return c.SetState(selector, lifx.State{Power: "on"})
+}
+
+func (c *Client) PowerOff(selector string) (*lifx.LifxResponse, error) {
+	return c.SetState(selector, lifx.State{Power: "off"})
+}
+
+func (c *Client) Breathe(selector string, breathe lifx.Breathe) (*lifx.LifxResponse, error) {
+	if err := breathe.Valid(); err != nil {
+		return nil, err
+	}
+
+	color, err := c.hsbkColor(breathe.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	w := NewWaveform(WaveSine, color)
+	w.Transient = !breathe.Persist
+	w.Period = seconds(breathe.Period)
+	w.Cycles = float32(breathe.Cycles)
+	w.SkewRatio = float32(breathe.Peak)
+
+	return c.each(selector, func(d Device) error {
+		if !lifx.StartsFromCurrent(breathe.FromColor) {
+			from, err := c.hsbkColor(breathe.FromColor)
+			if err != nil {
+				return err
+			}
+			if err = c.SetColor(d, from, 0); err != nil {
+				return err
+			}
+		}
+		if breathe.PowerOn {
+			if err := c.SetPower(d, true, 0); err != nil {
+				return err
+			}
+		}
+		return c.SetWaveformOptional(d, w)
+	})
+}
+
+// each runs fn against every device matching selector and reports the
+// outcome per light the same way the HTTP API does.
+func (c *Client) each(selector string, fn func(Device) error) (*lifx.LifxResponse, error) {
+	var s lifx.LifxResponse
+
+	devices, err := c.resolve(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range devices {
+		r := lifx.Result{Id: d.Id(), Status: lifx.OK}
+		if light, err := c.metadata(d); err == nil {
+			r.Label = light.Label
+		}
+
+		if err := fn(d); err != nil {
+			if !errors.Is(err, ErrTimeout) {
+				return nil, err
+			}
+			r.Status = lifx.TimedOut
+		}
+
+		s.Results = append(s.Results, r)
+	}
+
+	return &s, nil
+}
+
+func (c *Client) hsbkColor(color lifx.Color) (lifx.HSBKColor, error) {
+	var hsbk lifx.HSBKColor
+
+	switch v := color.(type) {
+	case nil:
+		return hsbk, nil
+	case lifx.HSBKColor:
+		hsbk = v
+	case *lifx.HSBKColor:
+		hsbk = *v
+	case lifx.RGBColor:
+		return v.HSBKColor(), nil
+	default:
+		return hsbk, ErrUnsupportedColor
+	}
+
+	// Devices can't pick a random hue themselves, so one is picked here.
+	if hsbk.RandomHue {
+		c.randMu.Lock()
+		hue := float32(c.rand.Float64() * 360)
+		c.randMu.Unlock()
+		hsbk.H, hsbk.RandomHue = &hue, false
+	}
+	return hsbk, nil
+}
+
+func mergeColor(c, current lifx.HSBKColor) lifx.HSBKColor {
+	if c.H == nil {
+		c.H = current.H
+	}
+	if c.S == nil {
+		c.S = current.S
+	}
+	if c.B == nil {
+		c.B = current.B
+	}
+	if c.K == nil {
+		c.K = current.K
+	}
+	return c
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,80 @@
+package lan
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"net"
+	"strings"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+// newResolveClient returns a client that has discovered lights without
+// touching the network, with their metadata already cached.
+func newResolveClient(lights ...lifx.Light) *Client {
+	c := &Client{
+		devices: make(map[string]Device),
+		lights:  make(map[string]lifx.Light),
+		rand:    rand.New(rand.NewSource(1)),
+	}
+	for _, l := range lights {
+		mac, _ := hex.DecodeString(l.Id)
+		d := Device{MAC: net.HardwareAddr(mac)}
+		c.devices[d.Id()] = d
+		c.lights[d.Id()] = l
+	}
+	return c
+}
+
+func TestResolve(t *testing.T) {
+	light := func(id, label, group string) lifx.Light {
+		return lifx.Light{Id: id, Label: label, Group: lifx.Selector{Name: group}}
+	}
+	c := newResolveClient(
+		light("d073d5000001", "Desk", "Office"),
+		light("d073d5000002", "Shelf", "Office"),
+		light("d073d5000003", "Kettle", "Kitchen"),
+		light("d073d5000004", "Hob", "Kitchen"),
+	)
+
+	for _, tt := range []struct {
+		selector string
+		n        int
+		group    string
+		err      string
+	}{
+		{"all", 4, "", ""},
+		{"group:Office", 2, "Office", ""},
+		{"label:Desk,group:Office", 2, "Office", ""},
+		{"id:D073D5000003", 1, "Kitchen", ""},
+		{"all:random", 1, "", ""},
+		{"group:Kitchen:random", 1, "Kitchen", ""},
+		{"label:Nowhere", 0, "", ""},
+		{"id:d073d5000009", 0, "", "has not been discovered"},
+		{"id:d073d5000001|0-3", 0, "", "zones"},
+		{"scene_id:abc", 0, "", "scenes"},
+		{"colour:red", 0, "", "not a valid selector"},
+	} {
+		t.Run(tt.selector, func(t *testing.T) {
+			devices, err := c.resolve(tt.selector)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want one mentioning %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(devices) != tt.n {
+				t.Fatalf("resolved %d devices, want %d", len(devices), tt.n)
+			}
+			for _, d := range devices {
+				if l := c.lights[d.Id()]; tt.group != "" && l.Group.Name != tt.group {
+					t.Errorf("resolved %s in %s, want a light in %s", l.Label, l.Group.Name, tt.group)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package lan
+
+import (
+	"net"
+	"time"
+)
+
+const ServiceUDP Service = 1
+
+const discoveryQueue = 256
+
+type (
+	Service uint8
+
+	Device struct {
+		MAC      net.HardwareAddr
+		IP       net.IP
+		Zone     string
+		Port     int
+		Service  Service
+		LastSeen time.Time
+	}
+)
+
+func (d Device) Addr() *net.UDPAddr {
+	return &net.UDPAddr{IP: d.IP, Port: d.Port, Zone: d.Zone}
+}
+
+func (d Device) target() (t [8]byte) {
+	copy(t[:], d.MAC)
+	return
+}
+
+func (c *Client) Discover() ([]Device, error) {
+	var (
+		devices []Device
+		seen    = make(map[string]bool)
+	)
+
+	// Every device on the network may answer at once, so the reply queue
+	// is sized for a burst rather than a single response.
+	seq, ch := c.dispatch.register(discoveryQueue)
+	defer c.dispatch.unregister(seq)
+
+	h := c.header([8]byte{}, seq, msgGetService, resRequired)
+	for _, addr := range c.broadcast {
+		if err := c.write(addr, h, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case p, ok := <-ch:
+			if !ok {
+				return devices, ErrClosed
+			}
+			if p.header.Type != msgStateService {
+				continue
+			}
+
+			var s stateService
+			if err := decodePayload(p.payload, &s); err != nil || s.Service != ServiceUDP {
+				continue
+			}
+
+			mac := net.HardwareAddr(append([]byte(nil), p.header.Target[:6]...))
+			if seen[mac.String()] {
+				continue
+			}
+			seen[mac.String()] = true
+
+			devices = append(devices, Device{
+				MAC:      mac,
+				IP:       p.addr.IP,
+				Zone:     p.addr.Zone,
+				Port:     int(s.Port),
+				Service:  s.Service,
+				LastSeen: time.Now(),
+			})
+		case <-timer.C:
+			c.remember(devices)
+			return devices, nil
+		}
+	}
+}
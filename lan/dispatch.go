@@ -0,0 +1,94 @@
+package lan
+
+import (
+	"net"
+	"sync"
+)
+
+const pendingQueue = 16
+
+type (
+	packet struct {
+		header  header
+		payload []byte
+		addr    *net.UDPAddr
+	}
+
+	dispatcher struct {
+		mu       sync.Mutex
+		sequence uint8
+		pending  map[uint8]chan packet
+		closed   bool
+	}
+)
+
+func newDispatcher() *dispatcher {
+	return &dispatcher{pending: make(map[uint8]chan packet)}
+}
+
+func (d *dispatcher) next() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sequence++
+	return d.sequence
+}
+
+// register reserves a sequence number that no outstanding request is using
+// and returns the channel its replies are delivered on. Once all 256
+// sequence numbers are in flight the oldest mapping is reused.
+func (d *dispatcher) register(size int) (uint8, <-chan packet) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch := make(chan packet, size)
+	if d.closed {
+		close(ch)
+		return 0, ch
+	}
+
+	for i := 0; i < 256; i++ {
+		d.sequence++
+		if _, ok := d.pending[d.sequence]; !ok {
+			break
+		}
+	}
+	d.pending[d.sequence] = ch
+
+	return d.sequence, ch
+}
+
+func (d *dispatcher) unregister(seq uint8) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.pending, seq)
+}
+
+func (d *dispatcher) deliver(p packet) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch, ok := d.pending[p.header.Sequence]
+	if !ok {
+		return
+	}
+
+	// Never block the read loop on a slow consumer; a dropped reply is
+	// indistinguishable from one lost on the network.
+	select {
+	case ch <- p:
+	default:
+	}
+}
+
+func (d *dispatcher) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	for seq, ch := range d.pending {
+		close(ch)
+		delete(d.pending, seq)
+	}
+}
@@ -0,0 +1,221 @@
+package lan
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	// Store holds the state of emulated lights. lifxtest.Server implements
+	// it, so one set of bulbs can be served over HTTP and the LAN at once.
+	Store interface {
+		Light(id string) (lifx.Light, bool)
+		UpdateLight(id string, fn func(*lifx.Light)) bool
+	}
+
+	// Emulator answers LAN protocol messages on behalf of lights in a
+	// Store. Every light shares the emulator's socket and is told apart by
+	// the target address in the header, as if each were a separate bulb.
+	//
+	// Latency delays every reply and DropRate is the probability that a
+	// message is ignored altogether, which makes the emulator useful for
+	// exercising retransmission. Lights that are not connected never reply.
+	Emulator struct {
+		Latency  time.Duration
+		DropRate float64
+
+		store   Store
+		ids     []string
+		started time.Time
+		rand    *rand.Rand
+	}
+)
+
+func NewEmulator(store Store, ids ...string) *Emulator {
+	return &Emulator{
+		store:   store,
+		ids:     ids,
+		started: time.Now(),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetRandSource decides which messages DropRate drops from src, so that
+// a seeded source drops the same ones every run. It must be called before
+// Serve.
+func (e *Emulator) SetRandSource(src rand.Source) {
+	e.rand = rand.New(src)
+}
+
+func (e *Emulator) Serve(conn net.PacketConn) error {
+	buf := make([]byte, 1500)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		h, p, err := decode(buf[:n])
+		if err != nil {
+			continue
+		}
+		if e.DropRate > 0 && e.rand.Float64() < e.DropRate {
+			continue
+		}
+		if e.Latency > 0 {
+			time.Sleep(e.Latency)
+		}
+
+		for _, id := range e.ids {
+			target, err := hex.DecodeString(id)
+			if err != nil || len(target) != 6 {
+				continue
+			}
+			if !h.Tagged() && string(h.Target[:6]) != string(target) {
+				continue
+			}
+			e.handle(conn, addr, h, p, id, target)
+		}
+	}
+}
+
+func (e *Emulator) handle(conn net.PacketConn, addr net.Addr, h header, p []byte, id string, target []byte) {
+	reply := func(typ uint16, payload interface{}) {
+		r := header{Source: h.Source, Sequence: h.Sequence, Type: typ}
+		copy(r.Target[:], target)
+		if b, err := encode(r, payload); err == nil {
+			conn.WriteTo(b, addr)
+		}
+	}
+
+	light, ok := e.store.Light(id)
+	if !ok || !light.Connected {
+		return
+	}
+
+	if h.Flags&ackRequired != 0 {
+		reply(msgAcknowledgement, nil)
+	}
+
+	switch h.Type {
+	case msgGetService:
+		port := 0
+		if a, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+			port = a.Port
+		}
+		reply(msgStateService, &stateService{Service: ServiceUDP, Port: uint32(port)})
+	case msgGetHostFirmware:
+		reply(msgStateHostFirmware, &stateHostFirmware{
+			Build:        uint64(e.started.UnixNano()),
+			VersionMajor: 3,
+			VersionMinor: 70,
+		})
+	case msgGetWifiInfo:
+		reply(msgStateWifiInfo, &stateWifiInfo{Signal: 1e-5})
+	case msgGetVersion:
+		reply(msgStateVersion, &stateVersion{
+			Vendor:  uint32(light.Product.VendorId),
+			Product: uint32(light.Product.ProductId),
+		})
+	case msgGetInfo:
+		now := time.Now()
+		reply(msgStateInfo, &stateInfo{
+			Time:   uint64(now.UnixNano()),
+			Uptime: uint64(now.Sub(e.started)),
+		})
+	case msgGetPower:
+		reply(msgStatePower, &statePower{Level: powerLevel(light.Power)})
+	case msgGetLightPower:
+		reply(msgStateLightPower, &statePower{Level: powerLevel(light.Power)})
+	case msgSetLightPower:
+		var s setLightPower
+		if decodePayload(p, &s) != nil {
+			return
+		}
+		e.store.UpdateLight(id, func(l *lifx.Light) { l.Power = powerString(s.Level) })
+		if h.Flags&resRequired != 0 {
+			reply(msgStateLightPower, &statePower{Level: s.Level})
+		}
+	case msgGetLabel:
+		reply(msgStateLabel, &stateLabel{Label: labelBytes(light.Label)})
+	case msgGetGroup:
+		reply(msgStateGroup, &stateGroup{
+			Group: selectorBytes(light.Group.Id),
+			Label: labelBytes(light.Group.Name),
+		})
+	case msgGetLocation:
+		reply(msgStateLocation, &stateLocation{
+			Location: selectorBytes(light.Location.Id),
+			Label:    labelBytes(light.Location.Name),
+		})
+	case msgGetColor:
+		reply(msgLightState, newLightState(light))
+	case msgSetColor:
+		var s setColor
+		if decodePayload(p, &s) != nil {
+			return
+		}
+		e.store.UpdateLight(id, func(l *lifx.Light) { setLightColor(l, s.Color.HSBKColor()) })
+		if h.Flags&resRequired != 0 {
+			light, _ = e.store.Light(id)
+			reply(msgLightState, newLightState(light))
+		}
+	case msgSetWaveform, msgSetWaveformOptional:
+		var s setWaveform
+		if decodePayload(p, &s) != nil {
+			return
+		}
+		// Transient waveforms return to the original color, so only a
+		// persistent one leaves a lasting change to emulate.
+		if s.Transient == 0 {
+			e.store.UpdateLight(id, func(l *lifx.Light) { setLightColor(l, s.Color.HSBKColor()) })
+		}
+	}
+}
+
+func newLightState(l lifx.Light) *lightState {
+	return &lightState{
+		Color: newHSBK(l.Color),
+		Power: powerLevel(l.Power),
+		Label: labelBytes(l.Label),
+	}
+}
+
+func setLightColor(l *lifx.Light, c lifx.HSBKColor) {
+	l.Color = c
+	if c.B != nil {
+		l.Brightness = float64(*c.B)
+	}
+}
+
+func powerLevel(power string) uint16 {
+	if power == "on" {
+		return 0xffff
+	}
+	return 0
+}
+
+func labelBytes(s string) (b [32]byte) {
+	copy(b[:], s)
+	return
+}
+
+// selectorBytes turns a group or location id back into the 16 bytes the
+// protocol carries, hashing ids that are not already in that form.
+func selectorBytes(id string) (b [16]byte) {
+	if raw, err := hex.DecodeString(id); err == nil && len(raw) == len(b) {
+		copy(b[:], raw)
+		return
+	}
+	return md5.Sum([]byte(id))
+}
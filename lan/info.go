@@ -0,0 +1,120 @@
+package lan
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type (
+	Firmware struct {
+		Build time.Time
+		Major uint16
+		Minor uint16
+	}
+
+	WifiInfo struct {
+		Signal float32
+	}
+
+	Version struct {
+		Vendor  uint32
+		Product uint32
+	}
+
+	Info struct {
+		Time     time.Time
+		Uptime   time.Duration
+		Downtime time.Duration
+	}
+
+	DeviceInfo struct {
+		Firmware Firmware
+		Wifi     WifiInfo
+		Version  Version
+		Info     Info
+	}
+)
+
+func (f Firmware) String() string {
+	return fmt.Sprintf("%d.%d", f.Major, f.Minor)
+}
+
+// RSSI converts the reported signal strength, which is in milliwatts, to
+// dBm.
+func (w WifiInfo) RSSI() float64 {
+	return math.Floor(10*math.Log10(float64(w.Signal)) + 0.5)
+}
+
+func (c *Client) GetHostFirmware(d Device) (Firmware, error) {
+	var s stateHostFirmware
+
+	if err := c.request(d, msgGetHostFirmware, nil, msgStateHostFirmware, &s); err != nil {
+		return Firmware{}, err
+	}
+
+	return Firmware{
+		Build: time.Unix(0, int64(s.Build)),
+		Major: s.VersionMajor,
+		Minor: s.VersionMinor,
+	}, nil
+}
+
+func (c *Client) GetWifiInfo(d Device) (WifiInfo, error) {
+	var s stateWifiInfo
+
+	if err := c.request(d, msgGetWifiInfo, nil, msgStateWifiInfo, &s); err != nil {
+		return WifiInfo{}, err
+	}
+
+	return WifiInfo{Signal: s.Signal}, nil
+}
+
+func (c *Client) GetVersion(d Device) (Version, error) {
+	var s stateVersion
+
+	if err := c.request(d, msgGetVersion, nil, msgStateVersion, &s); err != nil {
+		return Version{}, err
+	}
+
+	return Version{Vendor: s.Vendor, Product: s.Product}, nil
+}
+
+func (c *Client) GetInfo(d Device) (Info, error) {
+	var s stateInfo
+
+	if err := c.request(d, msgGetInfo, nil, msgStateInfo, &s); err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		Time:     time.Unix(0, int64(s.Time)),
+		Uptime:   time.Duration(s.Uptime),
+		Downtime: time.Duration(s.Downtime),
+	}, nil
+}
+
+func (c *Client) GetDeviceInfo(d Device) (DeviceInfo, error) {
+	var (
+		err  error
+		info DeviceInfo
+	)
+
+	if info.Firmware, err = c.GetHostFirmware(d); err != nil {
+		return info, err
+	}
+
+	if info.Wifi, err = c.GetWifiInfo(d); err != nil {
+		return info, err
+	}
+
+	if info.Version, err = c.GetVersion(d); err != nil {
+		return info, err
+	}
+
+	if info.Info, err = c.GetInfo(d); err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
@@ -0,0 +1,380 @@
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const DefaultPort = 56700
+
+var (
+	DefaultTimeout = 2 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = 250 * time.Millisecond
+
+	// DefaultRateLimit follows the LIFX recommendation of no more than 20
+	// messages per second to a single device.
+	DefaultRateLimit = 20
+)
+
+type Client struct {
+	conn      *net.UDPConn
+	ifaces    []string
+	bcasts    []string
+	network   string
+	timeout   time.Duration
+	broadcast []*net.UDPAddr
+	source    uint32
+	retries   int
+	backoff   time.Duration
+	acks      bool
+	limit     *limiter
+	dispatch  *dispatcher
+	done      chan struct{}
+	mu        sync.RWMutex
+	devices   map[string]Device
+	failures  map[string]int
+	registry  string
+	lights    map[string]lifx.Light
+	randMu    sync.Mutex
+	rand      *rand.Rand
+}
+
+var (
+	ErrTimeout = errors.New("timed out waiting for a response from the device")
+	ErrClosed  = errors.New("client is closed")
+)
+
+func NewClient(options ...func(*Client)) (*Client, error) {
+	var (
+		err   error
+		laddr *net.UDPAddr
+	)
+
+	c := &Client{
+		timeout:  DefaultTimeout,
+		retries:  DefaultRetries,
+		backoff:  DefaultBackoff,
+		acks:     true,
+		limit:    newLimiter(DefaultRateLimit),
+		network:  "udp4",
+		dispatch: newDispatcher(),
+		done:     make(chan struct{}),
+		devices:  make(map[string]Device),
+		failures: make(map[string]int),
+		lights:   make(map[string]lifx.Light),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if laddr, c.broadcast, err = c.listenAddrs(); err != nil {
+		return nil, err
+	}
+
+	// The registry is read before the socket is opened, so that a bad
+	// registry file doesn't leave it open.
+	if err = c.load(); err != nil {
+		return nil, err
+	}
+
+	if c.conn, err = net.ListenUDP(c.network, laddr); err != nil {
+		return nil, err
+	}
+
+	if c.source == 0 {
+		c.source = newSource()
+	}
+
+	go c.readLoop()
+
+	return c, nil
+}
+
+// WithRandSource draws random hues and the light a ":random" selector
+// picks from src, so that a seeded source picks the same ones every run.
+func WithRandSource(src rand.Source) func(*Client) {
+	return func(c *Client) {
+		c.rand = rand.New(src)
+	}
+}
+
+func WithTimeout(timeout time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithInterface restricts discovery to the named network interface. It can
+// be given more than once to discover across several interfaces.
+func WithInterface(name string) func(*Client) {
+	return func(c *Client) {
+		c.ifaces = append(c.ifaces, name)
+	}
+}
+
+// WithBroadcastAddr sends discovery to addr in addition to any interface
+// broadcasts. addr may be an IP address, a host:port pair, or a subnet in
+// CIDR notation whose broadcast address is used, which helps reach devices
+// on a VLAN or behind a Docker bridge the host is not directly attached
+// to.
+func WithBroadcastAddr(addr string) func(*Client) {
+	return func(c *Client) {
+		c.bcasts = append(c.bcasts, addr)
+	}
+}
+
+// WithRetries sets how many times an unanswered message is retransmitted
+// and how long to wait for the first reply. The wait doubles after every
+// attempt, up to the client timeout.
+func WithRetries(retries int, backoff time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithRateLimit caps how many messages per second are sent to any one
+// device. A limit of zero disables rate limiting.
+func WithRateLimit(perSecond int) func(*Client) {
+	return func(c *Client) {
+		c.limit = newLimiter(perSecond)
+	}
+}
+
+// WithAcks controls whether state changes wait for the device to
+// acknowledge them. Disabling acknowledgements trades delivery guarantees
+// for the lowest possible latency.
+func WithAcks(acks bool) func(*Client) {
+	return func(c *Client) {
+		c.acks = acks
+	}
+}
+
+// WithNetwork selects the address family: "udp4" (the default), "udp6",
+// or "udp" for a dual-stack socket that discovers over both.
+func WithNetwork(network string) func(*Client) {
+	return func(c *Client) {
+		c.network = network
+	}
+}
+
+// WithRegistry persists discovered devices to path so that later clients
+// can address them immediately instead of waiting for discovery.
+func WithRegistry(path string) func(*Client) {
+	return func(c *Client) {
+		c.registry = path
+	}
+}
+
+// WithSource sets the source identifier stamped on every message. It must
+// be non-zero, otherwise devices broadcast their replies to the network.
+func WithSource(source uint32) func(*Client) {
+	return func(c *Client) {
+		c.source = source
+	}
+}
+
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	if serr := c.save(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	defer c.dispatch.close()
+
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		h, p, err := decode(buf[:n])
+		if err != nil || h.Source != c.source {
+			continue
+		}
+
+		c.touch(h.Target)
+
+		c.dispatch.deliver(packet{
+			header:  h,
+			payload: append([]byte(nil), p...),
+			addr:    addr,
+		})
+	}
+}
+
+func (c *Client) header(target [8]byte, sequence uint8, typ uint16, flags uint8) header {
+	h := header{
+		Source:   c.source,
+		Target:   target,
+		Flags:    flags,
+		Sequence: sequence,
+		Type:     typ,
+	}
+
+	// An all-zero target addresses every device, which the protocol
+	// requires to be marked as tagged.
+	if target == ([8]byte{}) {
+		h.Protocol = tagged
+	}
+
+	return h
+}
+
+func (c *Client) write(addr *net.UDPAddr, h header, payload interface{}) error {
+	b, err := encode(h, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.transmit(addr, h.Target, b, 0)
+}
+
+// transmit waits for the target's rate limiter before writing b. It
+// returns errSuperseded instead of sending when a newer coalescable
+// message for the same target was queued in the meantime.
+func (c *Client) transmit(addr *net.UDPAddr, target [8]byte, b []byte, ticket uint64) error {
+	if target != ([8]byte{}) && !c.limit.acquire(target, ticket) {
+		return errSuperseded
+	}
+
+	_, err := c.conn.WriteToUDP(b, addr)
+	return err
+}
+
+// set delivers a state change. Unless acknowledgements are disabled it
+// blocks until the device confirms receipt, retransmitting as needed.
+func (c *Client) set(d Device, typ uint16, payload interface{}) error {
+	if !c.acks {
+		b, err := encode(c.header(d.target(), c.dispatch.next(), typ, 0), payload)
+		if err != nil {
+			return err
+		}
+		if err = c.transmit(d.Addr(), d.target(), b, c.limit.ticket(d.target(), typ)); err != errSuperseded {
+			return err
+		}
+		return nil
+	}
+
+	return c.exchange(d, typ, payload, ackRequired, func(h header, p []byte) (bool, error) {
+		return h.Type == msgAcknowledgement, nil
+	})
+}
+
+func (c *Client) request(d Device, typ uint16, payload interface{}, resType uint16, res interface{}) error {
+	return c.exchange(d, typ, payload, resRequired, func(h header, p []byte) (bool, error) {
+		if h.Type != resType {
+			return false, nil
+		}
+		return true, decodePayload(p, res)
+	})
+}
+
+// exchange sends a message and feeds every matching reply to handle until
+// it reports that it is done, which lets callers gather multi-packet
+// responses such as multizone state. The message is retransmitted with the
+// same sequence number, waiting twice as long each time, until the retries
+// are exhausted. Replies duplicated by retransmission are only handled once.
+func (c *Client) exchange(d Device, typ uint16, payload interface{}, flags uint8, handle func(header, []byte) (bool, error)) error {
+	err := c.exchangeOnce(d, typ, payload, flags, handle)
+	if err != ErrTimeout {
+		c.succeeded(d)
+		return err
+	}
+
+	// The device may have moved to a new address since it was last seen,
+	// in which case the message is worth one more attempt.
+	if moved, ok := c.failed(d); ok {
+		return c.exchangeOnce(moved, typ, payload, flags, handle)
+	}
+
+	return err
+}
+
+func (c *Client) exchangeOnce(d Device, typ uint16, payload interface{}, flags uint8, handle func(header, []byte) (bool, error)) error {
+	seq, ch := c.dispatch.register(pendingQueue)
+	defer c.dispatch.unregister(seq)
+
+	b, err := encode(c.header(d.target(), seq, typ, flags), payload)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	wait := c.backoff
+	ticket := c.limit.ticket(d.target(), typ)
+
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if err = c.transmit(d.Addr(), d.target(), b, ticket); err != nil {
+			if err == errSuperseded {
+				return nil
+			}
+			return err
+		}
+
+		if wait > c.timeout {
+			wait = c.timeout
+		}
+		done, err := c.await(ch, wait, seen, handle)
+		if err != nil || done {
+			return err
+		}
+		wait *= 2
+	}
+
+	return ErrTimeout
+}
+
+func (c *Client) await(ch <-chan packet, wait time.Duration, seen map[string]bool, handle func(header, []byte) (bool, error)) (bool, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case p, ok := <-ch:
+			if !ok {
+				return true, ErrClosed
+			}
+
+			key := fmt.Sprintf("%d:%x", p.header.Type, p.payload)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if done, err := handle(p.header, p.payload); err != nil || done {
+				return true, err
+			}
+		case <-timer.C:
+			return false, nil
+		}
+	}
+}
+
+func newSource() uint32 {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		// A source of zero asks devices to broadcast their replies.
+		if s := r.Uint32(); s != 0 {
+			return s
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lan
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var errSuperseded = errors.New("superseded by a newer message")
+
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[[8]byte]time.Time
+	latest   map[[8]byte]uint64
+}
+
+func newLimiter(perSecond int) *limiter {
+	l := &limiter{
+		next:   make(map[[8]byte]time.Time),
+		latest: make(map[[8]byte]uint64),
+	}
+	if perSecond > 0 {
+		l.interval = time.Second / time.Duration(perSecond)
+	}
+	return l
+}
+
+// ticket registers a coalescable message for target, superseding any
+// earlier one still waiting to be sent. Only SetColor is coalesced: when
+// colors arrive faster than the device may be sent messages, only the most
+// recent one matters. Other messages get the zero ticket.
+func (l *limiter) ticket(target [8]byte, typ uint16) uint64 {
+	if typ != msgSetColor || l.interval == 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.latest[target]++
+	return l.latest[target]
+}
+
+// acquire blocks until a message may be sent to target. It returns false
+// without consuming a slot if the ticket has been superseded while waiting.
+func (l *limiter) acquire(target [8]byte, ticket uint64) bool {
+	if l.interval == 0 {
+		return true
+	}
+
+	for {
+		l.mu.Lock()
+		if ticket != 0 && l.latest[target] != ticket {
+			l.mu.Unlock()
+			return false
+		}
+
+		now := time.Now()
+		next := l.next[target]
+		if !now.Before(next) {
+			l.next[target] = now.Add(l.interval)
+			l.mu.Unlock()
+			return true
+		}
+		l.mu.Unlock()
+
+		time.Sleep(next.Sub(now))
+	}
+}
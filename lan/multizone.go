@@ -0,0 +1,139 @@
+package lan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	ZoneNoApply ZoneApply = iota
+	ZoneApplyNow
+	ZoneApplyOnly
+)
+
+const MaxExtendedZones = 82
+
+type ZoneApply uint8
+
+func (c *Client) SetColorZones(d Device, start, end uint8, color lifx.HSBKColor, duration time.Duration, apply ZoneApply) error {
+	if start > end {
+		return fmt.Errorf("start zone %d is after end zone %d", start, end)
+	}
+
+	return c.set(d, msgSetColorZones, &setColorZones{
+		StartIndex: start,
+		EndIndex:   end,
+		Color:      newHSBK(color),
+		Duration:   durationMillis(duration),
+		Apply:      apply,
+	})
+}
+
+func (c *Client) GetColorZones(d Device, start, end uint8) ([]lifx.Zone, error) {
+	var zones = make(map[int]lifx.Zone)
+
+	if start > end {
+		return nil, fmt.Errorf("start zone %d is after end zone %d", start, end)
+	}
+
+	// The device only knows how many zones it has once it replies, so the
+	// requested range is narrowed to fit the first response.
+	last := int(end)
+	collect := func(count, index int, colors []hsbk) bool {
+		if count > 0 && last > count-1 {
+			last = count - 1
+		}
+		for i, color := range colors {
+			if z := index + i; z >= int(start) && z <= last {
+				zones[z] = color.Zone(z)
+			}
+		}
+		return len(zones) >= last-int(start)+1
+	}
+
+	err := c.exchange(d, msgGetColorZones, &getColorZones{StartIndex: start, EndIndex: end}, resRequired, func(h header, p []byte) (bool, error) {
+		switch h.Type {
+		case msgStateZone:
+			var s stateZone
+			if err := decodePayload(p, &s); err != nil {
+				return true, err
+			}
+			return collect(int(s.Count), int(s.Index), []hsbk{s.Color}), nil
+		case msgStateMultiZone:
+			var s stateMultiZone
+			if err := decodePayload(p, &s); err != nil {
+				return true, err
+			}
+			return collect(int(s.Count), int(s.Index), s.Colors[:]), nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedZones(zones), nil
+}
+
+func (c *Client) SetExtendedColorZones(d Device, index uint16, colors []lifx.HSBKColor, duration time.Duration, apply ZoneApply) error {
+	if len(colors) > MaxExtendedZones {
+		return fmt.Errorf("at most %d zones can be set in one message", MaxExtendedZones)
+	}
+
+	s := setExtendedColorZones{
+		Duration:    durationMillis(duration),
+		Apply:       apply,
+		ZoneIndex:   index,
+		ColorsCount: uint8(len(colors)),
+	}
+	for i, color := range colors {
+		s.Colors[i] = newHSBK(color)
+	}
+
+	return c.set(d, msgSetExtendedColorZones, &s)
+}
+
+func (c *Client) GetExtendedColorZones(d Device) ([]lifx.Zone, error) {
+	var (
+		zones = make(map[int]lifx.Zone)
+		total = -1
+	)
+
+	err := c.exchange(d, msgGetExtendedColorZones, nil, resRequired, func(h header, p []byte) (bool, error) {
+		if h.Type != msgStateExtendedColorZones {
+			return false, nil
+		}
+
+		var s stateExtendedColorZones
+		if err := decodePayload(p, &s); err != nil {
+			return true, err
+		}
+
+		total = int(s.ZonesCount)
+		for i := 0; i < int(s.ColorsCount) && i < MaxExtendedZones; i++ {
+			z := int(s.ZoneIndex) + i
+			if z < total {
+				zones[z] = s.Colors[i].Zone(z)
+			}
+		}
+
+		return len(zones) >= total, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedZones(zones), nil
+}
+
+func sortedZones(m map[int]lifx.Zone) []lifx.Zone {
+	zones := make([]lifx.Zone, 0, len(m))
+	for _, z := range m {
+		zones = append(zones, z)
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Zone < zones[j].Zone })
+	return zones
+}
@@ -0,0 +1,176 @@
+package lan
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// listenAddrs works out the local address to bind and where discovery
+// broadcasts are sent. IPv6 has no broadcast, so discovery there uses the
+// link-local all-nodes multicast group on each interface instead.
+func (c *Client) listenAddrs() (*net.UDPAddr, []*net.UDPAddr, error) {
+	var (
+		laddr      = &net.UDPAddr{}
+		broadcasts []*net.UDPAddr
+	)
+
+	switch c.network {
+	case "udp", "udp4", "udp6":
+	default:
+		return nil, nil, fmt.Errorf("unsupported network %s", c.network)
+	}
+
+	for _, s := range c.bcasts {
+		addr, err := parseBroadcastAddr(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		broadcasts = append(broadcasts, addr)
+	}
+
+	if c.network != "udp6" {
+		if len(c.ifaces) == 0 && len(c.bcasts) == 0 {
+			broadcasts = append(broadcasts, &net.UDPAddr{IP: net.IPv4bcast, Port: DefaultPort})
+		}
+
+		for _, name := range c.ifaces {
+			ips, bcasts, err := interfaceAddrs(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, b := range bcasts {
+				broadcasts = append(broadcasts, &net.UDPAddr{IP: b, Port: DefaultPort})
+			}
+			// Binding to the interface keeps replies on it, but that is only
+			// possible with a single IPv4 address; a dual-stack socket
+			// cannot be bound to one at all.
+			if c.network == "udp4" && len(c.ifaces) == 1 && len(ips) == 1 {
+				laddr.IP = ips[0]
+			}
+		}
+	}
+
+	if c.network != "udp4" {
+		ifaces, err := multicastInterfaces(c.ifaces)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, iface := range ifaces {
+			broadcasts = append(broadcasts, &net.UDPAddr{
+				IP:   net.IPv6linklocalallnodes,
+				Port: DefaultPort,
+				Zone: iface.Name,
+			})
+		}
+	}
+
+	return laddr, broadcasts, nil
+}
+
+func parseBroadcastAddr(s string) (*net.UDPAddr, error) {
+	if _, n, err := net.ParseCIDR(s); err == nil {
+		b := subnetBroadcast(n)
+		if b == nil {
+			return nil, fmt.Errorf("subnet %s has no broadcast address", s)
+		}
+		return &net.UDPAddr{IP: b, Port: DefaultPort}, nil
+	}
+
+	if ip := net.ParseIP(s); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: DefaultPort}, nil
+	}
+
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broadcast address %s", s)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid broadcast address %s", s)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broadcast port %s", port)
+	}
+
+	return &net.UDPAddr{IP: ip, Port: p}, nil
+}
+
+func subnetBroadcast(n *net.IPNet) net.IP {
+	v4 := n.IP.To4()
+	if v4 == nil {
+		return nil
+	}
+	mask := net.IP(n.Mask).To4()
+	if mask == nil {
+		return nil
+	}
+
+	b := make(net.IP, net.IPv4len)
+	for i := range v4 {
+		b[i] = v4[i] | ^mask[i]
+	}
+	return b
+}
+
+func multicastInterfaces(names []string) ([]net.Interface, error) {
+	var ifaces []net.Interface
+
+	if len(names) > 0 {
+		for _, name := range names {
+			iface, err := net.InterfaceByName(name)
+			if err != nil {
+				return nil, err
+			}
+			ifaces = append(ifaces, *iface)
+		}
+		return ifaces, nil
+	}
+
+	all, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range all {
+		if iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagMulticast != 0 && iface.Flags&net.FlagLoopback == 0 {
+			ifaces = append(ifaces, iface)
+		}
+	}
+	return ifaces, nil
+}
+
+// interfaceAddrs returns every IPv4 address on the named interface along
+// with the broadcast address of its subnet.
+func interfaceAddrs(name string) (ips, broadcasts []net.IP, err error) {
+	var (
+		iface *net.Interface
+		addrs []net.Addr
+	)
+
+	if iface, err = net.InterfaceByName(name); err != nil {
+		return nil, nil, err
+	}
+
+	if addrs, err = iface.Addrs(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, addr := range addrs {
+		n, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if b := subnetBroadcast(n); b != nil {
+			ips = append(ips, n.IP.To4())
+			broadcasts = append(broadcasts, b)
+		}
+	}
+
+	if len(ips) == 0 {
+		return nil, nil, fmt.Errorf("interface %s has no IPv4 address", name)
+	}
+
+	return ips, broadcasts, nil
+}
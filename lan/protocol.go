@@ -0,0 +1,400 @@
+package lan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	protocolNumber = 1024
+	addressable    = 1 << 12
+	tagged         = 1 << 13
+
+	headerSize = 36
+)
+
+const (
+	resRequired = 1 << 0
+	ackRequired = 1 << 1
+)
+
+const (
+	msgGetService              uint16 = 2
+	msgStateService            uint16 = 3
+	msgGetHostFirmware         uint16 = 14
+	msgStateHostFirmware       uint16 = 15
+	msgGetWifiInfo             uint16 = 16
+	msgStateWifiInfo           uint16 = 17
+	msgGetPower                uint16 = 20
+	msgStatePower              uint16 = 22
+	msgGetLabel                uint16 = 23
+	msgStateLabel              uint16 = 25
+	msgGetVersion              uint16 = 32
+	msgStateVersion            uint16 = 33
+	msgGetInfo                 uint16 = 34
+	msgStateInfo               uint16 = 35
+	msgAcknowledgement         uint16 = 45
+	msgGetLocation             uint16 = 48
+	msgStateLocation           uint16 = 50
+	msgGetGroup                uint16 = 51
+	msgStateGroup              uint16 = 53
+	msgGetColor                uint16 = 101
+	msgSetColor                uint16 = 102
+	msgSetWaveform             uint16 = 103
+	msgLightState              uint16 = 107
+	msgGetLightPower           uint16 = 116
+	msgSetLightPower           uint16 = 117
+	msgStateLightPower         uint16 = 118
+	msgSetWaveformOptional     uint16 = 119
+	msgSetColorZones           uint16 = 501
+	msgGetColorZones           uint16 = 502
+	msgStateZone               uint16 = 503
+	msgStateMultiZone          uint16 = 506
+	msgSetExtendedColorZones   uint16 = 510
+	msgGetExtendedColorZones   uint16 = 511
+	msgStateExtendedColorZones uint16 = 512
+	msgGetDeviceChain          uint16 = 701
+	msgStateDeviceChain        uint16 = 702
+	msgGetTileState64          uint16 = 707
+	msgStateTileState64        uint16 = 711
+	msgSetTileState64          uint16 = 715
+)
+
+type (
+	header struct {
+		Size     uint16
+		Protocol uint16
+		Source   uint32
+		Target   [8]byte
+		_        [6]byte
+		Flags    uint8
+		Sequence uint8
+		_        uint64
+		Type     uint16
+		_        uint16
+	}
+
+	stateService struct {
+		Service Service
+		Port    uint32
+	}
+
+	stateHostFirmware struct {
+		Build        uint64
+		_            uint64
+		VersionMinor uint16
+		VersionMajor uint16
+	}
+
+	stateWifiInfo struct {
+		Signal float32
+		_      uint32
+		_      uint32
+		_      int16
+	}
+
+	stateVersion struct {
+		Vendor  uint32
+		Product uint32
+		_       uint32
+	}
+
+	stateInfo struct {
+		Time     uint64
+		Uptime   uint64
+		Downtime uint64
+	}
+
+	hsbk struct {
+		Hue        uint16
+		Saturation uint16
+		Brightness uint16
+		Kelvin     uint16
+	}
+
+	statePower struct {
+		Level uint16
+	}
+
+	stateLabel struct {
+		Label [32]byte
+	}
+
+	stateLocation struct {
+		Location  [16]byte
+		Label     [32]byte
+		UpdatedAt uint64
+	}
+
+	stateGroup struct {
+		Group     [16]byte
+		Label     [32]byte
+		UpdatedAt uint64
+	}
+
+	setColor struct {
+		_        uint8
+		Color    hsbk
+		Duration uint32
+	}
+
+	setLightPower struct {
+		Level    uint16
+		Duration uint32
+	}
+
+	setWaveform struct {
+		_         uint8
+		Transient uint8
+		Color     hsbk
+		Period    uint32
+		Cycles    float32
+		SkewRatio int16
+		Waveform  Wave
+	}
+
+	setWaveformOptional struct {
+		setWaveform
+		SetHue        uint8
+		SetSaturation uint8
+		SetBrightness uint8
+		SetKelvin     uint8
+	}
+
+	setColorZones struct {
+		StartIndex uint8
+		EndIndex   uint8
+		Color      hsbk
+		Duration   uint32
+		Apply      ZoneApply
+	}
+
+	getColorZones struct {
+		StartIndex uint8
+		EndIndex   uint8
+	}
+
+	stateZone struct {
+		Count uint8
+		Index uint8
+		Color hsbk
+	}
+
+	stateMultiZone struct {
+		Count  uint8
+		Index  uint8
+		Colors [8]hsbk
+	}
+
+	setExtendedColorZones struct {
+		Duration    uint32
+		Apply       ZoneApply
+		ZoneIndex   uint16
+		ColorsCount uint8
+		Colors      [82]hsbk
+	}
+
+	stateExtendedColorZones struct {
+		ZonesCount  uint16
+		ZoneIndex   uint16
+		ColorsCount uint8
+		Colors      [82]hsbk
+	}
+
+	tile struct {
+		AccelMeasX           int16
+		AccelMeasY           int16
+		AccelMeasZ           int16
+		_                    int16
+		UserX                float32
+		UserY                float32
+		Width                uint8
+		Height               uint8
+		_                    uint8
+		DeviceVersionVendor  uint32
+		DeviceVersionProduct uint32
+		_                    uint32
+		FirmwareBuild        uint64
+		_                    uint64
+		FirmwareVersionMinor uint16
+		FirmwareVersionMajor uint16
+		_                    uint32
+	}
+
+	stateDeviceChain struct {
+		StartIndex       uint8
+		TileDevices      [16]tile
+		TileDevicesCount uint8
+	}
+
+	getTileState64 struct {
+		TileIndex uint8
+		Length    uint8
+		_         uint8
+		X         uint8
+		Y         uint8
+		Width     uint8
+	}
+
+	setTileState64 struct {
+		TileIndex uint8
+		Length    uint8
+		_         uint8
+		X         uint8
+		Y         uint8
+		Width     uint8
+		Duration  uint32
+		Colors    [64]hsbk
+	}
+
+	stateTileState64 struct {
+		TileIndex uint8
+		_         uint8
+		X         uint8
+		Y         uint8
+		Width     uint8
+		Colors    [64]hsbk
+	}
+
+	lightState struct {
+		Color hsbk
+		_     int16
+		Power uint16
+		Label [32]byte
+		_     uint64
+	}
+)
+
+var (
+	errShortPacket = errors.New("packet is shorter than the protocol header")
+	errPacketSize  = errors.New("packet size is smaller than the protocol header")
+)
+
+func (h *header) Tagged() bool {
+	return h.Protocol&tagged != 0
+}
+
+const defaultKelvin = 3500
+
+// newHSBK fills in any component missing from c, using full brightness and
+// a neutral white temperature so a bare hue never turns a bulb off.
+func newHSBK(c lifx.HSBKColor) hsbk {
+	var v hsbk
+
+	if c.H != nil {
+		v.Hue = uint16(math.Round(float64(*c.H) / 360 * 0xffff))
+	}
+	if c.S != nil {
+		v.Saturation = scaleUnit(*c.S)
+	}
+	v.Brightness = 0xffff
+	if c.B != nil {
+		v.Brightness = scaleUnit(*c.B)
+	}
+	v.Kelvin = defaultKelvin
+	if c.K != nil {
+		v.Kelvin = uint16(*c.K)
+	}
+
+	return v
+}
+
+func scaleUnit(f float32) uint16 {
+	return uint16(math.Round(float64(f) * 0xffff))
+}
+
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func durationMillis(d time.Duration) uint32 {
+	return uint32(d / time.Millisecond)
+}
+
+func (c hsbk) HSBKColor() lifx.HSBKColor {
+	return lifx.HSBKColor{
+		H: lifx.Float32Ptr(float32(c.Hue) * 360 / 0xffff),
+		S: lifx.Float32Ptr(float32(c.Saturation) / 0xffff),
+		B: lifx.Float32Ptr(float32(c.Brightness) / 0xffff),
+		K: lifx.Int16Ptr(int16(c.Kelvin)),
+	}
+}
+
+func (c hsbk) Zone(index int) lifx.Zone {
+	return lifx.Zone{
+		Zone:       index,
+		Hue:        float32(c.Hue) * 360 / 0xffff,
+		Saturation: float32(c.Saturation) / 0xffff,
+		Brightness: float32(c.Brightness) / 0xffff,
+		Kelvin:     int16(c.Kelvin),
+	}
+}
+
+func powerString(level uint16) string {
+	if level == 0 {
+		return "off"
+	}
+	return "on"
+}
+
+func labelString(b [32]byte) string {
+	if i := bytes.IndexByte(b[:], 0); i >= 0 {
+		return string(b[:i])
+	}
+	return string(b[:])
+}
+
+func encode(h header, payload interface{}) ([]byte, error) {
+	var p bytes.Buffer
+
+	if payload != nil {
+		if err := binary.Write(&p, binary.LittleEndian, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	h.Size = uint16(headerSize + p.Len())
+	h.Protocol |= protocolNumber | addressable
+
+	var b bytes.Buffer
+	b.Grow(int(h.Size))
+	if err := binary.Write(&b, binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+	b.Write(p.Bytes())
+
+	return b.Bytes(), nil
+}
+
+func decode(b []byte) (header, []byte, error) {
+	var h header
+
+	if len(b) < headerSize {
+		return h, nil, errShortPacket
+	}
+
+	if err := binary.Read(bytes.NewReader(b[:headerSize]), binary.LittleEndian, &h); err != nil {
+		return h, nil, err
+	}
+
+	if h.Size < headerSize {
+		return h, nil, errPacketSize
+	}
+	if int(h.Size) > len(b) {
+		return h, nil, errShortPacket
+	}
+
+	return h, b[headerSize:h.Size], nil
+}
+
+func decodePayload(b []byte, v interface{}) error {
+	return binary.Read(bytes.NewReader(b), binary.LittleEndian, v)
+}
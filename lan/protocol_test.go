@@ -0,0 +1,73 @@
+package lan
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	packet, err := encode(header{Type: msgStateService, Source: 7}, &stateService{Service: 1, Port: 56700})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resized := func(size uint16) []byte {
+		b := append([]byte(nil), packet...)
+		binary.LittleEndian.PutUint16(b, size)
+		return b
+	}
+
+	for _, tt := range []struct {
+		name    string
+		b       []byte
+		payload int
+		err     error
+	}{
+		{"whole", packet, len(packet) - headerSize, nil},
+		{"trailing bytes", append(append([]byte(nil), packet...), 0, 0), len(packet) - headerSize, nil},
+		{"header only", resized(headerSize), 0, nil},
+		{"empty", nil, 0, errShortPacket},
+		{"truncated header", packet[:headerSize-1], 0, errShortPacket},
+		{"truncated payload", packet[:len(packet)-1], 0, errShortPacket},
+		{"size zero", resized(0), 0, errPacketSize},
+		{"size below header", resized(10), 0, errPacketSize},
+		{"size past end", resized(uint16(len(packet) + 1)), 0, errShortPacket},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h, payload, err := decode(tt.b)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if h.Type != msgStateService || h.Source != 7 {
+				t.Errorf("got header %+v", h)
+			}
+			if len(payload) != tt.payload {
+				t.Errorf("got %d bytes of payload, want %d", len(payload), tt.payload)
+			}
+		})
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	packet, err := encode(header{Type: msgStateService}, &stateService{Service: 1, Port: 56700})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, payload, err := decode(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s stateService
+	if err = decodePayload(payload, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Service != 1 || s.Port != 56700 {
+		t.Errorf("got %+v", s)
+	}
+}
@@ -0,0 +1,195 @@
+package lan
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const registryVersion = 1
+
+var (
+	// DefaultRegistryTTL is how long a device loaded from the registry is
+	// trusted without having been seen on the network.
+	DefaultRegistryTTL = 7 * 24 * time.Hour
+
+	// DefaultRediscoverAfter is how many consecutive timeouts a device may
+	// have before the network is rediscovered.
+	DefaultRediscoverAfter = 2
+)
+
+type (
+	registryFile struct {
+		Version int             `json:"version"`
+		Devices []registryEntry `json:"devices"`
+	}
+
+	registryEntry struct {
+		MAC      string    `json:"mac"`
+		IP       string    `json:"ip"`
+		Zone     string    `json:"zone,omitempty"`
+		Port     int       `json:"port"`
+		Service  Service   `json:"service"`
+		LastSeen time.Time `json:"last_seen"`
+	}
+)
+
+func (c *Client) remember(devices []Device) {
+	c.mu.Lock()
+	for _, d := range devices {
+		c.devices[d.Id()] = d
+		delete(c.failures, d.Id())
+	}
+	c.mu.Unlock()
+
+	c.save()
+}
+
+func (c *Client) Devices() []Device {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	devices := make([]Device, 0, len(c.devices))
+	for _, d := range c.devices {
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+func (c *Client) touch(target [8]byte) {
+	id := lifx.LightID(target[:6])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d, ok := c.devices[id]; ok {
+		d.LastSeen = time.Now()
+		c.devices[id] = d
+	}
+}
+
+func (c *Client) succeeded(d Device) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.failures, d.Id())
+}
+
+// failed records a timeout talking to d. Once the device has failed often
+// enough the network is rediscovered, and the device's new address is
+// returned if it has moved.
+func (c *Client) failed(d Device) (Device, bool) {
+	c.mu.Lock()
+	c.failures[d.Id()]++
+	n := c.failures[d.Id()]
+	c.mu.Unlock()
+
+	if n < DefaultRediscoverAfter {
+		return d, false
+	}
+
+	if _, err := c.Discover(); err != nil {
+		return d, false
+	}
+
+	c.mu.RLock()
+	moved, ok := c.devices[d.Id()]
+	c.mu.RUnlock()
+
+	if !ok || (moved.IP.Equal(d.IP) && moved.Port == d.Port && moved.Zone == d.Zone) {
+		return d, false
+	}
+	return moved, true
+}
+
+func (c *Client) load() error {
+	var f registryFile
+
+	if c.registry == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(c.registry)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if err = json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+
+	if f.Version != registryVersion {
+		return nil
+	}
+
+	for _, e := range f.Devices {
+		if time.Since(e.LastSeen) > DefaultRegistryTTL {
+			continue
+		}
+		mac, err := net.ParseMAC(e.MAC)
+		if err != nil {
+			continue
+		}
+		d := Device{
+			MAC:      mac,
+			IP:       net.ParseIP(e.IP),
+			Zone:     e.Zone,
+			Port:     e.Port,
+			Service:  e.Service,
+			LastSeen: e.LastSeen,
+		}
+		c.devices[d.Id()] = d
+	}
+
+	return nil
+}
+
+func (c *Client) save() error {
+	if c.registry == "" {
+		return nil
+	}
+
+	f := registryFile{Version: registryVersion}
+	for _, d := range c.Devices() {
+		f.Devices = append(f.Devices, registryEntry{
+			MAC:      d.MAC.String(),
+			IP:       d.IP.String(),
+			Zone:     d.Zone,
+			Port:     d.Port,
+			Service:  d.Service,
+			LastSeen: d.LastSeen,
+		})
+	}
+
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// registry behind.
+	tmp, err := ioutil.TempFile(filepath.Dir(c.registry), ".lifx-registry-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.registry)
+}
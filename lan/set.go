@@ -0,0 +1,26 @@
+package lan
+
+import (
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func (c *Client) SetColor(d Device, color lifx.HSBKColor, duration time.Duration) error {
+	return c.set(d, msgSetColor, &setColor{
+		Color:    newHSBK(color),
+		Duration: durationMillis(duration),
+	})
+}
+
+func (c *Client) SetPower(d Device, on bool, duration time.Duration) error {
+	var level uint16
+	if on {
+		level = 0xffff
+	}
+
+	return c.set(d, msgSetLightPower, &setLightPower{
+		Level:    level,
+		Duration: durationMillis(duration),
+	})
+}
@@ -0,0 +1,97 @@
+package lan
+
+import (
+	"encoding/hex"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func (d Device) Id() string {
+	return lifx.LightID(d.MAC)
+}
+
+func (c *Client) GetColor(d Device) (lifx.Light, error) {
+	var s lightState
+
+	if err := c.request(d, msgGetColor, nil, msgLightState, &s); err != nil {
+		return lifx.Light{}, err
+	}
+
+	color := s.Color.HSBKColor()
+
+	return lifx.Light{
+		Id:         d.Id(),
+		Label:      labelString(s.Label),
+		Connected:  true,
+		Power:      powerString(s.Power),
+		Color:      color,
+		Brightness: float64(*color.B),
+	}, nil
+}
+
+func (c *Client) GetPower(d Device) (string, error) {
+	var s statePower
+
+	if err := c.request(d, msgGetPower, nil, msgStatePower, &s); err != nil {
+		return "", err
+	}
+
+	return powerString(s.Level), nil
+}
+
+func (c *Client) GetLabel(d Device) (string, error) {
+	var s stateLabel
+
+	if err := c.request(d, msgGetLabel, nil, msgStateLabel, &s); err != nil {
+		return "", err
+	}
+
+	return labelString(s.Label), nil
+}
+
+func (c *Client) GetGroup(d Device) (lifx.Selector, error) {
+	var s stateGroup
+
+	if err := c.request(d, msgGetGroup, nil, msgStateGroup, &s); err != nil {
+		return lifx.Selector{}, err
+	}
+
+	return lifx.Selector{
+		Id:   hex.EncodeToString(s.Group[:]),
+		Name: labelString(s.Label),
+	}, nil
+}
+
+func (c *Client) GetLocation(d Device) (lifx.Selector, error) {
+	var s stateLocation
+
+	if err := c.request(d, msgGetLocation, nil, msgStateLocation, &s); err != nil {
+		return lifx.Selector{}, err
+	}
+
+	return lifx.Selector{
+		Id:   hex.EncodeToString(s.Location[:]),
+		Name: labelString(s.Label),
+	}, nil
+}
+
+func (c *Client) GetLight(d Device) (lifx.Light, error) {
+	var (
+		err   error
+		light lifx.Light
+	)
+
+	if light, err = c.GetColor(d); err != nil {
+		return light, err
+	}
+
+	if light.Group, err = c.GetGroup(d); err != nil {
+		return light, err
+	}
+
+	if light.Location, err = c.GetLocation(d); err != nil {
+		return light, err
+	}
+
+	return light, nil
+}
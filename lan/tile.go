@@ -0,0 +1,131 @@
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const tileColors = 64
+
+func (c *Client) GetDeviceChain(d Device) (lifx.Chain, error) {
+	var (
+		s     stateDeviceChain
+		chain lifx.Chain
+	)
+
+	if err := c.request(d, msgGetDeviceChain, nil, msgStateDeviceChain, &s); err != nil {
+		return chain, err
+	}
+
+	for i := 0; i < int(s.TileDevicesCount) && i < len(s.TileDevices); i++ {
+		t := s.TileDevices[i]
+		chain.Children = append(chain.Children, lifx.ChainChild{
+			Index:  int(s.StartIndex) + i,
+			UserX:  float64(t.UserX),
+			UserY:  float64(t.UserY),
+			Width:  int(t.Width),
+			Height: int(t.Height),
+		})
+	}
+
+	return chain, nil
+}
+
+func (c *Client) GetTileState64(d Device, tile, width uint8) ([][]lifx.HSBKColor, error) {
+	var s stateTileState64
+
+	if width == 0 || width > 8 {
+		return nil, errors.New("width must be between 1 and 8")
+	}
+
+	err := c.exchange(d, msgGetTileState64, &getTileState64{TileIndex: tile, Length: 1, Width: width}, resRequired, func(h header, p []byte) (bool, error) {
+		if h.Type != msgStateTileState64 {
+			return false, nil
+		}
+		if err := decodePayload(p, &s); err != nil {
+			return true, err
+		}
+		return s.TileIndex == tile, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]lifx.HSBKColor, tileColors/int(width))
+	for y := range rows {
+		rows[y] = make([]lifx.HSBKColor, width)
+		for x := range rows[y] {
+			rows[y][x] = s.Colors[y*int(width)+x].HSBKColor()
+		}
+	}
+
+	return rows, nil
+}
+
+func (c *Client) SetTileState64(d Device, tile, x, y, width uint8, colors []lifx.HSBKColor, duration time.Duration) error {
+	if len(colors) > tileColors {
+		return fmt.Errorf("at most %d colors can be set in one message", tileColors)
+	}
+
+	s := setTileState64{
+		TileIndex: tile,
+		Length:    1,
+		X:         x,
+		Y:         y,
+		Width:     width,
+		Duration:  durationMillis(duration),
+	}
+	for i, color := range colors {
+		s.Colors[i] = newHSBK(color)
+	}
+
+	return c.set(d, msgSetTileState64, &s)
+}
+
+// SetMatrix paints m across the device chain, treating the tiles as laid
+// out left to right so that each tile takes the next block of columns.
+func (c *Client) SetMatrix(d Device, m [][]lifx.HSBKColor, duration time.Duration) error {
+	chain, err := c.GetDeviceChain(d)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	for _, t := range chain.Children {
+		if t.Width == 0 {
+			continue
+		}
+
+		// A tile wider or taller than 8 pixels needs several messages, each
+		// carrying as many full rows as fit in 64 colors.
+		if t.Width > tileColors {
+			return fmt.Errorf("tile %d is %d pixels wide, more than fit in one message", t.Index, t.Width)
+		}
+		step := tileColors / t.Width
+		for y := 0; y < t.Height; y += step {
+			var colors []lifx.HSBKColor
+			for row := y; row < y+step && row < t.Height; row++ {
+				for col := 0; col < t.Width; col++ {
+					colors = append(colors, matrixAt(m, row, offset+col))
+				}
+			}
+			if err = c.SetTileState64(d, uint8(t.Index), 0, uint8(y), uint8(t.Width), colors, duration); err != nil {
+				return err
+			}
+		}
+
+		offset += t.Width
+	}
+
+	return nil
+}
+
+func matrixAt(m [][]lifx.HSBKColor, row, col int) lifx.HSBKColor {
+	if row < len(m) && col < len(m[row]) {
+		return m[row][col]
+	}
+	return lifx.HSBKColor{B: lifx.Float32Ptr(0)}
+}
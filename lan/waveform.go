@@ -0,0 +1,99 @@
+package lan
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	WaveSaw Wave = iota
+	WaveSine
+	WaveHalfSine
+	WaveTriangle
+	WavePulse
+)
+
+type (
+	Wave uint8
+
+	Waveform struct {
+		Transient bool
+		Color     lifx.HSBKColor
+		Period    time.Duration
+		Cycles    float32
+		SkewRatio float32
+		Wave      Wave
+	}
+)
+
+var (
+	DefaultWaveformPeriod            = time.Second
+	DefaultWaveformCycles    float32 = 1
+	DefaultWaveformSkewRatio float32 = 0.5
+)
+
+func NewWaveform(wave Wave, color lifx.HSBKColor) Waveform {
+	return Waveform{
+		Transient: true,
+		Color:     color,
+		Period:    DefaultWaveformPeriod,
+		Cycles:    DefaultWaveformCycles,
+		SkewRatio: DefaultWaveformSkewRatio,
+		Wave:      wave,
+	}
+}
+
+func (w *Waveform) Valid() error {
+	if w.Wave > WavePulse {
+		return errors.New("unknown waveform")
+	}
+	if w.SkewRatio < 0 || w.SkewRatio > 1 {
+		return errors.New("skew ratio must be between 0.0 and 1.0")
+	}
+	if w.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	if w.Cycles <= 0 {
+		return errors.New("cycles must be positive")
+	}
+	return nil
+}
+
+func (w *Waveform) payload() setWaveform {
+	return setWaveform{
+		Transient: boolByte(w.Transient),
+		Color:     newHSBK(w.Color),
+		Period:    durationMillis(w.Period),
+		Cycles:    w.Cycles,
+		SkewRatio: int16(int32(math.Round(float64(w.SkewRatio)*0xffff)) - 0x8000),
+		Waveform:  w.Wave,
+	}
+}
+
+func (c *Client) SetWaveform(d Device, w Waveform) error {
+	if err := w.Valid(); err != nil {
+		return err
+	}
+
+	p := w.payload()
+	return c.set(d, msgSetWaveform, &p)
+}
+
+// SetWaveformOptional only changes the color components that are set on
+// w.Color, leaving the others at the device's current values.
+func (c *Client) SetWaveformOptional(d Device, w Waveform) error {
+	if err := w.Valid(); err != nil {
+		return err
+	}
+
+	return c.set(d, msgSetWaveformOptional, &setWaveformOptional{
+		setWaveform:   w.payload(),
+		SetHue:        boolByte(w.Color.H != nil),
+		SetSaturation: boolByte(w.Color.S != nil),
+		SetBrightness: boolByte(w.Color.B != nil),
+		SetKelvin:     boolByte(w.Color.K != nil),
+	})
+}
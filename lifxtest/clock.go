@@ -0,0 +1,130 @@
+package lifxtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	// Clock is a lifx.Clock that only moves when Advance is called, so
+	// tests of retries, polling, caches and schedules run without sleeping.
+	Clock struct {
+		mu      sync.Mutex
+		now     time.Time
+		waiters []*waiter
+	}
+
+	// waiter is a timer, or a ticker when period is set.
+	waiter struct {
+		clock  *Clock
+		when   time.Time
+		period time.Duration
+		c      chan time.Time
+	}
+
+	ticker struct{ *waiter }
+)
+
+var _ lifx.Clock = (*Clock)(nil)
+
+// NewClock returns a clock reading now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) NewTimer(d time.Duration) lifx.Timer {
+	return c.add(d, 0)
+}
+
+func (c *Clock) NewTicker(d time.Duration) lifx.Ticker {
+	if d <= 0 {
+		panic("lifxtest: non-positive interval for NewTicker")
+	}
+	return ticker{c.add(d, d)}
+}
+
+// Advance moves the clock on by d, firing timers and tickers as it passes
+// the times they are due, in order. Like the real ones, a ticker that is
+// not read drops ticks.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].when.Before(c.waiters[j].when)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].when.After(end) {
+			break
+		}
+
+		w := c.waiters[0]
+		c.now = w.when
+		select {
+		case w.c <- c.now:
+		default:
+		}
+
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+	c.now = end
+}
+
+// Waiters is the number of timers and tickers still pending, which lets a
+// test wait until the code under test has started waiting before it
+// calls Advance.
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+func (c *Clock) add(d, period time.Duration) *waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &waiter{clock: c, when: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- c.now
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (w *waiter) C() <-chan time.Time {
+	return w.c
+}
+
+// Stop reports whether it stopped the timer before it fired.
+func (w *waiter) Stop() bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, x := range c.waiters {
+		if x == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t ticker) Stop() { t.waiter.Stop() }
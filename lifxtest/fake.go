@@ -0,0 +1,260 @@
+package lifxtest
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+var ErrNoMatch = errors.New("Selector did not match any lights")
+
+type (
+	Call struct {
+		Method   string
+		Selector string
+		Arg      interface{}
+	}
+
+	// FakeClient is an in-memory lifx.Backend. It applies calls to its
+	// lights the way the API would, records every call, and can be told
+	// to fail specific methods.
+	FakeClient struct {
+		mu     sync.Mutex
+		lights []lifx.Light
+		errs   map[string]error
+		calls  []Call
+		rand   *rand.Rand
+	}
+)
+
+var _ lifx.Backend = (*FakeClient)(nil)
+
+func NewFakeClient(lights ...lifx.Light) *FakeClient {
+	return &FakeClient{
+		lights: lights,
+		errs:   make(map[string]error),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// FailWith makes every later call to method return err. A nil err clears
+// the failure.
+func (f *FakeClient) FailWith(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// SetRandSource draws which light a ":random" selector picks and what a
+// random hue becomes from src, so that a seeded source gives the same run
+// every time.
+func (f *FakeClient) SetRandSource(src rand.Source) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rand = rand.New(src)
+}
+
+func (f *FakeClient) SetLights(lights ...lifx.Light) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lights = lights
+}
+
+func (f *FakeClient) Lights() []lifx.Light {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]lifx.Light(nil), f.lights...)
+}
+
+func (f *FakeClient) Light(id string) (lifx.Light, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, l := range f.lights {
+		if l.Id == id {
+			return l, true
+		}
+	}
+	return lifx.Light{}, false
+}
+
+func (f *FakeClient) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call(nil), f.calls...)
+}
+
+func (f *FakeClient) CallsTo(method string) []Call {
+	var calls []Call
+
+	for _, c := range f.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func (f *FakeClient) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}
+
+// record logs the call and returns the lights selector matches, or the
+// injected error for method.
+func (f *FakeClient) record(method, selector string, arg interface{}) ([]*lifx.Light, error) {
+	f.calls = append(f.calls, Call{Method: method, Selector: selector, Arg: arg})
+
+	if err, ok := f.errs[method]; ok {
+		return nil, err
+	}
+
+	lights := matching(f.lights, selector, f.rand)
+	if len(lights) == 0 {
+		return nil, ErrNoMatch
+	}
+	return lights, nil
+}
+
+func (f *FakeClient) ListLights(selector string) ([]lifx.Light, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lights, err := f.record("ListLights", selector, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]lifx.Light, len(lights))
+	for i, l := range lights {
+		out[i] = *l
+	}
+	return out, nil
+}
+
+func (f *FakeClient) SetState(selector string, s lifx.State) (*lifx.LifxResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lights, err := f.record("SetState", selector, s)
+	if err != nil {
+		return nil, err
+	}
+
+	st := state{Power: s.Power, Brightness: s.Brightness, Duration: s.Duration, Infrared: s.Infrared, Fast: s.Fast}
+	if s.Color != nil {
+		st.Color = s.Color.ColorString()
+	}
+
+	for _, l := range lights {
+		if err = apply(l, st, f.rand); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Fast {
+		return nil, nil
+	}
+	return response(lights), nil
+}
+
+func (f *FakeClient) Toggle(selector string, duration float64) (*lifx.LifxResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lights, err := f.record("Toggle", selector, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lights {
+		if l.Power == "on" {
+			l.Power = "off"
+		} else {
+			l.Power = "on"
+		}
+	}
+
+	r := response(lights)
+	for i, l := range lights {
+		if r.Results[i].Status == lifx.OK {
+			r.Results[i].Power = l.Power
+		}
+	}
+	return r, nil
+}
+
+func (f *FakeClient) PowerOn(selector string) (*lifx.LifxResponse, error) {
+	return f.power("PowerOn", selector, "on")
+}
+
+func (f *FakeClient) PowerOff(selector string) (*lifx.LifxResponse, error) {
+	return f.power("PowerOff", selector, "off")
+}
+
+func (f *FakeClient) power(method, selector, power string) (*lifx.LifxResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	lights, err := f.record(method, selector, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lights {
+		l.Power = power
+	}
+	return response(lights), nil
+}
+
+func (f *FakeClient) Breathe(selector string, breathe lifx.Breathe) (*lifx.LifxResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := breathe.Valid(); err != nil {
+		return nil, err
+	}
+
+	lights, err := f.record("Breathe", selector, breathe)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lights {
+		l.Effect = "BREATHE"
+		if breathe.PowerOn {
+			l.Power = "on"
+		}
+	}
+	return response(lights), nil
+}
+
+func result(l *lifx.Light) lifx.Result {
+	status := lifx.OK
+	if !l.Connected {
+		status = lifx.Offline
+	}
+	return lifx.Result{Id: l.Id, Label: l.Label, Status: status}
+}
+
+func response(lights []*lifx.Light) *lifx.LifxResponse {
+	results := make([]lifx.Result, len(lights))
+	for i, l := range lights {
+		results[i] = result(l)
+	}
+	return &lifx.LifxResponse{Results: results}
+}
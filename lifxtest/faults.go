@@ -0,0 +1,127 @@
+package lifxtest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+// Faults makes a Server misbehave the way the real API sometimes does.
+// Rates are probabilities between 0 and 1 rolled independently for every
+// request, or for every light in the case of TimeoutRate.
+type Faults struct {
+	Latency       time.Duration
+	Jitter        time.Duration
+	ErrorRate     float64
+	RateLimitRate float64
+	TimeoutRate   float64
+}
+
+var serverErrors = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	523,
+}
+
+// SetRandSource draws the server's random choices, which faults to inject,
+// which light a ":random" selector picks and what a random hue becomes,
+// from src, so that a seeded source gives the same run every time.
+func (s *Server) SetRandSource(src rand.Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rand = rand.New(src)
+}
+
+func (s *Server) SetFaults(f Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = f
+}
+
+// SetStatus forces the status the light reports. Offline lights are also
+// marked disconnected; OK restores normal behavior. Lights that are not OK
+// ignore state changes.
+func (s *Server) SetStatus(id string, status lifx.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.lights {
+		if s.lights[i].Id == id {
+			s.lights[i].Connected = status != lifx.Offline
+		}
+	}
+
+	if status == lifx.OK || status == lifx.Offline {
+		delete(s.statuses, id)
+		return
+	}
+	s.statuses[id] = status
+}
+
+// injectFault delays the request and may answer it with an error instead
+// of handling it, reporting whether it did.
+func (s *Server) injectFault(w http.ResponseWriter) bool {
+	s.mu.Lock()
+	f := s.faults
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(s.rand.Int63n(int64(f.Jitter)))
+	}
+	limited := f.RateLimitRate > 0 && s.rand.Float64() < f.RateLimitRate
+	failed := f.ErrorRate > 0 && s.rand.Float64() < f.ErrorRate
+	code := serverErrors[s.rand.Intn(len(serverErrors))]
+	s.mu.Unlock()
+
+	time.Sleep(delay)
+
+	switch {
+	case limited:
+		w.Header().Set("X-RateLimit-Limit", "120")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Minute).Unix()))
+		writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+		return true
+	case failed:
+		writeError(w, code, "Something went wrong")
+		return true
+	}
+	return false
+}
+
+// each applies fn to every light that can currently be reached and
+// reports a result per light, so unreachable lights show up as partial
+// failures rather than failing the whole request.
+func (s *Server) each(lights []*lifx.Light, fn func(*lifx.Light) error) ([]lifx.Result, error) {
+	results := make([]lifx.Result, 0, len(lights))
+
+	for _, l := range lights {
+		status := s.status(l)
+		if status == lifx.OK {
+			if err := fn(l); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, lifx.Result{Id: l.Id, Label: l.Label, Status: status})
+	}
+
+	return results, nil
+}
+
+func (s *Server) status(l *lifx.Light) lifx.Status {
+	if status, ok := s.statuses[l.Id]; ok {
+		return status
+	}
+	if !l.Connected {
+		return lifx.Offline
+	}
+	if s.faults.TimeoutRate > 0 && s.rand.Float64() < s.faults.TimeoutRate {
+		return lifx.TimedOut
+	}
+	return lifx.OK
+}
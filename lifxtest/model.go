@@ -0,0 +1,196 @@
+package lifxtest
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"math/rand"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	Scene struct {
+		UUID   string       `json:"uuid"`
+		Name   string       `json:"name"`
+		States []SceneState `json:"states"`
+	}
+
+	SceneState struct {
+		Selector   string  `json:"selector"`
+		Power      string  `json:"power,omitempty"`
+		Color      string  `json:"color,omitempty"`
+		Brightness float64 `json:"brightness,omitempty"`
+	}
+
+	// state mirrors lifx.State with the color kept as the string the
+	// client sent, since lifx.Color is an interface and cannot be decoded.
+	state struct {
+		Power      string  `json:"power,omitempty"`
+		Color      string  `json:"color,omitempty"`
+		Brightness float64 `json:"brightness,omitempty"`
+		Duration   float64 `json:"duration,omitempty"`
+		Infrared   float64 `json:"infrared,omitempty"`
+		Fast       bool    `json:"fast,omitempty"`
+	}
+
+	stateWithSelector struct {
+		state
+		Selector string `json:"selector"`
+	}
+
+	states struct {
+		States   []stateWithSelector `json:"states"`
+		Defaults state               `json:"defaults"`
+	}
+)
+
+// NewLight returns a connected color bulb that is switched off, ready to be
+// added to a Server.
+func NewLight(id, label, group, location string) lifx.Light {
+	return lifx.Light{
+		Id:        id,
+		UUID:      id,
+		Label:     label,
+		Connected: true,
+		Power:     "off",
+		Color: lifx.HSBKColor{
+			H: lifx.Float32Ptr(0),
+			S: lifx.Float32Ptr(0),
+			B: lifx.Float32Ptr(1),
+			K: lifx.Int16Ptr(3500),
+		},
+		Brightness: 1,
+		Effect:     "OFF",
+		Group:      lifx.Selector{Id: selectorId("group", group), Name: group},
+		Location:   lifx.Selector{Id: selectorId("location", location), Name: location},
+		Product: lifx.Product{
+			Name:       "LIFX Color",
+			Identifier: "lifx_color",
+			Company:    "LIFX",
+			VendorId:   1,
+			ProductId:  91,
+			Capabilities: lifx.Capabilities{
+				HasColor:             true,
+				HasVariableColorTemp: true,
+				MinKelvin:            1500,
+				MaxKelvin:            9000,
+			},
+		},
+	}
+}
+
+// selectorId derives a stable 32 character hex id from a group or
+// location name, the same shape the API and the LAN protocol use.
+func selectorId(kind, name string) string {
+	sum := md5.Sum([]byte(kind + ":" + name))
+	return hex.EncodeToString(sum[:])
+}
+
+// matching resolves selector against lights as the API does, with each
+// ":random" part picking one of the lights it matches from r.
+func matching(lights []lifx.Light, selector string, r *rand.Rand) []*lifx.Light {
+	var matched []*lifx.Light
+
+	parts, err := lifx.ParseSelector(selector)
+	if err != nil {
+		return nil
+	}
+
+	picked := make(map[int]bool)
+	for _, p := range parts {
+		var candidates []int
+		for i := range lights {
+			if p.Match(lights[i]) {
+				candidates = append(candidates, i)
+			}
+		}
+		if p.Random && len(candidates) > 0 {
+			candidates = candidates[r.Intn(len(candidates)):][:1]
+		}
+		for _, i := range candidates {
+			picked[i] = true
+		}
+	}
+
+	for i := range lights {
+		if picked[i] {
+			matched = append(matched, &lights[i])
+		}
+	}
+	return matched
+}
+
+// apply sets l to s, with a random hue drawn from r.
+func apply(l *lifx.Light, s state, r *rand.Rand) error {
+	if s.Color != "" {
+		c, err := lifx.ParseColor(s.Color)
+		if err != nil {
+			return err
+		}
+		if c.RandomHue {
+			c.H = lifx.Float32Ptr(float32(r.Float64() * 360))
+		}
+		if c.H != nil {
+			l.Color.H = c.H
+		}
+		if c.S != nil {
+			l.Color.S = c.S
+		}
+		if c.K != nil {
+			l.Color.K = c.K
+		}
+		if c.B != nil {
+			setBrightness(l, float64(*c.B))
+		}
+	}
+
+	if s.Brightness != 0 {
+		setBrightness(l, s.Brightness)
+	}
+
+	if s.Power != "" {
+		l.Power = s.Power
+	}
+
+	return nil
+}
+
+func applyDelta(l *lifx.Light, d lifx.StateDelta) {
+	if d.Power != nil {
+		l.Power = *d.Power
+	}
+	if d.Hue != nil {
+		h := float32(*d.Hue) + *l.Color.H
+		for h < 0 {
+			h += 360
+		}
+		for h >= 360 {
+			h -= 360
+		}
+		l.Color.H = lifx.Float32Ptr(h)
+	}
+	if d.Saturation != nil {
+		l.Color.S = lifx.Float32Ptr(clamp32(*l.Color.S+float32(*d.Saturation), 0, 1))
+	}
+	if d.Brightness != nil {
+		setBrightness(l, float64(clamp32(float32(l.Brightness+*d.Brightness), 0, 1)))
+	}
+	if d.Kelvin != nil {
+		l.Color.K = lifx.Int16Ptr(int16(clamp32(float32(int(*l.Color.K)+*d.Kelvin), 1500, 9000)))
+	}
+}
+
+func setBrightness(l *lifx.Light, b float64) {
+	l.Brightness = b
+	l.Color.B = lifx.Float32Ptr(float32(b))
+}
+
+func clamp32(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
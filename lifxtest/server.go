@@ -0,0 +1,389 @@
+package lifxtest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type Server struct {
+	*httptest.Server
+
+	// Token, when set, is the only access token the server accepts.
+	Token string
+
+	mu       sync.Mutex
+	lights   []lifx.Light
+	scenes   []Scene
+	faults   Faults
+	statuses map[string]lifx.Status
+	rand     *rand.Rand
+}
+
+// NewServer starts a fake of the LIFX HTTP API serving the given lights.
+// Point a client at it with WithServer.
+func NewServer(lights ...lifx.Light) *Server {
+	s := NewUnstartedServer(lights...)
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a Server that is not yet listening, so that
+// its Listener can be replaced before calling Start.
+func NewUnstartedServer(lights ...lifx.Light) *Server {
+	s := &Server{
+		lights:   lights,
+		statuses: make(map[string]lifx.Status),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// WithServer is a client option that routes every request the client makes
+// to s, regardless of the endpoint it was built for.
+func WithServer(s *Server) func(*lifx.Client) {
+	return func(c *lifx.Client) {
+		c.Client = s.HTTPClient()
+	}
+}
+
+func (s *Server) HTTPClient() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: &rewriter{target: target, next: http.DefaultTransport}}
+}
+
+func (s *Server) AddLight(l lifx.Light) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lights = append(s.lights, l)
+}
+
+func (s *Server) AddScene(scene Scene) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenes = append(s.scenes, scene)
+}
+
+// Lights returns a copy of the current state of every light.
+func (s *Server) Lights() []lifx.Light {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]lifx.Light(nil), s.lights...)
+}
+
+func (s *Server) Light(id string) (lifx.Light, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, l := range s.lights {
+		if l.Id == id {
+			return l, true
+		}
+	}
+	return lifx.Light{}, false
+}
+
+// UpdateLight applies fn to the light with the given id while holding the
+// server's lock, reporting whether the light exists.
+func (s *Server) UpdateLight(id string, fn func(*lifx.Light)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.lights {
+		if s.lights[i].Id == id {
+			fn(&s.lights[i])
+			return true
+		}
+	}
+	return false
+}
+
+type rewriter struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t *rewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	r.Host = t.target.Host
+	return t.next.RoundTrip(r)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	if s.injectFault(w) {
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	parts := strings.Split(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case path == "lights/states" && r.Method == http.MethodPut:
+		s.setStates(w, r)
+	case len(parts) == 2 && parts[0] == "lights" && r.Method == http.MethodGet:
+		s.listLights(w, parts[1])
+	case len(parts) == 3 && parts[0] == "lights" && parts[2] == "state" && r.Method == http.MethodPut:
+		s.setState(w, r, parts[1])
+	case len(parts) == 4 && parts[0] == "lights" && path == "lights/"+parts[1]+"/state/delta" && r.Method == http.MethodPost:
+		s.stateDelta(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "lights" && parts[2] == "toggle" && r.Method == http.MethodPost:
+		s.toggle(w, parts[1])
+	case len(parts) == 4 && parts[0] == "lights" && parts[2] == "effects" && r.Method == http.MethodPost:
+		s.effect(w, parts[1], parts[3])
+	case path == "scenes" && r.Method == http.MethodGet:
+		s.listScenes(w)
+	case len(parts) == 3 && parts[0] == "scenes" && parts[2] == "activate" && r.Method == http.MethodPut:
+		s.activateScene(w, parts[1])
+	case path == "color" && r.Method == http.MethodGet:
+		s.validateColor(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) matching(selector string) []*lifx.Light {
+	return matching(s.lights, selector, s.rand)
+}
+
+func (s *Server) match(w http.ResponseWriter, selector string) []*lifx.Light {
+	lights := s.matching(selector)
+	if len(lights) == 0 {
+		writeError(w, http.StatusNotFound, "Could not find light with "+selector)
+	}
+	return lights
+}
+
+func (s *Server) listLights(w http.ResponseWriter, selector string) {
+	lights := s.match(w, selector)
+	if lights == nil {
+		return
+	}
+
+	out := make([]lifx.Light, len(lights))
+	for i, l := range lights {
+		out[i] = *l
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) setState(w http.ResponseWriter, r *http.Request, selector string) {
+	var st state
+
+	if err := json.NewDecoder(r.Body).Decode(&st); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	lights := s.match(w, selector)
+	if lights == nil {
+		return
+	}
+
+	results, err := s.each(lights, func(l *lifx.Light) error { return apply(l, st, s.rand) })
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if st.Fast {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	writeResults(w, results)
+}
+
+func (s *Server) setStates(w http.ResponseWriter, r *http.Request) {
+	var (
+		st      states
+		results []lifx.OperationResult
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(&st); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	for _, op := range st.States {
+		merged := st.Defaults
+		if op.Power != "" {
+			merged.Power = op.Power
+		}
+		if op.Color != "" {
+			merged.Color = op.Color
+		}
+		if op.Brightness != 0 {
+			merged.Brightness = op.Brightness
+		}
+
+		r, err := s.each(s.matching(op.Selector), func(l *lifx.Light) error { return apply(l, merged, s.rand) })
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		results = append(results, lifx.OperationResult{
+			Operation: lifx.Operation{
+				Selector:   op.Selector,
+				Power:      merged.Power,
+				Color:      merged.Color,
+				Brightness: merged.Brightness,
+				Duration:   merged.Duration,
+				Infrared:   merged.Infrared,
+				Fast:       merged.Fast,
+			},
+			Results: r,
+		})
+	}
+
+	writeJSON(w, http.StatusMultiStatus, lifx.SetStatesResponse{Results: results})
+}
+
+func (s *Server) stateDelta(w http.ResponseWriter, r *http.Request, selector string) {
+	var d lifx.StateDelta
+
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	lights := s.match(w, selector)
+	if lights == nil {
+		return
+	}
+
+	results, _ := s.each(lights, func(l *lifx.Light) error {
+		applyDelta(l, d)
+		return nil
+	})
+	writeResults(w, results)
+}
+
+func (s *Server) toggle(w http.ResponseWriter, selector string) {
+	lights := s.match(w, selector)
+	if lights == nil {
+		return
+	}
+
+	results, _ := s.each(lights, func(l *lifx.Light) error {
+		if l.Power == "on" {
+			l.Power = "off"
+		} else {
+			l.Power = "on"
+		}
+		return nil
+	})
+	for i, l := range lights {
+		if results[i].Status == lifx.OK {
+			results[i].Power = l.Power
+		}
+	}
+	writeResults(w, results)
+}
+
+func (s *Server) effect(w http.ResponseWriter, selector, name string) {
+	lights := s.match(w, selector)
+	if lights == nil {
+		return
+	}
+
+	results, _ := s.each(lights, func(l *lifx.Light) error {
+		l.Effect = lifx.Effect(strings.ToUpper(name))
+		return nil
+	})
+	writeResults(w, results)
+}
+
+// listScenes answers with the scenes in the API's form, which has colors
+// as objects rather than the strings a Scene is given.
+func (s *Server) listScenes(w http.ResponseWriter) {
+	scenes := make([]lifx.Scene, len(s.scenes))
+	for i, sc := range s.scenes {
+		scenes[i] = lifx.Scene{UUID: sc.UUID, Name: sc.Name, States: make([]lifx.SceneState, len(sc.States))}
+		for j, ss := range sc.States {
+			scenes[i].States[j] = lifx.SceneState{Selector: ss.Selector, Power: ss.Power, Brightness: ss.Brightness}
+			if ss.Color != "" {
+				c, err := lifx.ParseColor(ss.Color)
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				scenes[i].States[j].Color = c
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, scenes)
+}
+
+func (s *Server) activateScene(w http.ResponseWriter, selector string) {
+	var (
+		scene   *Scene
+		results []lifx.Result
+	)
+
+	uuid := strings.TrimPrefix(selector, "scene_id:")
+	for i := range s.scenes {
+		if s.scenes[i].UUID == uuid {
+			scene = &s.scenes[i]
+		}
+	}
+	if scene == nil {
+		writeError(w, http.StatusNotFound, "Could not find scene with "+selector)
+		return
+	}
+
+	for _, ss := range scene.States {
+		st := state{Power: ss.Power, Color: ss.Color, Brightness: ss.Brightness}
+		r, err := s.each(s.matching(ss.Selector), func(l *lifx.Light) error { return apply(l, st, s.rand) })
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		results = append(results, r...)
+	}
+
+	writeResults(w, results)
+}
+
+func (s *Server) validateColor(w http.ResponseWriter, r *http.Request) {
+	c, err := lifx.ParseColor(r.URL.Query().Get("string"))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func writeResults(w http.ResponseWriter, results []lifx.Result) {
+	writeJSON(w, http.StatusMultiStatus, lifx.LifxResponse{Results: results})
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, lifx.LifxResponse{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -4,7 +4,10 @@ import (
 	//"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,9 +17,29 @@ const (
 	Offline  Status = "offline"
 )
 
+// The effects a light can report running.
+const (
+	EffectNone    Effect = "OFF"
+	EffectBreathe Effect = "BREATHE"
+	EffectPulse   Effect = "PULSE"
+	EffectMove    Effect = "MOVE"
+	EffectMorph   Effect = "MORPH"
+	EffectFlame   Effect = "FLAME"
+	EffectClouds  Effect = "CLOUDS"
+	EffectSunrise Effect = "SUNRISE"
+	EffectSunset  Effect = "SUNSET"
+)
+
 type (
+	// Status is how a light fared in a call. Statuses the package does
+	// not know are kept as the API sent them, so compare with the Is
+	// methods rather than ==, which also ignore case.
 	Status string
 
+	// Effect is the effect a light is running. Newer API responses
+	// describe it as an object, of which only the type is kept.
+	Effect string
+
 	Selector struct {
 		Id   string `json:"id"`
 		Name string `json:"name"`
@@ -26,6 +49,8 @@ type (
 		Name         string       `json:"name"`
 		Identifier   string       `json:"identifier"`
 		Company      string       `json:"company"`
+		VendorId     int          `json:"vendor_id"`
+		ProductId    int          `json:"product_id"`
 		Capabilities Capabilities `json:"capabilities"`
 	}
 
@@ -33,7 +58,9 @@ type (
 		HasColor             bool    `json:"has_color"`
 		HasVariableColorTemp bool    `json:"has_variable_color_temp"`
 		HasIR                bool    `json:"has_ir"`
+		HasHEV               bool    `json:"has_hev"`
 		HasChain             bool    `json:"has_chain"`
+		HasMatrix            bool    `json:"has_matrix"`
 		HasMultizone         bool    `json:"has_multizone"`
 		MinKelvin            float64 `json:"min_kelvin"`
 		MaxKelvin            float64 `json:"max_kelvin"`
@@ -47,12 +74,39 @@ type (
 		Power           string    `json:"power"`
 		Color           HSBKColor `json:"color"`
 		Brightness      float64   `json:"brightness"`
-		Effect          string    `json:"effect"`
+		Effect          Effect    `json:"effect"`
 		Group           Selector  `json:"group"`
 		Location        Selector  `json:"location"`
 		Product         Product   `json:"product"`
+		Zones           *Zones    `json:"zones,omitempty"`
+		Chain           *Chain    `json:"chain,omitempty"`
 		LastSeen        time.Time `json:"last_seen"`
-		SecondsLastSeen float64   `json:"seconds_last_seen"`
+		SecondsLastSeen float64   `json:"seconds_since_seen"`
+	}
+
+	Zones struct {
+		Count int    `json:"count"`
+		Zones []Zone `json:"zones"`
+	}
+
+	Zone struct {
+		Zone       int     `json:"zone"`
+		Hue        float32 `json:"hue"`
+		Saturation float32 `json:"saturation"`
+		Brightness float32 `json:"brightness"`
+		Kelvin     int16   `json:"kelvin"`
+	}
+
+	Chain struct {
+		Children []ChainChild `json:"children"`
+	}
+
+	ChainChild struct {
+		Index  int     `json:"index"`
+		UserX  float64 `json:"user_x"`
+		UserY  float64 `json:"user_y"`
+		Width  int     `json:"width"`
+		Height int     `json:"height"`
 	}
 
 	State struct {
@@ -84,10 +138,39 @@ type (
 		Defaults State               `json:"defaults,omitempty"`
 	}
 
+	// Operation is one of the states sent to SetStates as the API echoes
+	// it back, with the color as the string the API was given.
+	Operation struct {
+		Selector   string  `json:"selector"`
+		Power      string  `json:"power,omitempty"`
+		Color      string  `json:"color,omitempty"`
+		Brightness float64 `json:"brightness,omitempty"`
+		Duration   float64 `json:"duration,omitempty"`
+		Infrared   float64 `json:"infrared,omitempty"`
+		Fast       bool    `json:"fast,omitempty"`
+	}
+
+	OperationResult struct {
+		Operation Operation `json:"operation"`
+		Results   []Result  `json:"results"`
+	}
+
+	// SetStatesResponse has one result per state sent, in the order they
+	// were sent.
+	SetStatesResponse struct {
+		Error    string            `json:"error"`
+		Errors   []Error           `json:"errors"`
+		Warnings []Warning         `json:"warnings"`
+		Results  []OperationResult `json:"results"`
+	}
+
 	Toggle struct {
 		Duration float64 `json:"duration,omitempty"`
 	}
 
+	// Breathe fades between Color and FromColor Cycles times, each taking
+	// Period seconds. Without a FromColor, or with FromCurrent, it starts
+	// from each light's current color.
 	Breathe struct {
 		Color     Color   `json:"color,omitempty"`
 		FromColor Color   `json:"from_color,omitempty"`
@@ -117,127 +200,460 @@ func NewBreathe() Breathe {
 	return b
 }
 
-func (b *Breathe) Valid() error {
-	if b.Peak < 0 || b.Peak > 1 {
-		return errors.New("peak must be between 0.0 and 1.0")
+// ClampKelvin brings k into the product's kelvin range. Products that do
+// not report a range leave k alone.
+func (c Capabilities) ClampKelvin(k int16) int16 {
+	if c.MinKelvin == 0 && c.MaxKelvin == 0 {
+		return k
 	}
-	return nil
+	return int16(clamp(float64(k), c.MinKelvin, c.MaxKelvin))
 }
 
-func (c *Client) SetState(selector string, state State) (*LifxResponse, error) {
-	var (
-		err  error
-		s    *LifxResponse
-		resp *Response
-	)
-
-	if resp, err = c.setState(selector, state); err != nil {
-		return nil, err
+func (z Zone) Color() HSBKColor {
+	return HSBKColor{
+		H: Float32Ptr(z.Hue),
+		S: Float32Ptr(z.Saturation),
+		B: Float32Ptr(z.Brightness),
+		K: Int16Ptr(z.Kelvin),
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.IsError() {
-		return nil, resp.GetLifxError()
+// IsEffectRunning reports whether the light is running any effect.
+func (l Light) IsEffectRunning() bool {
+	return l.Effect.Running()
+}
+
+// ToState returns the state that puts a light back the way l is, for
+// restoring a snapshot or copying one light onto another. Brightness is
+// carried outside the color, as the API reports it.
+func (l Light) ToState() State {
+	c := l.Color
+	c.B = nil
+
+	return State{
+		Power:      l.Power,
+		Color:      c,
+		Brightness: l.Brightness,
 	}
+}
 
-	if state.Fast && resp.StatusCode == http.StatusAccepted {
-		return nil, nil
+// StaleFor is how long ago the API last heard from the light. It goes by
+// LastSeen, so it keeps growing as the Light ages, and falls back to
+// SecondsLastSeen when LastSeen is missing.
+func (l Light) StaleFor() time.Duration {
+	if l.LastSeen.IsZero() {
+		return time.Duration(l.SecondsLastSeen * float64(time.Second))
+	}
+	// The API's clock may be ahead of ours.
+	if d := time.Since(l.LastSeen); d > 0 {
+		return d
 	}
+	return 0
+}
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, err
+// SeenWithin reports whether the API heard from the light within d.
+func (l Light) SeenWithin(d time.Duration) bool {
+	return l.StaleFor() <= d
+}
+
+func (e Effect) Running() bool {
+	return e != "" && e != EffectNone
+}
+
+func (e Effect) String() string {
+	return string(e)
+}
+
+// IsKnown reports whether s is one of OK, TimedOut and Offline.
+func (s Status) IsKnown() bool {
+	switch s.normal() {
+	case OK, TimedOut, Offline:
+		return true
 	}
+	return false
+}
+
+func (s Status) IsOK() bool       { return s.normal() == OK }
+func (s Status) IsTimedOut() bool { return s.normal() == TimedOut }
+func (s Status) IsOffline() bool  { return s.normal() == Offline }
 
-	return s, nil
+func (s Status) normal() Status {
+	return Status(strings.ToLower(strings.TrimSpace(string(s))))
 }
 
-func (c *Client) FastSetState(selector string, state State) (*LifxResponse, error) {
-	state.Fast = true
-	return c.SetState(selector, state)
+// UnmarshalJSON keeps a status that is not a string as its raw JSON,
+// rather than failing the whole response over it.
+func (s *Status) UnmarshalJSON(b []byte) error {
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		*s = Status(b)
+		return nil
+	}
+	*s = Status(v)
+	return nil
 }
 
-func (c *Client) SetStates(selector string, states States) (*LifxResponse, error) {
+func (e *Effect) UnmarshalJSON(b []byte) error {
 	var (
-		err  error
-		s    *LifxResponse
-		resp *Response
+		s   string
+		obj struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+		}
 	)
 
-	if resp, err = c.setStates(selector, states); err != nil {
-		return nil, err
+	if err := json.Unmarshal(b, &s); err == nil {
+		*e = Effect(strings.ToUpper(s))
+		return nil
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	if obj.Type == "" {
+		obj.Type = obj.Name
 	}
-	defer resp.Body.Close()
+	*e = Effect(strings.ToUpper(obj.Type))
+	return nil
+}
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, err
+func (s State) MarshalJSON() ([]byte, error) {
+	type state State
+	return json.Marshal(struct {
+		state
+		Color string `json:"color,omitempty"`
+	}{state(s), colorString(s.Color)})
+}
+
+// MarshalJSON stops State's from being promoted, which would drop the
+// selector.
+func (s StateWithSelector) MarshalJSON() ([]byte, error) {
+	type state State
+	return json.Marshal(struct {
+		state
+		Color    string `json:"color,omitempty"`
+		Selector string `json:"selector"`
+	}{state(s.State), colorString(s.Color), s.Selector})
+}
+
+func (b Breathe) MarshalJSON() ([]byte, error) {
+	type breathe Breathe
+	return json.Marshal(struct {
+		breathe
+		Color     string `json:"color,omitempty"`
+		FromColor string `json:"from_color,omitempty"`
+	}{breathe(b), colorString(b.Color), colorString(b.FromColor)})
+}
+
+func (b *Breathe) Valid() error {
+	if b.Color == nil {
+		return errors.New("breathe needs a color")
 	}
+	if !(b.Peak >= 0 && b.Peak <= 1) {
+		return errors.New("peak must be between 0.0 and 1.0")
+	}
+	if err := validFromColor("breathe", b.FromColor); err != nil {
+		return err
+	}
+	return validTiming("breathe", b.Period, b.Cycles, 0)
+}
+
+// MaxStates is the most states the API accepts in one SetStates request.
+// SetStates splits larger batches itself.
+const MaxStates = 50
+
+// ErrNoInfrared is returned by CheckedSetInfrared for lights without
+// infrared support.
+var ErrNoInfrared = errors.New("light does not support infrared")
 
-	return s, nil
+func (c *Client) SetState(selector string, state State) (*LifxResponse, error) {
+	if c.fast {
+		state.Fast = true
+	}
+	state.Duration = c.defaultDuration(state.Duration)
+	state = c.brightnessFloor(state, state.Power)
+	if c.clampK {
+		if r, ok, err := c.setStateClamped(selector, state); ok {
+			return r, err
+		}
+	}
+	return doRequest[*LifxResponse](c, http.MethodPut, EndpointState(selector), state)
 }
 
-func (c *Client) StateDelta(selector string, delta StateDelta) (*LifxResponse, error) {
+// brightnessFloor applies the client's brightness floor to s, which is
+// left with the given power.
+func (c *Client) brightnessFloor(s State, power string) State {
+	if c.floor <= 0 || power == "off" {
+		return s
+	}
+
+	if s.Brightness != 0 && s.Brightness < c.floor {
+		s.Brightness = c.floor
+	}
+	if s.Color != nil {
+		if color, err := ParseColor(s.Color.ColorString()); err == nil && color.B != nil && float64(*color.B) < c.floor {
+			color.B = Float32Ptr(float32(c.floor))
+			s.Color = color
+		}
+	}
+	return s
+}
+
+// setStateClamped sends state with its kelvin clamped to each selected
+// light's range. It reports false, sending nothing, when the kelvin needs
+// no clamping.
+func (c *Client) setStateClamped(selector string, state State) (*LifxResponse, bool, error) {
+	c = c.correlated()
+
 	var (
-		err  error
-		s    *LifxResponse
-		resp *Response
+		states States
+		ids    = make(map[int16][]SelectorPart)
+		order  []int16
 	)
 
-	if resp, err = c.stateDelta(selector, delta); err != nil {
-		return nil, err
+	if state.Color == nil {
+		return nil, false, nil
+	}
+	color, err := ParseColor(state.Color.ColorString())
+	if err != nil || color.K == nil {
+		return nil, false, nil
 	}
-	defer resp.Body.Close()
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, err
+	lights, err := c.Products(selector)
+	if err != nil {
+		return nil, true, err
 	}
 
-	return s, nil
+	clamped := false
+	for _, l := range lights {
+		k := l.Product.Capabilities.ClampKelvin(*color.K)
+		if k != *color.K {
+			clamped = true
+		}
+		if _, ok := ids[k]; !ok {
+			order = append(order, k)
+		}
+		ids[k] = append(ids[k], ById(l.Id))
+	}
+	if !clamped {
+		return nil, false, nil
+	}
+
+	for _, k := range order {
+		selectors, err := BatchSelectors(ids[k]...)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, s := range selectors {
+			st, col := state, color
+			col.K = Int16Ptr(k)
+			st.Color = col
+			states.States = append(states.States, StateWithSelector{State: st, Selector: s})
+		}
+	}
+
+	r, err := c.SetStates("", states)
+	if r == nil {
+		return nil, true, err
+	}
+	return &LifxResponse{Error: r.Error, Errors: r.Errors, Warnings: r.Warnings, Results: r.AllResults()}, true, err
 }
 
-func (c *Client) Toggle(selector string, duration float64) (*LifxResponse, error) {
+func (c *Client) FastSetState(selector string, state State) (*LifxResponse, error) {
+	state.Fast = true
+	return c.SetState(selector, state)
+}
+
+func (c *Client) SetStates(selector string, states States) (*SetStatesResponse, error) {
+	if c.fast {
+		states.Defaults.Fast = true
+	}
+	states.Defaults.Duration = c.defaultDuration(states.Defaults.Duration)
+	if c.floor > 0 {
+		states.Defaults = c.brightnessFloor(states.Defaults, states.Defaults.Power)
+		states.States = append([]StateWithSelector(nil), states.States...)
+		for i, s := range states.States {
+			power := s.Power
+			if power == "" {
+				power = states.Defaults.Power
+			}
+			states.States[i].State = c.brightnessFloor(s.State, power)
+		}
+	}
+	if len(states.States) <= MaxStates {
+		return doRequest[*SetStatesResponse](c, http.MethodPut, EndpointStates(), states)
+	}
+	return c.setStatesChunked(states)
+}
+
+// setStatesChunked sends states MaxStates at a time, as many at once as
+// the client allows, and merges the responses in the order the states
+// were given. Every chunk is sent even when one fails, so that as many
+// lights as possible change; the first error is returned with whatever
+// results came back.
+func (c *Client) setStatesChunked(states States) (*SetStatesResponse, error) {
+	c = c.correlated()
+
+	var chunks []States
+	for i := 0; i < len(states.States); i += MaxStates {
+		end := i + MaxStates
+		if end > len(states.States) {
+			end = len(states.States)
+		}
+		chunks = append(chunks, States{States: states.States[i:end], Defaults: states.Defaults})
+	}
+
 	var (
-		err  error
-		s    *LifxResponse
-		resp *Response
+		wg        sync.WaitGroup
+		responses = make([]*SetStatesResponse, len(chunks))
+		errs      = make([]error, len(chunks))
+		sem       = make(chan struct{}, c.statesConcurrency())
 	)
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk States) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			responses[i], errs[i] = doRequest[*SetStatesResponse](c, http.MethodPut, EndpointStates(), chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var merged *SetStatesResponse
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &SetStatesResponse{}
+		}
+		if merged.Error == "" {
+			merged.Error = r.Error
+		}
+		merged.Errors = append(merged.Errors, r.Errors...)
+		merged.Warnings = append(merged.Warnings, r.Warnings...)
+		merged.Results = append(merged.Results, r.Results...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
 
-	if resp, err = c.toggle(selector, duration); err != nil {
-		return nil, err
+// Operation finds the result for the state sent with selector.
+func (r *SetStatesResponse) Operation(selector string) (OperationResult, bool) {
+	if r == nil {
+		return OperationResult{}, false
+	}
+	for _, op := range r.Results {
+		if op.Operation.Selector == selector {
+			return op, true
+		}
 	}
-	defer resp.Body.Close()
+	return OperationResult{}, false
+}
 
-	if resp.IsError() {
-		return nil, resp.GetLifxError()
+// Failed lists the lights, across every operation, that did not apply
+// their state.
+func (r *SetStatesResponse) Failed() []Result {
+	return failed(r.AllResults())
+}
+
+func (r *SetStatesResponse) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// AllResults lists the results of every operation together.
+func (r *SetStatesResponse) AllResults() []Result {
+	var results []Result
+	if r == nil {
+		return nil
+	}
+	for _, op := range r.Results {
+		results = append(results, op.Results...)
 	}
+	return results
+}
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, err
+func (c *Client) StateDelta(selector string, delta StateDelta) (*LifxResponse, error) {
+	if delta.Duration == nil && c.duration != 0 {
+		delta.Duration = Float64Ptr(c.duration)
 	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointStateDelta(selector), delta)
+}
 
-	return s, nil
+func (c *Client) Toggle(selector string, duration float64) (*LifxResponse, error) {
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointToggle(selector), Toggle{Duration: c.defaultDuration(duration)})
 }
 
-func (c *Client) ListLights(selector string) ([]Light, error) {
-	var (
-		err  error
-		s    []Light
-		resp *Response
-	)
+// defaultDuration is d, or the client's default duration if d is unset.
+func (c *Client) defaultDuration(d float64) float64 {
+	if d == 0 {
+		return c.duration
+	}
+	return d
+}
 
-	if resp, err = c.listLights(selector); err != nil {
-		return nil, err
+func (c *Client) ListLights(selector string) ([]Light, error) {
+	lights, err := doRequest[[]Light](c, http.MethodGet, EndpointListLights(selector), nil)
+	if err == nil && c.products != nil {
+		c.products.put(selector, lights, c.now())
 	}
-	defer resp.Body.Close()
+	return lights, err
+}
 
-	if resp.StatusCode > 299 {
-		return nil, resp.GetLifxError()
+// ListLightsBySelector lists the lights for each of selectors. When there
+// are several and all of them can be matched locally, it lists every light
+// once and sorts them out itself rather than asking for each selector; a
+// selector that matches nothing then gets no lights rather than an
+// error. Random and scene selectors are always asked for.
+func (c *Client) ListLightsBySelector(selectors []string) (map[string][]Light, error) {
+	m := make(map[string][]Light, len(selectors))
+	c = c.correlated()
+
+	if len(selectors) > 1 && partitionable(selectors) {
+		lights, err := c.ListLights("all")
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range selectors {
+			m[s] = []Light{}
+			for _, l := range lights {
+				if ok, _ := MatchSelector(s, l); ok {
+					m[s] = append(m[s], l)
+				}
+			}
+		}
+		return m, nil
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
-		return nil, err
+	for _, s := range selectors {
+		lights, err := c.ListLights(s)
+		if err != nil {
+			return nil, err
+		}
+		m[s] = lights
 	}
+	return m, nil
+}
 
-	return s, nil
+// partitionable reports whether every selector can be matched against a
+// list of lights without losing anything the API would do.
+func partitionable(selectors []string) bool {
+	for _, s := range selectors {
+		parts, err := ParseSelector(s)
+		if err != nil {
+			return false
+		}
+		for _, p := range parts {
+			if p.Random || p.Kind == "scene_id" {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 func (c *Client) PowerOff(selector string) (*LifxResponse, error) {
@@ -256,25 +672,119 @@ func (c *Client) FastPowerOn(selector string) {
 	c.SetState(selector, State{Power: "on", Fast: true})
 }
 
-func (c *Client) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
-	var (
-		err  error
-		s    *LifxResponse
-		resp *Response
-	)
+// SetBrightness sets the brightness of the selected lights, leaving their
+// power and color alone. A brightness of zero cannot be sent, since State
+// leaves zero values out; turn the lights off instead.
+func (c *Client) SetBrightness(selector string, level, duration float64) (*LifxResponse, error) {
+	if level <= 0 || level > 1 {
+		return nil, errors.New("brightness must be greater than 0.0 and at most 1.0")
+	}
+	return c.SetState(selector, State{Brightness: level, Duration: duration})
+}
+
+// DimBy lowers the brightness of the selected lights by delta, such as 0.1
+// for ten percent, each from its own current level.
+func (c *Client) DimBy(selector string, delta float64) (*LifxResponse, error) {
+	if err := validDelta(delta); err != nil {
+		return nil, err
+	}
+	return c.StateDelta(selector, StateDelta{Brightness: Float64Ptr(-delta)})
+}
+
+// BrightenBy raises the brightness of the selected lights by delta.
+func (c *Client) BrightenBy(selector string, delta float64) (*LifxResponse, error) {
+	if err := validDelta(delta); err != nil {
+		return nil, err
+	}
+	return c.StateDelta(selector, StateDelta{Brightness: Float64Ptr(delta)})
+}
+
+func validDelta(delta float64) error {
+	if delta < 0 || delta > 1 {
+		return errors.New("brightness change must be between 0.0 and 1.0")
+	}
+	return nil
+}
+
+// SetInfrared sets the maximum infrared level of the selected lights. A
+// level of zero cannot be sent as a state, so it is applied as a delta that
+// takes the level as far down as it goes.
+func (c *Client) SetInfrared(selector string, level float64) (*LifxResponse, error) {
+	if level < 0 || level > 1 {
+		return nil, errors.New("infrared must be between 0.0 and 1.0")
+	}
+	if level == 0 {
+		return c.StateDelta(selector, StateDelta{Infrared: Float64Ptr(-1)})
+	}
+	return c.SetState(selector, State{Infrared: level})
+}
 
-	if resp, err = c.breathe(selector, breathe); err != nil {
+// CheckedSetInfrared is SetInfrared, but first checks the products of the
+// selected lights and changes none of them if one has no infrared LEDs,
+// since the API would otherwise report success and do nothing.
+func (c *Client) CheckedSetInfrared(selector string, level float64) (*LifxResponse, error) {
+	if level < 0 || level > 1 {
+		return nil, errors.New("infrared must be between 0.0 and 1.0")
+	}
+
+	lights, err := c.Products(selector)
+	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	for _, l := range lights {
+		if !l.Product.Capabilities.HasIR {
+			return nil, fmt.Errorf("%w: %s is a %s", ErrNoInfrared, l.Label, l.Product.Name)
+		}
+	}
 
-	if resp.IsError() {
-		return nil, resp.GetLifxError()
+	return c.SetInfrared(selector, level)
+}
+
+// SetColorHSBK sets the selected lights to the given hue, saturation and
+// brightness. A kelvin of zero leaves the color temperature alone.
+func (c *Client) SetColorHSBK(selector string, h, s, b float32, k int16, duration float64) (*LifxResponse, error) {
+	color, err := NewHSBColor(h, s, b)
+	if err != nil {
+		return nil, err
 	}
+	if k != 0 {
+		if k < KelvinCandlelight || k > KelvinBlueIce {
+			return nil, errors.New("kelvin must be between 1500-9000")
+		}
+		color.K = Int16Ptr(k)
+	}
+	return c.SetState(selector, State{Color: color, Duration: duration})
+}
 
-	if err = json.NewDecoder(resp.Body).Decode(&s); err != nil {
+// SetColorHex sets the selected lights to a hex color such as "#ff8800".
+// The leading '#' is optional.
+func (c *Client) SetColorHex(selector, hex string, duration float64) (*LifxResponse, error) {
+	color, err := parseHex(strings.TrimPrefix(hex, "#"))
+	if err != nil {
 		return nil, err
 	}
+	return c.SetState(selector, State{Color: color, Duration: duration})
+}
+
+// SetRandomColor sets the selected lights to a hue the API picks at
+// random, at full saturation and keeping their brightness.
+func (c *Client) SetRandomColor(selector string, duration float64) (*LifxResponse, error) {
+	return c.SetState(selector, State{Color: NewRandom(), Duration: duration})
+}
+
+// SetKelvin sets the selected lights to white at the given color
+// temperature, keeping their brightness.
+func (c *Client) SetKelvin(selector string, kelvin int16, duration float64) (*LifxResponse, error) {
+	color, err := NewWhite(kelvin)
+	if err != nil {
+		return nil, err
+	}
+	return c.SetState(selector, State{Color: color, Duration: duration})
+}
 
-	return s, nil
+func (c *Client) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
+	if err := breathe.Valid(); err != nil {
+		return nil, err
+	}
+	return doRequest[*LifxResponse](c, http.MethodPost, EndpointBreathe(selector), breathe)
 }
@@ -0,0 +1,218 @@
+package lifx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go/internal/miniyaml"
+)
+
+// LocalScene is a scene defined in code or in a file rather than in the
+// LIFX app, which the API offers no way to create. Applying it sends its
+// states in one SetStates call, so later states win where selectors
+// overlap.
+type LocalScene struct {
+	Name   string              `json:"name"`
+	States []StateWithSelector `json:"states"`
+}
+
+// ParseLocalScene reads a scene from YAML. Each entry under states is
+// named and gives a selector with the state to put it in. YAML mappings
+// have no order, so entries are applied in order of their names, with
+// numbers compared by value: 2-desk comes before 10-porch.
+//
+//	name: evening
+//	states:
+//	  1-kitchen:
+//	    selector: group:Kitchen
+//	    power: on
+//	    color: kelvin:2700
+//	    brightness: 0.4
+//	  2-desk:
+//	    selector: label:Desk
+//	    power: off
+//	  10-porch:
+//	    selector: label:Porch
+//	    power: on
+func ParseLocalScene(r io.Reader) (LocalScene, error) {
+	var s LocalScene
+
+	doc, err := miniyaml.Parse(r)
+	if err != nil {
+		return s, err
+	}
+
+	for k, v := range doc {
+		switch k {
+		case "name":
+			name, ok := v.(string)
+			if !ok {
+				return s, errors.New("name must be a string")
+			}
+			s.Name = name
+		case "states":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return s, errors.New("states must be a mapping")
+			}
+			keys := make([]string, 0, len(m))
+			for key := range m {
+				keys = append(keys, key)
+			}
+			sort.Slice(keys, func(i, j int) bool { return naturalLess(keys[i], keys[j]) })
+
+			for _, key := range keys {
+				st, err := parseSceneEntry(m[key])
+				if err != nil {
+					return s, fmt.Errorf("state %s: %w", key, err)
+				}
+				s.States = append(s.States, st)
+			}
+		default:
+			return s, fmt.Errorf("unknown setting %q", k)
+		}
+	}
+
+	return s, s.Valid()
+}
+
+// LoadLocalScene reads the scene in the YAML file at path.
+func LoadLocalScene(path string) (LocalScene, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LocalScene{}, err
+	}
+	defer f.Close()
+
+	s, err := ParseLocalScene(f)
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func parseSceneEntry(v interface{}) (StateWithSelector, error) {
+	var st StateWithSelector
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return st, errors.New("must be a mapping")
+	}
+
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return st, fmt.Errorf("%s must be a value", k)
+		}
+
+		var err error
+		switch k {
+		case "selector":
+			st.Selector = s
+		case "power":
+			st.Power = s
+		case "color":
+			st.Color, err = ParseColor(s)
+		case "brightness":
+			st.Brightness, err = strconv.ParseFloat(s, 64)
+		case "infrared":
+			st.Infrared, err = strconv.ParseFloat(s, 64)
+		case "duration":
+			st.Duration, err = strconv.ParseFloat(s, 64)
+		default:
+			return st, fmt.Errorf("unknown setting %q", k)
+		}
+		if err != nil {
+			return st, fmt.Errorf("%s: %w", k, err)
+		}
+	}
+
+	return st, nil
+}
+
+func (s *LocalScene) Valid() error {
+	if len(s.States) == 0 {
+		return fmt.Errorf("scene '%s' has no states", s.Name)
+	}
+
+	for i, st := range s.States {
+		if st.Selector == "" {
+			return fmt.Errorf("scene '%s' state %d has no selector", s.Name, i)
+		}
+		// Zones are checked by the API; only the selector before them is
+		// parsed here.
+		sel, _, _ := strings.Cut(st.Selector, "|")
+		if _, err := ParseSelector(sel); err != nil {
+			return fmt.Errorf("scene '%s' state %d: %w", s.Name, i, err)
+		}
+
+		switch st.Power {
+		case "", "on", "off":
+		default:
+			return fmt.Errorf("scene '%s' state %d: power must be on or off, not '%s'", s.Name, i, st.Power)
+		}
+		if st.Brightness < 0 || st.Brightness > 1 {
+			return fmt.Errorf("scene '%s' state %d: brightness must be between 0 and 1", s.Name, i)
+		}
+	}
+	return nil
+}
+
+// ToStates compiles the scene into the states SetStates sends, with each
+// light fading over duration seconds unless its state gives its own.
+func (s LocalScene) ToStates(duration float64) States {
+	states := States{States: append([]StateWithSelector(nil), s.States...)}
+	states.Defaults.Duration = duration
+	return states
+}
+
+// ApplyLocalScene puts the lights in s's states over duration seconds.
+func (c *Client) ApplyLocalScene(s LocalScene, duration float64) (*SetStatesResponse, error) {
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+	return c.correlated().SetStates("", s.ToStates(duration))
+}
+
+// naturalLess orders a before b comparing runs of digits by their value,
+// so that "2-desk" sorts before "10-porch". Numbers of equal value but
+// different width, such as "02" and "2", fall back to byte order.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := digits(a), digits(b)
+		if da > 0 && db > 0 {
+			na := strings.TrimLeft(a[:da], "0")
+			nb := strings.TrimLeft(b[:db], "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			if a[:da] != b[:db] {
+				return a[:da] < b[:db]
+			}
+			a, b = a[da:], b[db:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// digits is the length of the run of ASCII digits s starts with.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package lifx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLocalSceneOrder(t *testing.T) {
+	s, err := ParseLocalScene(strings.NewReader(`name: evening
+states:
+  10-porch:
+    selector: label:Porch
+    power: on
+  2-desk:
+    selector: label:Desk
+    power: off
+  1-kitchen:
+    selector: group:Kitchen
+    power: on
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, st := range s.States {
+		got = append(got, st.Selector)
+	}
+	if want := "group:Kitchen,label:Desk,label:Porch"; strings.Join(got, ",") != want {
+		t.Errorf("states applied in order %v, want %s", got, want)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-desk", "10-porch", true},
+		{"10-porch", "2-desk", false},
+		{"desk", "porch", true},
+		{"a2", "a10", true},
+		{"a10b", "a10c", true},
+		{"02", "2", true},
+		{"2", "02", false},
+		{"kitchen", "kitchen-2", true},
+		{"same", "same", false},
+		{"9", "a", true},
+	}
+
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
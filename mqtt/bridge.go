@@ -0,0 +1,334 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	online  = "online"
+	offline = "offline"
+)
+
+type (
+	// Bridge mirrors every light on the account to MQTT. Each light's state
+	// is published, retained, to <prefix>/<id>/state as JSON, and commands
+	// published to <prefix>/<id>/set are applied to the light. Both use the
+	// Home Assistant JSON light schema.
+	Bridge struct {
+		backend         lifx.Backend
+		client          *Client
+		store           *lifx.StateStore
+		prefix          string
+		discoveryPrefix string
+		interval        time.Duration
+		onError         lifx.ErrorHandler
+	}
+
+	state struct {
+		State      string   `json:"state"`
+		Brightness int      `json:"brightness"`
+		ColorMode  string   `json:"color_mode,omitempty"`
+		Color      *hsColor `json:"color,omitempty"`
+		ColorTemp  int      `json:"color_temp,omitempty"`
+		Effect     string   `json:"effect,omitempty"`
+	}
+
+	hsColor struct {
+		H float64 `json:"h"`
+		S float64 `json:"s"`
+	}
+
+	command struct {
+		State      *string  `json:"state"`
+		Brightness *int     `json:"brightness"`
+		Color      *hsColor `json:"color"`
+		ColorTemp  *int     `json:"color_temp"`
+		Transition *float64 `json:"transition"`
+		Effect     *string  `json:"effect"`
+	}
+)
+
+var (
+	DefaultTopicPrefix     = "lifx"
+	DefaultDiscoveryPrefix = "homeassistant"
+)
+
+func NewBridge(backend lifx.Backend, client *Client, options ...func(*Bridge)) *Bridge {
+	b := &Bridge{
+		backend:         backend,
+		client:          client,
+		prefix:          DefaultTopicPrefix,
+		discoveryPrefix: DefaultDiscoveryPrefix,
+		interval:        lifx.DefaultWatchInterval,
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	b.store = lifx.NewStateStore(backend, lifx.All().String(), lifx.WithInterval(b.interval))
+	return b
+}
+
+func WithTopicPrefix(prefix string) func(*Bridge) {
+	return func(b *Bridge) {
+		b.prefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// WithDiscoveryPrefix sets where Home Assistant discovery payloads are
+// published. An empty prefix turns discovery off.
+func WithDiscoveryPrefix(prefix string) func(*Bridge) {
+	return func(b *Bridge) {
+		b.discoveryPrefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+func WithPollInterval(interval time.Duration) func(*Bridge) {
+	return func(b *Bridge) {
+		b.interval = interval
+	}
+}
+
+// WithErrorHandler passes errors that don't stop the bridge to fn: failed
+// refreshes with the op "refresh", and commands that could not be decoded
+// or applied with the light's command topic as the op.
+func WithErrorHandler(fn lifx.ErrorHandler) func(*Bridge) {
+	return func(b *Bridge) {
+		b.onError = fn
+	}
+}
+
+// Will is the last will a client used by a bridge with the given topic
+// prefix should register, so that its lights are marked unavailable if
+// the bridge goes away.
+func Will(prefix string) Message {
+	return Message{Topic: availabilityTopic(prefix), Payload: []byte(offline), Retain: true}
+}
+
+func availabilityTopic(prefix string) string {
+	return prefix + "/bridge/availability"
+}
+
+func (b *Bridge) topic(id, suffix string) string {
+	return fmt.Sprintf("%s/%s/%s", b.prefix, id, suffix)
+}
+
+// Run publishes the lights and serves commands until ctx is done or the
+// connection to the broker is lost.
+func (b *Bridge) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := b.store.Refresh(); err != nil {
+		return err
+	}
+
+	events, unsubscribe := b.store.Subscribe()
+	defer unsubscribe()
+
+	if err := b.client.Subscribe(b.topic("+", "set"), b.handle); err != nil {
+		return err
+	}
+
+	for _, l := range b.store.Lights() {
+		b.announce(l)
+	}
+	if err := b.client.Publish(availabilityTopic(b.prefix), []byte(online), true); err != nil {
+		return err
+	}
+
+	go b.store.Run(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.client.Publish(availabilityTopic(b.prefix), []byte(offline), true)
+			return ctx.Err()
+		case <-b.client.Done():
+			return b.client.Err()
+		case e := <-events:
+			switch e.Type {
+			case lifx.LightAdded:
+				b.announce(e.Light)
+			case lifx.LightChanged:
+				b.publishState(e.Light)
+			case lifx.LightRemoved:
+				b.forget(e.Light)
+			case lifx.WatchError:
+				b.reportError("refresh", e.Err)
+			}
+		}
+	}
+}
+
+func (b *Bridge) announce(l lifx.Light) {
+	if b.discoveryPrefix != "" {
+		if payload, err := json.Marshal(b.discovery(l)); err == nil {
+			b.client.Publish(b.discoveryTopic(l), payload, true)
+		}
+	}
+	b.publishState(l)
+}
+
+func (b *Bridge) forget(l lifx.Light) {
+	if b.discoveryPrefix != "" {
+		// An empty retained payload removes the entity from Home Assistant.
+		b.client.Publish(b.discoveryTopic(l), nil, true)
+	}
+	b.client.Publish(b.topic(l.Id, "availability"), []byte(offline), true)
+}
+
+func (b *Bridge) publishState(l lifx.Light) {
+	availability := online
+	if !l.Connected {
+		availability = offline
+	}
+	b.client.Publish(b.topic(l.Id, "availability"), []byte(availability), true)
+
+	if payload, err := json.Marshal(newState(l)); err == nil {
+		b.client.Publish(b.topic(l.Id, "state"), payload, true)
+	}
+}
+
+func newState(l lifx.Light) state {
+	s := state{
+		State:      strings.ToUpper(l.Power),
+		Brightness: int(math.Round(l.Brightness * 255)),
+	}
+
+	if l.IsEffectRunning() {
+		s.Effect = strings.ToLower(l.Effect.String())
+	}
+
+	c := l.Color
+	switch {
+	case l.Product.Capabilities.HasColor && c.S != nil && *c.S > 0:
+		s.ColorMode = "hs"
+		s.Color = &hsColor{S: float64(*c.S) * 100}
+		if c.H != nil {
+			s.Color.H = float64(*c.H)
+		}
+	case l.Product.Capabilities.HasVariableColorTemp || l.Product.Capabilities.HasColor:
+		s.ColorMode = "color_temp"
+		if c.K != nil {
+			s.ColorTemp = int(*c.K)
+		}
+	default:
+		s.ColorMode = "brightness"
+	}
+
+	return s
+}
+
+func (b *Bridge) handle(m Message) {
+	var cmd command
+
+	parts := strings.Split(strings.TrimPrefix(m.Topic, b.prefix+"/"), "/")
+	if len(parts) != 2 {
+		return
+	}
+	id := parts[0]
+
+	if err := json.Unmarshal(m.Payload, &cmd); err != nil {
+		b.reportError(m.Topic, fmt.Errorf("bad command for %s: %w", id, err))
+		return
+	}
+	if err := b.apply(id, cmd); err != nil {
+		b.reportError(m.Topic, fmt.Errorf("applying command to %s: %w", id, err))
+	}
+}
+
+func (b *Bridge) reportError(op string, err error) {
+	if b.onError != nil {
+		b.onError(op, err)
+	}
+}
+
+func (b *Bridge) apply(id string, cmd command) error {
+	var (
+		st    lifx.State
+		color lifx.HSBKColor
+	)
+
+	selector := lifx.ById(id).String()
+
+	if cmd.Effect != nil && *cmd.Effect == "breathe" {
+		breathe := lifx.NewBreathe()
+		breathe.Color = lifx.NamedColor("white")
+		if cmd.Color != nil {
+			breathe.Color = lifx.HSBKColor{H: lifx.Float32Ptr(float32(cmd.Color.H)), S: lifx.Float32Ptr(float32(cmd.Color.S / 100))}
+		}
+		_, err := b.backend.Breathe(selector, breathe)
+		return err
+	}
+
+	if cmd.State != nil {
+		st.Power = strings.ToLower(*cmd.State)
+	}
+	if cmd.Brightness != nil {
+		if *cmd.Brightness <= 0 {
+			st.Power = "off"
+		} else {
+			st.Brightness = math.Min(float64(*cmd.Brightness)/255, 1)
+		}
+	}
+	if cmd.Color != nil {
+		color.H = lifx.Float32Ptr(float32(cmd.Color.H))
+		color.S = lifx.Float32Ptr(float32(cmd.Color.S / 100))
+		st.Color = color
+	} else if cmd.ColorTemp != nil {
+		color.K = lifx.Int16Ptr(int16(*cmd.ColorTemp))
+		color.S = lifx.Float32Ptr(0)
+		st.Color = color
+	}
+	if cmd.Transition != nil {
+		st.Duration = *cmd.Transition
+	}
+
+	r, err := b.backend.SetState(selector, st)
+	if err != nil {
+		return err
+	}
+
+	// Report the change straight away rather than at the next poll, so
+	// that Home Assistant's controls do not jump back in the meantime.
+	for _, res := range resultsOf(r) {
+		if !res.Status.IsOK() {
+			continue
+		}
+		b.store.Update(res.Id, func(l *lifx.Light) {
+			if st.Power != "" {
+				l.Power = st.Power
+			}
+			if st.Brightness > 0 {
+				l.Brightness = st.Brightness
+				l.Color.B = lifx.Float32Ptr(float32(st.Brightness))
+			}
+			if color.H != nil {
+				l.Color.H = color.H
+			}
+			if color.S != nil {
+				l.Color.S = color.S
+			}
+			if color.K != nil {
+				l.Color.K = color.K
+			}
+		})
+	}
+	return nil
+}
+
+func resultsOf(r *lifx.LifxResponse) []lifx.Result {
+	if r == nil {
+		return nil
+	}
+	return r.Results
+}
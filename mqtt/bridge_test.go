@@ -0,0 +1,46 @@
+package mqtt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestBridgeErrorHandler(t *testing.T) {
+	f := lifxtest.NewFakeClient(lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+	failed := errors.New("no route to bulb")
+	f.FailWith("SetState", failed)
+
+	type report struct {
+		op  string
+		err error
+	}
+	var reports []report
+	b := NewBridge(f, nil, WithErrorHandler(func(op string, err error) {
+		reports = append(reports, report{op, err})
+	}))
+
+	topic := "lifx/d073d5000001/set"
+	b.handle(Message{Topic: topic, Payload: []byte("{")})
+	b.handle(Message{Topic: topic, Payload: []byte(`{"state": "ON"}`)})
+
+	if len(reports) != 2 {
+		t.Fatalf("reported %d errors, want 2", len(reports))
+	}
+	for _, r := range reports {
+		if r.op != topic {
+			t.Errorf("op = %q, want %q", r.op, topic)
+		}
+	}
+	if !strings.HasPrefix(reports[0].err.Error(), "bad command for d073d5000001") {
+		t.Errorf("bad payload reported %v", reports[0].err)
+	}
+	if !errors.Is(reports[1].err, failed) {
+		t.Errorf("failed command reported %v, want %v", reports[1].err, failed)
+	}
+	if n := len(f.CallsTo("SetState")); n != 1 {
+		t.Errorf("SetState called %d times, want 1", n)
+	}
+}
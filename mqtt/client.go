@@ -0,0 +1,521 @@
+// Package mqtt bridges LIFX lights to an MQTT broker. It includes just enough
+// of an MQTT 3.1.1 client for the bridge: QoS 0 publishing and subscribing,
+// retained messages, a last will, and keepalives.
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	packetConnect      = 1
+	packetConnack      = 2
+	packetPublish      = 3
+	packetPuback       = 4
+	packetSubscribe    = 8
+	packetSuback       = 9
+	packetPingreq      = 12
+	packetPingresp     = 13
+	packetDisconnect   = 14
+	protocolLevel      = 4
+	maxRemainingLength = 268435455
+)
+
+type (
+	Message struct {
+		Topic   string
+		Payload []byte
+		Retain  bool
+	}
+
+	// Handler is called with each message on a subscribed topic. Handlers
+	// run one at a time, in the order messages arrive, on a goroutine of
+	// their own rather than the one reading from the broker, so they may
+	// Publish and Subscribe themselves.
+	Handler func(Message)
+
+	// delivery is a message waiting for the handlers that matched it when
+	// it arrived.
+	delivery struct {
+		m        Message
+		handlers []Handler
+	}
+
+	Client struct {
+		conn      net.Conn
+		clientID  string
+		username  string
+		password  string
+		keepAlive time.Duration
+		timeout   time.Duration
+		will      *Message
+
+		wmu      sync.Mutex
+		mu       sync.Mutex
+		handlers map[string]Handler
+		pending  map[uint16]chan byte
+		queue    []delivery
+		queued   chan struct{}
+		nextID   uint16
+		done     chan struct{}
+		err      error
+	}
+)
+
+var (
+	DefaultKeepAlive = 30 * time.Second
+	DefaultTimeout   = 10 * time.Second
+
+	ErrClosed = errors.New("connection to the broker is closed")
+)
+
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+func WithClientID(id string) func(*Client) {
+	return func(c *Client) {
+		c.clientID = id
+	}
+}
+
+func WithCredentials(username, password string) func(*Client) {
+	return func(c *Client) {
+		c.username, c.password = username, password
+	}
+}
+
+func WithKeepAlive(keepAlive time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.keepAlive = keepAlive
+	}
+}
+
+// WithWill has the broker publish m on the client's behalf if the
+// connection drops without a clean disconnect.
+func WithWill(m Message) func(*Client) {
+	return func(c *Client) {
+		c.will = &m
+	}
+}
+
+// Dial connects to the broker at rawurl, such as "tcp://localhost:1883" or
+// "ssl://broker:8883". A bare host:port is treated as tcp.
+func Dial(rawurl string, options ...func(*Client)) (*Client, error) {
+	c := &Client{
+		clientID:  fmt.Sprintf("lifx-go-%d", time.Now().UnixNano()),
+		keepAlive: DefaultKeepAlive,
+		timeout:   DefaultTimeout,
+		handlers:  make(map[string]Handler),
+		pending:   make(map[uint16]chan byte),
+		queued:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "tcp://" + rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.User != nil && c.username == "" {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		c.conn, err = net.DialTimeout("tcp", u.Host, c.timeout)
+	case "ssl", "tls", "mqtts":
+		d := &net.Dialer{Timeout: c.timeout}
+		c.conn, err = tls.DialWithDialer(d, "tcp", u.Host, &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(c.conn)
+	if err = c.connect(r); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+
+	go c.readLoop(r)
+	go c.dispatchLoop()
+	if c.keepAlive > 0 {
+		go c.pingLoop()
+	}
+
+	return c, nil
+}
+
+func (c *Client) connect(r *bufio.Reader) error {
+	var (
+		flags byte = 0x02 // clean session
+		body  []byte
+	)
+
+	payload := appendString(nil, c.clientID)
+	if c.will != nil {
+		flags |= 0x04
+		if c.will.Retain {
+			flags |= 0x20
+		}
+		payload = appendString(payload, c.will.Topic)
+		payload = appendBytes(payload, c.will.Payload)
+	}
+	if c.username != "" {
+		flags |= 0x80
+		payload = appendString(payload, c.username)
+		if c.password != "" {
+			flags |= 0x40
+			payload = appendString(payload, c.password)
+		}
+	}
+
+	body = appendString(body, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = appendUint16(body, uint16(c.keepAlive/time.Second))
+	body = append(body, payload...)
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := c.write(packetConnect<<4, body); err != nil {
+		return err
+	}
+
+	typ, b, err := readPacket(r)
+	if err != nil {
+		return err
+	}
+	if typ>>4 != packetConnack || len(b) != 2 {
+		return errors.New("broker did not acknowledge the connection")
+	}
+	if b[1] != 0 {
+		if msg, ok := connackErrors[b[1]]; ok {
+			return fmt.Errorf("broker refused the connection: %s", msg)
+		}
+		return fmt.Errorf("broker refused the connection with code %d", b[1])
+	}
+
+	return nil
+}
+
+// Publish sends a message at QoS 0. Retained messages are kept by the
+// broker and delivered to later subscribers.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	var flags byte = packetPublish << 4
+	if retain {
+		flags |= 0x01
+	}
+
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return c.write(flags, body)
+}
+
+// Subscribe calls h for every message on a topic matching filter, which may
+// use the + and # wildcards. It waits for the broker to confirm.
+func (c *Client) Subscribe(filter string, h Handler) error {
+	c.mu.Lock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID++
+	}
+	id := c.nextID
+	ack := make(chan byte, 1)
+	c.pending[id] = ack
+	c.handlers[filter] = h
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	body := appendUint16(nil, id)
+	body = appendString(body, filter)
+	body = append(body, 0)
+	if err := c.write(packetSubscribe<<4|0x02, body); err != nil {
+		return err
+	}
+
+	select {
+	case code := <-ack:
+		if code == 0x80 {
+			return fmt.Errorf("broker refused the subscription to %s", filter)
+		}
+		return nil
+	case <-c.done:
+		return c.Err()
+	case <-time.After(c.timeout):
+		return fmt.Errorf("timed out subscribing to %s", filter)
+	}
+}
+
+// Done is closed when the connection ends, after which Err reports why.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// Close disconnects cleanly, so the broker does not publish the will.
+func (c *Client) Close() error {
+	c.write(packetDisconnect<<4, nil)
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+func (c *Client) write(header byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return errors.New("packet is too large")
+	}
+
+	b := append([]byte{header}, remainingLength(len(body))...)
+	b = append(b, body...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+
+	_, err := c.conn.Write(b)
+	return err
+}
+
+func (c *Client) readLoop(r *bufio.Reader) {
+	var err error
+
+	defer func() {
+		c.mu.Lock()
+		if errors.Is(err, net.ErrClosed) || err == io.EOF {
+			err = ErrClosed
+		}
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+	}()
+
+	for {
+		var (
+			typ byte
+			b   []byte
+		)
+		if c.keepAlive > 0 {
+			// The broker answers pings, so a silent connection is dead.
+			c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+		}
+		if typ, b, err = readPacket(r); err != nil {
+			return
+		}
+
+		switch typ >> 4 {
+		case packetPublish:
+			c.deliver(typ, b)
+		case packetSuback:
+			if len(b) >= 3 {
+				c.mu.Lock()
+				ack := c.pending[binary.BigEndian.Uint16(b)]
+				c.mu.Unlock()
+				if ack != nil {
+					ack <- b[2]
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) deliver(typ byte, b []byte) {
+	if len(b) < 2 {
+		return
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return
+	}
+	m := Message{Topic: string(b[2 : 2+n]), Retain: typ&0x01 != 0}
+	b = b[2+n:]
+
+	// Subscriptions are QoS 0, but a broker may still downgrade a QoS 1
+	// message late, so its packet id is skipped and acknowledged.
+	if qos := (typ >> 1) & 0x03; qos > 0 {
+		if len(b) < 2 {
+			return
+		}
+		if qos == 1 {
+			c.write(packetPuback<<4, b[:2])
+		}
+		b = b[2:]
+	}
+	m.Payload = b
+
+	// Handlers are matched now, so that a subscription made while the
+	// message waits doesn't receive it, and queued without bound, so that
+	// the read loop never waits for a handler that waits for a reply.
+	c.mu.Lock()
+	var handlers []Handler
+	for filter, h := range c.handlers {
+		if Match(filter, m.Topic) {
+			handlers = append(handlers, h)
+		}
+	}
+	if len(handlers) > 0 {
+		c.queue = append(c.queue, delivery{m: m, handlers: handlers})
+	}
+	c.mu.Unlock()
+
+	select {
+	case c.queued <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchLoop runs the handlers for queued messages until the connection
+// ends.
+func (c *Client) dispatchLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.queued:
+		}
+
+		for {
+			c.mu.Lock()
+			if len(c.queue) == 0 {
+				c.mu.Unlock()
+				break
+			}
+			d := c.queue[0]
+			c.queue[0] = delivery{}
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+
+			for _, h := range d.handlers {
+				h(d.m)
+			}
+		}
+	}
+}
+
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingreq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Match reports whether topic matches the subscription filter.
+func Match(filter, topic string) bool {
+	f, t := strings.Split(filter, "/"), strings.Split(topic, "/")
+
+	for i, part := range f {
+		switch {
+		case part == "#":
+			return true
+		case i >= len(t):
+			return false
+		case part != "+" && part != t[i]:
+			return false
+		}
+	}
+	return len(f) == len(t)
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var n, shift int
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+	}
+
+	b := make([]byte, n)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return 0, nil, err
+	}
+	return typ, b, nil
+}
+
+func remainingLength(n int) []byte {
+	var b []byte
+	for {
+		d := byte(n % 128)
+		if n /= 128; n > 0 {
+			d |= 0x80
+		}
+		b = append(b, d)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+func appendBytes(b, v []byte) []byte {
+	b = appendUint16(b, uint16(len(v)))
+	return append(b, v...)
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
@@ -0,0 +1,196 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRemainingLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xff, 0x7f}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2097152, []byte{0x80, 0x80, 0x80, 0x01}},
+		{maxRemainingLength, []byte{0xff, 0xff, 0xff, 0x7f}},
+	}
+
+	for _, tt := range tests {
+		if got := remainingLength(tt.n); !bytes.Equal(got, tt.want) {
+			t.Errorf("remainingLength(%d) = % x, want % x", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestReadPacket(t *testing.T) {
+	packet := func(typ byte, body []byte) []byte {
+		return append(append([]byte{typ}, remainingLength(len(body))...), body...)
+	}
+	long := bytes.Repeat([]byte{0xab}, 200)
+
+	tests := []struct {
+		name string
+		b    []byte
+		typ  byte
+		body []byte
+		ok   bool
+	}{
+		{"empty body", packet(packetPingresp<<4, nil), packetPingresp << 4, []byte{}, true},
+		{"short body", packet(packetPublish<<4, []byte("hi")), packetPublish << 4, []byte("hi"), true},
+		{"two byte length", packet(packetPublish<<4|0x01, long), packetPublish<<4 | 0x01, long, true},
+		{"nothing", nil, 0, nil, false},
+		{"no length", []byte{packetPublish << 4}, 0, nil, false},
+		{"unfinished length", []byte{packetPublish << 4, 0x80}, 0, nil, false},
+		{"length too long", []byte{packetPublish << 4, 0x80, 0x80, 0x80, 0x80, 0x01}, 0, nil, false},
+		{"truncated body", packet(packetPublish<<4, []byte("hello"))[:4], 0, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, body, err := readPacket(bufio.NewReader(bytes.NewReader(tt.b)))
+			if !tt.ok {
+				if err == nil {
+					t.Fatalf("readPacket = %x % x, want an error", typ, body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != tt.typ || !bytes.Equal(body, tt.body) {
+				t.Errorf("readPacket = %x % x, want %x % x", typ, body, tt.typ, tt.body)
+			}
+		})
+	}
+}
+
+func TestEncodeStrings(t *testing.T) {
+	b := appendString(nil, "MQTT")
+	b = appendBytes(b, []byte{1, 2})
+	b = appendUint16(b, 0x1234)
+
+	want := []byte{0, 4, 'M', 'Q', 'T', 'T', 0, 2, 1, 2, 0x12, 0x34}
+	if !bytes.Equal(b, want) {
+		t.Errorf("encoded % x, want % x", b, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"lifx/+/set", "lifx/d073d5000001/set", true},
+		{"lifx/+/set", "lifx/d073d5000001/state", false},
+		{"lifx/#", "lifx/d073d5000001/set", true},
+		{"lifx/#", "lifx", true},
+		{"lifx/+", "lifx/a/b", false},
+		{"lifx/a", "lifx/a", true},
+		{"lifx/a", "lifx", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+// broker is just enough of an MQTT broker for one client: it accepts the
+// connection, acknowledges subscriptions, and passes on what the client
+// publishes. Messages sent on publish go to the client.
+type broker struct {
+	ln        net.Listener
+	published chan Message
+	publish   chan Message
+}
+
+func newBroker(t *testing.T) *broker {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	b := &broker{ln: ln, published: make(chan Message, 16), publish: make(chan Message, 16)}
+	go b.serve()
+	return b
+}
+
+func (b *broker) serve() {
+	conn, err := b.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	send := func(header byte, body []byte) {
+		conn.Write(append(append([]byte{header}, remainingLength(len(body))...), body...))
+	}
+	go func() {
+		for m := range b.publish {
+			send(packetPublish<<4, append(appendString(nil, m.Topic), m.Payload...))
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		typ, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch typ >> 4 {
+		case packetConnect:
+			send(packetConnack<<4, []byte{0, 0})
+		case packetSubscribe:
+			send(packetSuback<<4, append(body[:2:2], 0))
+		case packetPublish:
+			n := int(body[0])<<8 | int(body[1])
+			b.published <- Message{Topic: string(body[2 : 2+n]), Payload: body[2+n:]}
+		}
+	}
+}
+
+// TestHandlerSubscribes checks that a handler can wait on the broker, which
+// deadlocks if handlers run on the goroutine reading its replies.
+func TestHandlerSubscribes(t *testing.T) {
+	b := newBroker(t)
+
+	c, err := Dial(b.ln.Addr().String(), WithKeepAlive(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	err = c.Subscribe("lifx/+/set", func(m Message) {
+		if err := c.Subscribe("lifx/"+string(m.Payload)+"/state", func(Message) {}); err != nil {
+			t.Errorf("subscribing from a handler: %v", err)
+			return
+		}
+		c.Publish("lifx/"+string(m.Payload)+"/ack", nil, false)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.publish <- Message{Topic: "lifx/d073d5000001/set", Payload: []byte("d073d5000001")}
+
+	select {
+	case m := <-b.published:
+		if m.Topic != "lifx/d073d5000001/ack" {
+			t.Errorf("published to %s, want lifx/d073d5000001/ack", m.Topic)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler never finished")
+	}
+}
@@ -0,0 +1,89 @@
+package mqtt
+
+import (
+	"fmt"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	// discovery is a Home Assistant MQTT light using the JSON schema.
+	discovery struct {
+		Name                string         `json:"name"`
+		UniqueID            string         `json:"unique_id"`
+		Schema              string         `json:"schema"`
+		CommandTopic        string         `json:"command_topic"`
+		StateTopic          string         `json:"state_topic"`
+		Availability        []availability `json:"availability"`
+		AvailabilityMode    string         `json:"availability_mode"`
+		Brightness          bool           `json:"brightness"`
+		BrightnessScale     int            `json:"brightness_scale"`
+		SupportedColorModes []string       `json:"supported_color_modes"`
+		ColorTempKelvin     bool           `json:"color_temp_kelvin,omitempty"`
+		MinKelvin           int            `json:"min_kelvin,omitempty"`
+		MaxKelvin           int            `json:"max_kelvin,omitempty"`
+		Effect              bool           `json:"effect"`
+		EffectList          []string       `json:"effect_list,omitempty"`
+		Device              device         `json:"device"`
+	}
+
+	availability struct {
+		Topic string `json:"topic"`
+	}
+
+	device struct {
+		Identifiers   []string `json:"identifiers"`
+		Name          string   `json:"name"`
+		Manufacturer  string   `json:"manufacturer"`
+		Model         string   `json:"model,omitempty"`
+		SuggestedArea string   `json:"suggested_area,omitempty"`
+	}
+)
+
+var effects = []string{"breathe"}
+
+func (b *Bridge) discoveryTopic(l lifx.Light) string {
+	return fmt.Sprintf("%s/light/lifx_%s/config", b.discoveryPrefix, l.Id)
+}
+
+func (b *Bridge) discovery(l lifx.Light) discovery {
+	caps := l.Product.Capabilities
+
+	d := discovery{
+		Name:         l.Label,
+		UniqueID:     "lifx_" + l.Id,
+		Schema:       "json",
+		CommandTopic: b.topic(l.Id, "set"),
+		StateTopic:   b.topic(l.Id, "state"),
+		Availability: []availability{
+			{Topic: availabilityTopic(b.prefix)},
+			{Topic: b.topic(l.Id, "availability")},
+		},
+		AvailabilityMode: "all",
+		Brightness:       true,
+		BrightnessScale:  255,
+		Effect:           true,
+		EffectList:       effects,
+		Device: device{
+			Identifiers:   []string{"lifx_" + l.Id},
+			Name:          l.Label,
+			Manufacturer:  "LIFX",
+			Model:         l.Product.Name,
+			SuggestedArea: l.Group.Name,
+		},
+	}
+
+	if caps.HasColor {
+		d.SupportedColorModes = append(d.SupportedColorModes, "hs")
+	}
+	if caps.HasColor || caps.HasVariableColorTemp {
+		d.SupportedColorModes = append(d.SupportedColorModes, "color_temp")
+		d.ColorTempKelvin = true
+		d.MinKelvin, d.MaxKelvin = int(caps.MinKelvin), int(caps.MaxKelvin)
+	}
+	if len(d.SupportedColorModes) == 0 {
+		d.SupportedColorModes = []string{"brightness"}
+	}
+
+	return d
+}
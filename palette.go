@@ -0,0 +1,145 @@
+package lifx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Palette is a set of colors spread across several lights, like the themes
+// in the LIFX app. Colors that leave brightness unset keep each light's
+// current brightness.
+type Palette struct {
+	Name   string
+	Colors []HSBKColor
+}
+
+func hsPalette(name string, hs ...[2]float32) Palette {
+	p := Palette{Name: name}
+	for _, c := range hs {
+		p.Colors = append(p.Colors, HSBKColor{H: Float32Ptr(c[0]), S: Float32Ptr(c[1])})
+	}
+	return p
+}
+
+var Palettes = map[string]Palette{
+	"autumn":     hsPalette("autumn", [2]float32{31, 1}, [2]float32{43, 0.9}, [2]float32{16, 1}, [2]float32{6, 0.85}, [2]float32{50, 0.7}),
+	"blissful":   hsPalette("blissful", [2]float32{303, 0.4}, [2]float32{321, 0.5}, [2]float32{268, 0.35}, [2]float32{340, 0.3}),
+	"cheerful":   hsPalette("cheerful", [2]float32{55, 1}, [2]float32{30, 1}, [2]float32{330, 0.8}, [2]float32{190, 0.8}),
+	"dream":      hsPalette("dream", [2]float32{201, 0.75}, [2]float32{240, 0.6}, [2]float32{275, 0.65}, [2]float32{310, 0.5}),
+	"energizing": hsPalette("energizing", [2]float32{185, 1}, [2]float32{120, 1}, [2]float32{60, 1}, [2]float32{0, 1}),
+	"exciting":   hsPalette("exciting", [2]float32{0, 1}, [2]float32{40, 1}, [2]float32{60, 1}, [2]float32{120, 1}, [2]float32{200, 1}, [2]float32{280, 1}),
+	"intense":    hsPalette("intense", [2]float32{0, 1}, [2]float32{320, 1}, [2]float32{260, 1}, [2]float32{20, 1}),
+	"peaceful":   hsPalette("peaceful", [2]float32{190, 0.3}, [2]float32{220, 0.35}, [2]float32{160, 0.25}, [2]float32{250, 0.2}),
+	"powerful":   hsPalette("powerful", [2]float32{240, 1}, [2]float32{0, 1}, [2]float32{280, 1}),
+	"relaxing":   hsPalette("relaxing", [2]float32{25, 0.6}, [2]float32{35, 0.5}, [2]float32{330, 0.3}, [2]float32{45, 0.4}),
+	"spring":     hsPalette("spring", [2]float32{90, 0.6}, [2]float32{130, 0.5}, [2]float32{320, 0.35}, [2]float32{55, 0.5}, [2]float32{200, 0.35}),
+	"tranquil":   hsPalette("tranquil", [2]float32{180, 0.5}, [2]float32{210, 0.6}, [2]float32{240, 0.4}),
+}
+
+// NewPalette builds a palette from color strings in the syntax ParseColor
+// accepts.
+func NewPalette(name string, colors ...string) (Palette, error) {
+	p := Palette{Name: name}
+
+	if len(colors) == 0 {
+		return p, errors.New("a palette needs at least one color")
+	}
+	for _, s := range colors {
+		c, err := ParseColor(s)
+		if err != nil {
+			return p, fmt.Errorf("palette %s: %w", name, err)
+		}
+		p.Colors = append(p.Colors, c)
+	}
+
+	return p, nil
+}
+
+// States spreads the palette across lights, which are ordered by group and
+// label so that the result is stable. Each light takes the next color,
+// and multizone lights have their zones divided into bands of successive
+// colors. Lights that cannot show color are left out.
+func (p Palette) States(lights []Light, defaults State) States {
+	var (
+		states States
+		next   int
+	)
+
+	states.Defaults = defaults
+	if len(p.Colors) == 0 {
+		return states
+	}
+
+	lights = append([]Light(nil), lights...)
+	SortByGroupThenLabel(lights)
+
+	color := func() HSBKColor {
+		c := p.Colors[next%len(p.Colors)]
+		next++
+		return c
+	}
+
+	for _, l := range lights {
+		if !l.Product.Capabilities.HasColor {
+			continue
+		}
+
+		if l.Zones == nil || l.Zones.Count < 2 {
+			states.States = append(states.States, StateWithSelector{
+				State:    State{Color: color()},
+				Selector: ById(l.Id).String(),
+			})
+			continue
+		}
+
+		bands := len(p.Colors)
+		if bands > l.Zones.Count {
+			bands = l.Zones.Count
+		}
+		for b := 0; b < bands; b++ {
+			start, end := b*l.Zones.Count/bands, (b+1)*l.Zones.Count/bands-1
+			states.States = append(states.States, StateWithSelector{
+				State:    State{Color: color()},
+				Selector: fmt.Sprintf("%s|%d-%d", ById(l.Id), start, end),
+			})
+		}
+	}
+
+	return states
+}
+
+// ApplyPalette spreads p across the lights matching selector. Fields set in
+// defaults, such as the duration, apply to every light.
+func (c *Client) ApplyPalette(selector string, p Palette, defaults State) (*SetStatesResponse, error) {
+	lights, err := c.ListLights(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	states := p.States(lights, defaults)
+	if len(states.States) == 0 {
+		return nil, fmt.Errorf("none of the lights matching %s can show color", selector)
+	}
+
+	return c.SetStates(selector, states)
+}
+
+// PaletteNames lists the built-in palettes in alphabetical order.
+func PaletteNames() []string {
+	names := make([]string, 0, len(Palettes))
+	for name := range Palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p Palette) String() string {
+	colors := make([]string, len(p.Colors))
+	for i, c := range p.Colors {
+		colors[i] = c.ColorString()
+	}
+	return strings.Join(colors, "; ")
+}
@@ -0,0 +1,34 @@
+package lifx
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is what a panic in a callback, a transport or a background
+// loop becomes once it has been recovered, so that it reaches an
+// ErrorHandler instead of bringing down the program.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value that was panicked with, if it was an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// safely calls fn, returning any panic it raises as a *PanicError.
+func safely(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,90 @@
+package lifx
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWorkers is the size WithWorkerPool gives a pool when asked for
+// none.
+var DefaultWorkers = 4
+
+// WithWorkerPool caps how many requests the client has in flight at once,
+// so that fan-outs such as SetStateMulti, Restore and scheduled jobs
+// queue up instead of bursting. Copies made with With share the pool. A
+// size of zero or less uses DefaultWorkers.
+func WithWorkerPool(size int) func(*Client) {
+	return func(c *Client) {
+		if size <= 0 {
+			size = DefaultWorkers
+		}
+		c.pool = newWorkerPool(size)
+	}
+}
+
+// workerPool hands out a fixed number of slots.
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+func (p *workerPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *workerPool) release() {
+	<-p.slots
+}
+
+func (p *workerPool) size() int {
+	return cap(p.slots)
+}
+
+// SetStateMulti sets state on each of selectors at once, within the
+// client's worker pool, and merges the results in the order of selectors.
+// Every selector is tried; the first error is returned with the results
+// that came back.
+func (c *Client) SetStateMulti(selectors []string, state State) (*LifxResponse, error) {
+	c = c.correlated()
+
+	var (
+		wg        sync.WaitGroup
+		responses = make([]*LifxResponse, len(selectors))
+		errs      = make([]error, len(selectors))
+	)
+
+	for i, s := range selectors {
+		wg.Add(1)
+		go func(i int, s string) {
+			defer wg.Done()
+			responses[i], errs[i] = c.SetState(s, state)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var merged *LifxResponse
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &LifxResponse{}
+		}
+		merged.Warnings = append(merged.Warnings, r.Warnings...)
+		merged.Results = append(merged.Results, r.Results...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,87 @@
+package lifx_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestSetStateMultiPool(t *testing.T) {
+	var (
+		lights    []lifx.Light
+		selectors []string
+	)
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("d073d500000%d", i)
+		lights = append(lights, lifxtest.NewLight(id, fmt.Sprintf("Light %d", i), "Office", "Home"))
+		selectors = append(selectors, "id:"+id)
+	}
+	api := lifxtest.NewServer(lights...)
+	defer api.Close()
+
+	c := lifx.NewClient("x", lifxtest.WithServer(api), lifx.WithWorkerPool(2))
+
+	var (
+		mu             sync.Mutex
+		inFlight, peak int
+		next           = c.Client.Transport
+	)
+	c.Client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		if inFlight++; inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(5 * time.Millisecond)
+		return next.RoundTrip(req)
+	})
+
+	r, err := c.SetStateMulti(selectors, lifx.State{Power: "on"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak > 2 {
+		t.Errorf("%d requests in flight at once, want at most 2", peak)
+	}
+	if len(r.Results) != len(lights) {
+		t.Fatalf("got %d results, want %d", len(r.Results), len(lights))
+	}
+	for i, res := range r.Results {
+		if res.Id != lights[i].Id {
+			t.Errorf("result %d is for %s, want %s", i, res.Id, lights[i].Id)
+		}
+	}
+}
+
+func TestSetStateMultiError(t *testing.T) {
+	api := lifxtest.NewServer(
+		lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"),
+		lifxtest.NewLight("d073d5000002", "Porch", "Outside", "Home"),
+	)
+	defer api.Close()
+
+	c := lifx.NewClient("x", lifxtest.WithServer(api))
+	r, err := c.SetStateMulti([]string{"label:Desk", "label:Missing", "label:Porch"}, lifx.State{Power: "on"})
+	if !errors.Is(err, lifx.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if r == nil || len(r.Results) != 2 {
+		t.Fatalf("results = %+v, want the two that matched", r)
+	}
+	for _, id := range []string{"d073d5000001", "d073d5000002"} {
+		if l, _ := api.Light(id); l.Power != "on" {
+			t.Errorf("%s is %s, want on", id, l.Power)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package presence turns people arriving and leaving into light changes.
+// Where the events come from is up to the caller, such as DHCP leases on a
+// router or a phone's geofence; rules decide what happens, including when
+// the first person gets home or the last one leaves.
+package presence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	Arrive EventType = "arrive"
+	Leave  EventType = "leave"
+)
+
+const (
+	// OnArrive and OnLeave fire for every arrival or departure of the
+	// rule's person, or of anyone if it has none.
+	OnArrive Trigger = "arrive"
+	OnLeave  Trigger = "leave"
+
+	// OnFirstArrive fires when someone arrives at an empty home, and
+	// OnLastLeave when the last person leaves it.
+	OnFirstArrive Trigger = "first-arrive"
+	OnLastLeave   Trigger = "last-leave"
+)
+
+type (
+	EventType string
+
+	Trigger string
+
+	Event struct {
+		Type   EventType
+		Person string
+		Time   time.Time
+	}
+
+	// Presence supplies events. The channel is closed when there are no
+	// more.
+	Presence interface {
+		Events() <-chan Event
+	}
+
+	// Feed is a Presence for callers that learn of arrivals and departures
+	// themselves.
+	Feed chan Event
+
+	Rule struct {
+		Name    string
+		Trigger Trigger
+		Person  string
+		Action  func(ctx context.Context) error
+	}
+
+	// Tracker keeps track of who is home and runs the rules matching each
+	// event in the order they were given. Repeated arrivals or departures
+	// change nothing, so sources may report the same state more than
+	// once.
+	Tracker struct {
+		mu      sync.Mutex
+		rules   []Rule
+		present map[string]bool
+	}
+
+	// SceneActivator is the part of the client that scene actions need.
+	SceneActivator interface {
+		ListScenes() ([]lifx.Scene, error)
+		ActivateScene(uuid string, activate lifx.Activate) (*lifx.LifxResponse, error)
+	}
+)
+
+func (f Feed) Events() <-chan Event { return f }
+
+func (f Feed) Arrive(person string) {
+	f <- Event{Type: Arrive, Person: person, Time: time.Now()}
+}
+
+func (f Feed) Leave(person string) {
+	f <- Event{Type: Leave, Person: person, Time: time.Now()}
+}
+
+// NewTracker returns a tracker running rules, or an error naming the first
+// rule that is not valid.
+func NewTracker(rules ...Rule) (*Tracker, error) {
+	for i, r := range rules {
+		if err := r.Valid(); err != nil {
+			name := r.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", i)
+			}
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	return &Tracker{rules: rules, present: make(map[string]bool)}, nil
+}
+
+func (r Rule) Valid() error {
+	switch r.Trigger {
+	case OnArrive, OnLeave, OnFirstArrive, OnLastLeave:
+	default:
+		return fmt.Errorf("'%s' is not a valid trigger", r.Trigger)
+	}
+	if r.Action == nil {
+		return errors.New("rule has no action")
+	}
+	if r.Person != "" && (r.Trigger == OnFirstArrive || r.Trigger == OnLastLeave) {
+		return fmt.Errorf("%s rules apply to everyone and take no person", r.Trigger)
+	}
+	return nil
+}
+
+// SetPresent records who is home without running any rules, for instance
+// from a source's current state at startup.
+func (t *Tracker) SetPresent(people ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.present = make(map[string]bool)
+	for _, p := range people {
+		t.present[p] = true
+	}
+}
+
+func (t *Tracker) Present() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	people := make([]string, 0, len(t.present))
+	for p := range t.present {
+		people = append(people, p)
+	}
+	sort.Strings(people)
+	return people
+}
+
+// Handle applies e and runs the rules it triggers, returning the first
+// error. Every triggered rule runs even if an earlier one fails.
+func (t *Tracker) Handle(ctx context.Context, e Event) error {
+	var triggers []Trigger
+
+	t.mu.Lock()
+	before := len(t.present)
+	switch e.Type {
+	case Arrive:
+		if !t.present[e.Person] {
+			t.present[e.Person] = true
+			triggers = append(triggers, OnArrive)
+			if before == 0 {
+				triggers = append(triggers, OnFirstArrive)
+			}
+		}
+	case Leave:
+		if t.present[e.Person] {
+			delete(t.present, e.Person)
+			triggers = append(triggers, OnLeave)
+			if before == 1 {
+				triggers = append(triggers, OnLastLeave)
+			}
+		}
+	default:
+		t.mu.Unlock()
+		return fmt.Errorf("'%s' is not a valid event", e.Type)
+	}
+	rules := t.rules
+	t.mu.Unlock()
+
+	var first error
+	for _, r := range rules {
+		if !r.matches(triggers, e.Person) {
+			continue
+		}
+		if err := r.Action(ctx); err != nil && first == nil {
+			first = fmt.Errorf("%s: %w", r.Name, err)
+		}
+	}
+	return first
+}
+
+func (r Rule) matches(triggers []Trigger, person string) bool {
+	if r.Person != "" && r.Person != person {
+		return false
+	}
+	for _, t := range triggers {
+		if t == r.Trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Run handles events from p until it has no more or ctx is done. Errors
+// from rules are passed to onError, if given, and do not stop the tracker.
+func (t *Tracker) Run(ctx context.Context, p Presence, onError func(error)) error {
+	events := p.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := t.Handle(ctx, e); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// ActivateScene returns an action activating the scene with the given name
+// or UUID.
+func ActivateScene(c SceneActivator, nameOrUUID string, duration float64) func(context.Context) error {
+	return func(context.Context) error {
+		scenes, err := c.ListScenes()
+		if err != nil {
+			return err
+		}
+		scene, err := lifx.FindScene(scenes, nameOrUUID)
+		if err != nil {
+			return err
+		}
+		_, err = c.ActivateScene(scene.UUID, lifx.Activate{Duration: duration})
+		return err
+	}
+}
+
+// SetPower returns an action turning the selected lights on or off.
+func SetPower(b lifx.Backend, selector, power string, duration float64) func(context.Context) error {
+	return SetState(b, selector, lifx.State{Power: power, Duration: duration})
+}
+
+func SetState(b lifx.Backend, selector string, state lifx.State) func(context.Context) error {
+	return func(context.Context) error {
+		_, err := b.SetState(selector, state)
+		return err
+	}
+}
@@ -0,0 +1,94 @@
+package lifx
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// LightProduct is what a light is, as opposed to what it is doing.
+	LightProduct struct {
+		Id      string  `json:"id"`
+		Label   string  `json:"label"`
+		Product Product `json:"product"`
+	}
+
+	// productCache remembers which products each selector matched, which
+	// rarely changes, so that checks against capabilities need not list
+	// the lights every time.
+	productCache struct {
+		ttl     time.Duration
+		mu      sync.Mutex
+		entries map[string]productEntry
+	}
+
+	productEntry struct {
+		lights  []LightProduct
+		fetched time.Time
+	}
+)
+
+// DefaultProductTTL is how long the products behind a selector are
+// remembered.
+var DefaultProductTTL = time.Hour
+
+// WithProductTTL sets how long the client remembers the products a
+// selector matched, as used by CheckedSetInfrared and WithKelvinClamp. A
+// duration of zero or less stops it remembering.
+func WithProductTTL(ttl time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.products = newProductCache(ttl)
+	}
+}
+
+func newProductCache(ttl time.Duration) *productCache {
+	return &productCache{ttl: ttl, entries: make(map[string]productEntry)}
+}
+
+// Products reports the products of the lights matching selector. They
+// are remembered from any earlier ListLights or Products call for the same
+// selector, and only listed again once they are older than the client's
+// product TTL.
+func (c *Client) Products(selector string) ([]LightProduct, error) {
+	if c.products != nil {
+		if lights, ok := c.products.get(selector, c.now()); ok {
+			return lights, nil
+		}
+	}
+
+	lights, err := c.ListLights(selector)
+	if err != nil {
+		return nil, err
+	}
+	return lightProducts(lights), nil
+}
+
+func lightProducts(lights []Light) []LightProduct {
+	products := make([]LightProduct, len(lights))
+	for i, l := range lights {
+		products[i] = LightProduct{Id: l.Id, Label: l.Label, Product: l.Product}
+	}
+	return products
+}
+
+func (p *productCache) get(selector string, now time.Time) ([]LightProduct, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.entries[selector]
+	if !ok || now.Sub(e.fetched) >= p.ttl {
+		return nil, false
+	}
+	return append([]LightProduct(nil), e.lights...), true
+}
+
+func (p *productCache) put(selector string, lights []Light, now time.Time) {
+	if p.ttl <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries[selector] = productEntry{lights: lightProducts(lights), fetched: now}
+}
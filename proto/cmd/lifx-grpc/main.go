@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/config"
+	"git.kill0.net/chill9/lifx-go/proto/lifxv1"
+	"git.kill0.net/chill9/lifx-go/proto/server"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	var (
+		listen = flag.String("listen", "127.0.0.1:9090", "address to serve gRPC on")
+		path   = flag.String("config", "", "client configuration file, defaults to $LIFX_CONFIG")
+	)
+	flag.Parse()
+
+	conf, err := config.Load(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := conf.NewClient(lifx.WithUserAgent("lifx-grpc/" + lifx.Version))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	lifxv1.RegisterLifxServer(s, server.New(c))
+
+	log.Printf("serving gRPC on %s", l.Addr())
+	log.Fatal(s.Serve(l))
+}
@@ -0,0 +1,4 @@
+// Package lifxv1 is the generated code for lifx.proto.
+package lifxv1
+
+//go:generate protoc -I.. --go_out=.. --go_opt=module=git.kill0.net/chill9/lifx-go/proto --go-grpc_out=.. --go-grpc_opt=module=git.kill0.net/chill9/lifx-go/proto ../lifx.proto
@@ -0,0 +1,1775 @@
+// Service definition for sharing one rate-limited LIFX client with services
+// written in other languages. Messages mirror the types in the lifx package.
+//
+// The generated code, a server delegating to a lifx.Client and the
+// lifx-grpc command live in this directory's own module, which keeps gRPC
+// out of the root module's dependencies. Regenerate with go generate.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: lifx.proto
+
+package lifxv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Event_Type int32
+
+const (
+	Event_TYPE_UNSPECIFIED Event_Type = 0
+	Event_LIGHT_ADDED      Event_Type = 1
+	Event_LIGHT_REMOVED    Event_Type = 2
+	Event_LIGHT_CHANGED    Event_Type = 3
+	Event_WATCH_ERROR      Event_Type = 4
+)
+
+// Enum value maps for Event_Type.
+var (
+	Event_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "LIGHT_ADDED",
+		2: "LIGHT_REMOVED",
+		3: "LIGHT_CHANGED",
+		4: "WATCH_ERROR",
+	}
+	Event_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"LIGHT_ADDED":      1,
+		"LIGHT_REMOVED":    2,
+		"LIGHT_CHANGED":    3,
+		"WATCH_ERROR":      4,
+	}
+)
+
+func (x Event_Type) Enum() *Event_Type {
+	p := new(Event_Type)
+	*p = x
+	return p
+}
+
+func (x Event_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Event_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_lifx_proto_enumTypes[0].Descriptor()
+}
+
+func (Event_Type) Type() protoreflect.EnumType {
+	return &file_lifx_proto_enumTypes[0]
+}
+
+func (x Event_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Event_Type.Descriptor instead.
+func (Event_Type) EnumDescriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{21, 0}
+}
+
+type Color struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hue           *float32               `protobuf:"fixed32,1,opt,name=hue,proto3,oneof" json:"hue,omitempty"`
+	Saturation    *float32               `protobuf:"fixed32,2,opt,name=saturation,proto3,oneof" json:"saturation,omitempty"`
+	Brightness    *float32               `protobuf:"fixed32,3,opt,name=brightness,proto3,oneof" json:"brightness,omitempty"`
+	Kelvin        *int32                 `protobuf:"varint,4,opt,name=kelvin,proto3,oneof" json:"kelvin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Color) Reset() {
+	*x = Color{}
+	mi := &file_lifx_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Color) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Color) ProtoMessage() {}
+
+func (x *Color) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Color.ProtoReflect.Descriptor instead.
+func (*Color) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Color) GetHue() float32 {
+	if x != nil && x.Hue != nil {
+		return *x.Hue
+	}
+	return 0
+}
+
+func (x *Color) GetSaturation() float32 {
+	if x != nil && x.Saturation != nil {
+		return *x.Saturation
+	}
+	return 0
+}
+
+func (x *Color) GetBrightness() float32 {
+	if x != nil && x.Brightness != nil {
+		return *x.Brightness
+	}
+	return 0
+}
+
+func (x *Color) GetKelvin() int32 {
+	if x != nil && x.Kelvin != nil {
+		return *x.Kelvin
+	}
+	return 0
+}
+
+type Group struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_lifx_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Capabilities struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	HasColor             bool                   `protobuf:"varint,1,opt,name=has_color,json=hasColor,proto3" json:"has_color,omitempty"`
+	HasVariableColorTemp bool                   `protobuf:"varint,2,opt,name=has_variable_color_temp,json=hasVariableColorTemp,proto3" json:"has_variable_color_temp,omitempty"`
+	HasIr                bool                   `protobuf:"varint,3,opt,name=has_ir,json=hasIr,proto3" json:"has_ir,omitempty"`
+	HasHev               bool                   `protobuf:"varint,4,opt,name=has_hev,json=hasHev,proto3" json:"has_hev,omitempty"`
+	HasChain             bool                   `protobuf:"varint,5,opt,name=has_chain,json=hasChain,proto3" json:"has_chain,omitempty"`
+	HasMatrix            bool                   `protobuf:"varint,6,opt,name=has_matrix,json=hasMatrix,proto3" json:"has_matrix,omitempty"`
+	HasMultizone         bool                   `protobuf:"varint,7,opt,name=has_multizone,json=hasMultizone,proto3" json:"has_multizone,omitempty"`
+	MinKelvin            float64                `protobuf:"fixed64,8,opt,name=min_kelvin,json=minKelvin,proto3" json:"min_kelvin,omitempty"`
+	MaxKelvin            float64                `protobuf:"fixed64,9,opt,name=max_kelvin,json=maxKelvin,proto3" json:"max_kelvin,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	mi := &file_lifx_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Capabilities) GetHasColor() bool {
+	if x != nil {
+		return x.HasColor
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasVariableColorTemp() bool {
+	if x != nil {
+		return x.HasVariableColorTemp
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasIr() bool {
+	if x != nil {
+		return x.HasIr
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasHev() bool {
+	if x != nil {
+		return x.HasHev
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasChain() bool {
+	if x != nil {
+		return x.HasChain
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasMatrix() bool {
+	if x != nil {
+		return x.HasMatrix
+	}
+	return false
+}
+
+func (x *Capabilities) GetHasMultizone() bool {
+	if x != nil {
+		return x.HasMultizone
+	}
+	return false
+}
+
+func (x *Capabilities) GetMinKelvin() float64 {
+	if x != nil {
+		return x.MinKelvin
+	}
+	return 0
+}
+
+func (x *Capabilities) GetMaxKelvin() float64 {
+	if x != nil {
+		return x.MaxKelvin
+	}
+	return 0
+}
+
+type Product struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Identifier    string                 `protobuf:"bytes,2,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Company       string                 `protobuf:"bytes,3,opt,name=company,proto3" json:"company,omitempty"`
+	VendorId      int32                  `protobuf:"varint,4,opt,name=vendor_id,json=vendorId,proto3" json:"vendor_id,omitempty"`
+	ProductId     int32                  `protobuf:"varint,5,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Capabilities  *Capabilities          `protobuf:"bytes,6,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Product) Reset() {
+	*x = Product{}
+	mi := &file_lifx_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Product) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Product) ProtoMessage() {}
+
+func (x *Product) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Product.ProtoReflect.Descriptor instead.
+func (*Product) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Product) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Product) GetIdentifier() string {
+	if x != nil {
+		return x.Identifier
+	}
+	return ""
+}
+
+func (x *Product) GetCompany() string {
+	if x != nil {
+		return x.Company
+	}
+	return ""
+}
+
+func (x *Product) GetVendorId() int32 {
+	if x != nil {
+		return x.VendorId
+	}
+	return 0
+}
+
+func (x *Product) GetProductId() int32 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *Product) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type Light struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Uuid       string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Label      string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Connected  bool                   `protobuf:"varint,4,opt,name=connected,proto3" json:"connected,omitempty"`
+	Power      string                 `protobuf:"bytes,5,opt,name=power,proto3" json:"power,omitempty"`
+	Color      *Color                 `protobuf:"bytes,6,opt,name=color,proto3" json:"color,omitempty"`
+	Brightness float64                `protobuf:"fixed64,7,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	Effect     string                 `protobuf:"bytes,8,opt,name=effect,proto3" json:"effect,omitempty"`
+	Group      *Group                 `protobuf:"bytes,9,opt,name=group,proto3" json:"group,omitempty"`
+	Location   *Group                 `protobuf:"bytes,10,opt,name=location,proto3" json:"location,omitempty"`
+	Product    *Product               `protobuf:"bytes,11,opt,name=product,proto3" json:"product,omitempty"`
+	// RFC 3339.
+	LastSeen         string  `protobuf:"bytes,12,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	SecondsSinceSeen float64 `protobuf:"fixed64,13,opt,name=seconds_since_seen,json=secondsSinceSeen,proto3" json:"seconds_since_seen,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Light) Reset() {
+	*x = Light{}
+	mi := &file_lifx_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Light) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Light) ProtoMessage() {}
+
+func (x *Light) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Light.ProtoReflect.Descriptor instead.
+func (*Light) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Light) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Light) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Light) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Light) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *Light) GetPower() string {
+	if x != nil {
+		return x.Power
+	}
+	return ""
+}
+
+func (x *Light) GetColor() *Color {
+	if x != nil {
+		return x.Color
+	}
+	return nil
+}
+
+func (x *Light) GetBrightness() float64 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *Light) GetEffect() string {
+	if x != nil {
+		return x.Effect
+	}
+	return ""
+}
+
+func (x *Light) GetGroup() *Group {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *Light) GetLocation() *Group {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Light) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *Light) GetLastSeen() string {
+	if x != nil {
+		return x.LastSeen
+	}
+	return ""
+}
+
+func (x *Light) GetSecondsSinceSeen() float64 {
+	if x != nil {
+		return x.SecondsSinceSeen
+	}
+	return 0
+}
+
+// State matches the body of PUT /lights/{selector}/state. Colors are given
+// as strings in the LIFX color format, such as "red" or "kelvin:2700".
+type State struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Power         string                 `protobuf:"bytes,1,opt,name=power,proto3" json:"power,omitempty"`
+	Color         string                 `protobuf:"bytes,2,opt,name=color,proto3" json:"color,omitempty"`
+	Brightness    *float64               `protobuf:"fixed64,3,opt,name=brightness,proto3,oneof" json:"brightness,omitempty"`
+	Duration      float64                `protobuf:"fixed64,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Infrared      *float64               `protobuf:"fixed64,5,opt,name=infrared,proto3,oneof" json:"infrared,omitempty"`
+	Fast          bool                   `protobuf:"varint,6,opt,name=fast,proto3" json:"fast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	mi := &file_lifx_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *State) GetPower() string {
+	if x != nil {
+		return x.Power
+	}
+	return ""
+}
+
+func (x *State) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *State) GetBrightness() float64 {
+	if x != nil && x.Brightness != nil {
+		return *x.Brightness
+	}
+	return 0
+}
+
+func (x *State) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *State) GetInfrared() float64 {
+	if x != nil && x.Infrared != nil {
+		return *x.Infrared
+	}
+	return 0
+}
+
+func (x *State) GetFast() bool {
+	if x != nil {
+		return x.Fast
+	}
+	return false
+}
+
+type Result struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_lifx_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Result) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Result) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Result) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*Result              `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Warnings      []string               `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultsResponse) Reset() {
+	*x = ResultsResponse{}
+	mi := &file_lifx_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsResponse) ProtoMessage() {}
+
+func (x *ResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultsResponse.ProtoReflect.Descriptor instead.
+func (*ResultsResponse) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ResultsResponse) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ResultsResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type ListLightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsRequest) Reset() {
+	*x = ListLightsRequest{}
+	mi := &file_lifx_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsRequest) ProtoMessage() {}
+
+func (x *ListLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsRequest.ProtoReflect.Descriptor instead.
+func (*ListLightsRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListLightsRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+type ListLightsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lights        []*Light               `protobuf:"bytes,1,rep,name=lights,proto3" json:"lights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsResponse) Reset() {
+	*x = ListLightsResponse{}
+	mi := &file_lifx_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsResponse) ProtoMessage() {}
+
+func (x *ListLightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsResponse.ProtoReflect.Descriptor instead.
+func (*ListLightsResponse) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListLightsResponse) GetLights() []*Light {
+	if x != nil {
+		return x.Lights
+	}
+	return nil
+}
+
+type SetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	State         *State                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStateRequest) Reset() {
+	*x = SetStateRequest{}
+	mi := &file_lifx_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStateRequest) ProtoMessage() {}
+
+func (x *SetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStateRequest.ProtoReflect.Descriptor instead.
+func (*SetStateRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetStateRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *SetStateRequest) GetState() *State {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type StateWithSelector struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	State         *State                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateWithSelector) Reset() {
+	*x = StateWithSelector{}
+	mi := &file_lifx_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateWithSelector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateWithSelector) ProtoMessage() {}
+
+func (x *StateWithSelector) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateWithSelector.ProtoReflect.Descriptor instead.
+func (*StateWithSelector) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StateWithSelector) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *StateWithSelector) GetState() *State {
+	if x != nil {
+		return x.State
+	}
+	return nil
+}
+
+type SetStatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	States        []*StateWithSelector   `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
+	Defaults      *State                 `protobuf:"bytes,2,opt,name=defaults,proto3" json:"defaults,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStatesRequest) Reset() {
+	*x = SetStatesRequest{}
+	mi := &file_lifx_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStatesRequest) ProtoMessage() {}
+
+func (x *SetStatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStatesRequest.ProtoReflect.Descriptor instead.
+func (*SetStatesRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetStatesRequest) GetStates() []*StateWithSelector {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *SetStatesRequest) GetDefaults() *State {
+	if x != nil {
+		return x.Defaults
+	}
+	return nil
+}
+
+type ToggleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	Duration      float64                `protobuf:"fixed64,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleRequest) Reset() {
+	*x = ToggleRequest{}
+	mi := &file_lifx_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleRequest) ProtoMessage() {}
+
+func (x *ToggleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleRequest.ProtoReflect.Descriptor instead.
+func (*ToggleRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ToggleRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *ToggleRequest) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type BreatheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	Color         string                 `protobuf:"bytes,2,opt,name=color,proto3" json:"color,omitempty"`
+	FromColor     string                 `protobuf:"bytes,3,opt,name=from_color,json=fromColor,proto3" json:"from_color,omitempty"`
+	Period        float64                `protobuf:"fixed64,4,opt,name=period,proto3" json:"period,omitempty"`
+	Cycles        float64                `protobuf:"fixed64,5,opt,name=cycles,proto3" json:"cycles,omitempty"`
+	Persist       bool                   `protobuf:"varint,6,opt,name=persist,proto3" json:"persist,omitempty"`
+	PowerOn       bool                   `protobuf:"varint,7,opt,name=power_on,json=powerOn,proto3" json:"power_on,omitempty"`
+	Peak          float64                `protobuf:"fixed64,8,opt,name=peak,proto3" json:"peak,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BreatheRequest) Reset() {
+	*x = BreatheRequest{}
+	mi := &file_lifx_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BreatheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BreatheRequest) ProtoMessage() {}
+
+func (x *BreatheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BreatheRequest.ProtoReflect.Descriptor instead.
+func (*BreatheRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BreatheRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *BreatheRequest) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *BreatheRequest) GetFromColor() string {
+	if x != nil {
+		return x.FromColor
+	}
+	return ""
+}
+
+func (x *BreatheRequest) GetPeriod() float64 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *BreatheRequest) GetCycles() float64 {
+	if x != nil {
+		return x.Cycles
+	}
+	return 0
+}
+
+func (x *BreatheRequest) GetPersist() bool {
+	if x != nil {
+		return x.Persist
+	}
+	return false
+}
+
+func (x *BreatheRequest) GetPowerOn() bool {
+	if x != nil {
+		return x.PowerOn
+	}
+	return false
+}
+
+func (x *BreatheRequest) GetPeak() float64 {
+	if x != nil {
+		return x.Peak
+	}
+	return 0
+}
+
+type Scene struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Scene) Reset() {
+	*x = Scene{}
+	mi := &file_lifx_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scene) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scene) ProtoMessage() {}
+
+func (x *Scene) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scene.ProtoReflect.Descriptor instead.
+func (*Scene) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Scene) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Scene) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListScenesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScenesRequest) Reset() {
+	*x = ListScenesRequest{}
+	mi := &file_lifx_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScenesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScenesRequest) ProtoMessage() {}
+
+func (x *ListScenesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScenesRequest.ProtoReflect.Descriptor instead.
+func (*ListScenesRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{16}
+}
+
+type ListScenesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scenes        []*Scene               `protobuf:"bytes,1,rep,name=scenes,proto3" json:"scenes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScenesResponse) Reset() {
+	*x = ListScenesResponse{}
+	mi := &file_lifx_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScenesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScenesResponse) ProtoMessage() {}
+
+func (x *ListScenesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScenesResponse.ProtoReflect.Descriptor instead.
+func (*ListScenesResponse) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListScenesResponse) GetScenes() []*Scene {
+	if x != nil {
+		return x.Scenes
+	}
+	return nil
+}
+
+type ActivateSceneRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A scene UUID or name.
+	Scene         string   `protobuf:"bytes,1,opt,name=scene,proto3" json:"scene,omitempty"`
+	Duration      float64  `protobuf:"fixed64,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Ignore        []string `protobuf:"bytes,3,rep,name=ignore,proto3" json:"ignore,omitempty"`
+	Fast          bool     `protobuf:"varint,4,opt,name=fast,proto3" json:"fast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivateSceneRequest) Reset() {
+	*x = ActivateSceneRequest{}
+	mi := &file_lifx_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivateSceneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivateSceneRequest) ProtoMessage() {}
+
+func (x *ActivateSceneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivateSceneRequest.ProtoReflect.Descriptor instead.
+func (*ActivateSceneRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ActivateSceneRequest) GetScene() string {
+	if x != nil {
+		return x.Scene
+	}
+	return ""
+}
+
+func (x *ActivateSceneRequest) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *ActivateSceneRequest) GetIgnore() []string {
+	if x != nil {
+		return x.Ignore
+	}
+	return nil
+}
+
+func (x *ActivateSceneRequest) GetFast() bool {
+	if x != nil {
+		return x.Fast
+	}
+	return false
+}
+
+type WatchRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Selector string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	// Polling interval in seconds, defaulting to the watcher's.
+	Interval      float64 `protobuf:"fixed64,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_lifx_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetInterval() float64 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+type Change struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Old           string                 `protobuf:"bytes,2,opt,name=old,proto3" json:"old,omitempty"`
+	New           string                 `protobuf:"bytes,3,opt,name=new,proto3" json:"new,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Change) Reset() {
+	*x = Change{}
+	mi := &file_lifx_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Change) ProtoMessage() {}
+
+func (x *Change) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Change.ProtoReflect.Descriptor instead.
+func (*Change) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Change) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Change) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *Change) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  Event_Type             `protobuf:"varint,1,opt,name=type,proto3,enum=lifx.v1.Event_Type" json:"type,omitempty"`
+	// RFC 3339.
+	Time          string    `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Light         *Light    `protobuf:"bytes,3,opt,name=light,proto3" json:"light,omitempty"`
+	Changes       []*Change `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	Error         string    `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_lifx_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_lifx_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_lifx_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Event) GetType() Event_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Event_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *Event) GetLight() *Light {
+	if x != nil {
+		return x.Light
+	}
+	return nil
+}
+
+func (x *Event) GetChanges() []*Change {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *Event) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_lifx_proto protoreflect.FileDescriptor
+
+const file_lifx_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"lifx.proto\x12\alifx.v1\"\xb6\x01\n" +
+	"\x05Color\x12\x15\n" +
+	"\x03hue\x18\x01 \x01(\x02H\x00R\x03hue\x88\x01\x01\x12#\n" +
+	"\n" +
+	"saturation\x18\x02 \x01(\x02H\x01R\n" +
+	"saturation\x88\x01\x01\x12#\n" +
+	"\n" +
+	"brightness\x18\x03 \x01(\x02H\x02R\n" +
+	"brightness\x88\x01\x01\x12\x1b\n" +
+	"\x06kelvin\x18\x04 \x01(\x05H\x03R\x06kelvin\x88\x01\x01B\x06\n" +
+	"\x04_hueB\r\n" +
+	"\v_saturationB\r\n" +
+	"\v_brightnessB\t\n" +
+	"\a_kelvin\"+\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xb1\x02\n" +
+	"\fCapabilities\x12\x1b\n" +
+	"\thas_color\x18\x01 \x01(\bR\bhasColor\x125\n" +
+	"\x17has_variable_color_temp\x18\x02 \x01(\bR\x14hasVariableColorTemp\x12\x15\n" +
+	"\x06has_ir\x18\x03 \x01(\bR\x05hasIr\x12\x17\n" +
+	"\ahas_hev\x18\x04 \x01(\bR\x06hasHev\x12\x1b\n" +
+	"\thas_chain\x18\x05 \x01(\bR\bhasChain\x12\x1d\n" +
+	"\n" +
+	"has_matrix\x18\x06 \x01(\bR\thasMatrix\x12#\n" +
+	"\rhas_multizone\x18\a \x01(\bR\fhasMultizone\x12\x1d\n" +
+	"\n" +
+	"min_kelvin\x18\b \x01(\x01R\tminKelvin\x12\x1d\n" +
+	"\n" +
+	"max_kelvin\x18\t \x01(\x01R\tmaxKelvin\"\xce\x01\n" +
+	"\aProduct\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"identifier\x18\x02 \x01(\tR\n" +
+	"identifier\x12\x18\n" +
+	"\acompany\x18\x03 \x01(\tR\acompany\x12\x1b\n" +
+	"\tvendor_id\x18\x04 \x01(\x05R\bvendorId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x05 \x01(\x05R\tproductId\x129\n" +
+	"\fcapabilities\x18\x06 \x01(\v2\x15.lifx.v1.CapabilitiesR\fcapabilities\"\x9c\x03\n" +
+	"\x05Light\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04uuid\x18\x02 \x01(\tR\x04uuid\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12\x1c\n" +
+	"\tconnected\x18\x04 \x01(\bR\tconnected\x12\x14\n" +
+	"\x05power\x18\x05 \x01(\tR\x05power\x12$\n" +
+	"\x05color\x18\x06 \x01(\v2\x0e.lifx.v1.ColorR\x05color\x12\x1e\n" +
+	"\n" +
+	"brightness\x18\a \x01(\x01R\n" +
+	"brightness\x12\x16\n" +
+	"\x06effect\x18\b \x01(\tR\x06effect\x12$\n" +
+	"\x05group\x18\t \x01(\v2\x0e.lifx.v1.GroupR\x05group\x12*\n" +
+	"\blocation\x18\n" +
+	" \x01(\v2\x0e.lifx.v1.GroupR\blocation\x12*\n" +
+	"\aproduct\x18\v \x01(\v2\x10.lifx.v1.ProductR\aproduct\x12\x1b\n" +
+	"\tlast_seen\x18\f \x01(\tR\blastSeen\x12,\n" +
+	"\x12seconds_since_seen\x18\r \x01(\x01R\x10secondsSinceSeen\"\xc5\x01\n" +
+	"\x05State\x12\x14\n" +
+	"\x05power\x18\x01 \x01(\tR\x05power\x12\x14\n" +
+	"\x05color\x18\x02 \x01(\tR\x05color\x12#\n" +
+	"\n" +
+	"brightness\x18\x03 \x01(\x01H\x00R\n" +
+	"brightness\x88\x01\x01\x12\x1a\n" +
+	"\bduration\x18\x04 \x01(\x01R\bduration\x12\x1f\n" +
+	"\binfrared\x18\x05 \x01(\x01H\x01R\binfrared\x88\x01\x01\x12\x12\n" +
+	"\x04fast\x18\x06 \x01(\bR\x04fastB\r\n" +
+	"\v_brightnessB\v\n" +
+	"\t_infrared\"F\n" +
+	"\x06Result\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"X\n" +
+	"\x0fResultsResponse\x12)\n" +
+	"\aresults\x18\x01 \x03(\v2\x0f.lifx.v1.ResultR\aresults\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\"/\n" +
+	"\x11ListLightsRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\"<\n" +
+	"\x12ListLightsResponse\x12&\n" +
+	"\x06lights\x18\x01 \x03(\v2\x0e.lifx.v1.LightR\x06lights\"S\n" +
+	"\x0fSetStateRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12$\n" +
+	"\x05state\x18\x02 \x01(\v2\x0e.lifx.v1.StateR\x05state\"U\n" +
+	"\x11StateWithSelector\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12$\n" +
+	"\x05state\x18\x02 \x01(\v2\x0e.lifx.v1.StateR\x05state\"r\n" +
+	"\x10SetStatesRequest\x122\n" +
+	"\x06states\x18\x01 \x03(\v2\x1a.lifx.v1.StateWithSelectorR\x06states\x12*\n" +
+	"\bdefaults\x18\x02 \x01(\v2\x0e.lifx.v1.StateR\bdefaults\"G\n" +
+	"\rToggleRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12\x1a\n" +
+	"\bduration\x18\x02 \x01(\x01R\bduration\"\xda\x01\n" +
+	"\x0eBreatheRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12\x14\n" +
+	"\x05color\x18\x02 \x01(\tR\x05color\x12\x1d\n" +
+	"\n" +
+	"from_color\x18\x03 \x01(\tR\tfromColor\x12\x16\n" +
+	"\x06period\x18\x04 \x01(\x01R\x06period\x12\x16\n" +
+	"\x06cycles\x18\x05 \x01(\x01R\x06cycles\x12\x18\n" +
+	"\apersist\x18\x06 \x01(\bR\apersist\x12\x19\n" +
+	"\bpower_on\x18\a \x01(\bR\apowerOn\x12\x12\n" +
+	"\x04peak\x18\b \x01(\x01R\x04peak\"/\n" +
+	"\x05Scene\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x13\n" +
+	"\x11ListScenesRequest\"<\n" +
+	"\x12ListScenesResponse\x12&\n" +
+	"\x06scenes\x18\x01 \x03(\v2\x0e.lifx.v1.SceneR\x06scenes\"t\n" +
+	"\x14ActivateSceneRequest\x12\x14\n" +
+	"\x05scene\x18\x01 \x01(\tR\x05scene\x12\x1a\n" +
+	"\bduration\x18\x02 \x01(\x01R\bduration\x12\x16\n" +
+	"\x06ignore\x18\x03 \x03(\tR\x06ignore\x12\x12\n" +
+	"\x04fast\x18\x04 \x01(\bR\x04fast\"F\n" +
+	"\fWatchRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\x01R\binterval\"B\n" +
+	"\x06Change\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x10\n" +
+	"\x03old\x18\x02 \x01(\tR\x03old\x12\x10\n" +
+	"\x03new\x18\x03 \x01(\tR\x03new\"\x91\x02\n" +
+	"\x05Event\x12'\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x13.lifx.v1.Event.TypeR\x04type\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\tR\x04time\x12$\n" +
+	"\x05light\x18\x03 \x01(\v2\x0e.lifx.v1.LightR\x05light\x12)\n" +
+	"\achanges\x18\x04 \x03(\v2\x0f.lifx.v1.ChangeR\achanges\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"d\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vLIGHT_ADDED\x10\x01\x12\x11\n" +
+	"\rLIGHT_REMOVED\x10\x02\x12\x11\n" +
+	"\rLIGHT_CHANGED\x10\x03\x12\x0f\n" +
+	"\vWATCH_ERROR\x10\x042\x8c\x04\n" +
+	"\x04Lifx\x12E\n" +
+	"\n" +
+	"ListLights\x12\x1a.lifx.v1.ListLightsRequest\x1a\x1b.lifx.v1.ListLightsResponse\x12>\n" +
+	"\bSetState\x12\x18.lifx.v1.SetStateRequest\x1a\x18.lifx.v1.ResultsResponse\x12@\n" +
+	"\tSetStates\x12\x19.lifx.v1.SetStatesRequest\x1a\x18.lifx.v1.ResultsResponse\x12:\n" +
+	"\x06Toggle\x12\x16.lifx.v1.ToggleRequest\x1a\x18.lifx.v1.ResultsResponse\x12<\n" +
+	"\aBreathe\x12\x17.lifx.v1.BreatheRequest\x1a\x18.lifx.v1.ResultsResponse\x12E\n" +
+	"\n" +
+	"ListScenes\x12\x1a.lifx.v1.ListScenesRequest\x1a\x1b.lifx.v1.ListScenesResponse\x12H\n" +
+	"\rActivateScene\x12\x1d.lifx.v1.ActivateSceneRequest\x1a\x18.lifx.v1.ResultsResponse\x120\n" +
+	"\x05Watch\x12\x15.lifx.v1.WatchRequest\x1a\x0e.lifx.v1.Event0\x01B+Z)git.kill0.net/chill9/lifx-go/proto/lifxv1b\x06proto3"
+
+var (
+	file_lifx_proto_rawDescOnce sync.Once
+	file_lifx_proto_rawDescData []byte
+)
+
+func file_lifx_proto_rawDescGZIP() []byte {
+	file_lifx_proto_rawDescOnce.Do(func() {
+		file_lifx_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_lifx_proto_rawDesc), len(file_lifx_proto_rawDesc)))
+	})
+	return file_lifx_proto_rawDescData
+}
+
+var file_lifx_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_lifx_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_lifx_proto_goTypes = []any{
+	(Event_Type)(0),              // 0: lifx.v1.Event.Type
+	(*Color)(nil),                // 1: lifx.v1.Color
+	(*Group)(nil),                // 2: lifx.v1.Group
+	(*Capabilities)(nil),         // 3: lifx.v1.Capabilities
+	(*Product)(nil),              // 4: lifx.v1.Product
+	(*Light)(nil),                // 5: lifx.v1.Light
+	(*State)(nil),                // 6: lifx.v1.State
+	(*Result)(nil),               // 7: lifx.v1.Result
+	(*ResultsResponse)(nil),      // 8: lifx.v1.ResultsResponse
+	(*ListLightsRequest)(nil),    // 9: lifx.v1.ListLightsRequest
+	(*ListLightsResponse)(nil),   // 10: lifx.v1.ListLightsResponse
+	(*SetStateRequest)(nil),      // 11: lifx.v1.SetStateRequest
+	(*StateWithSelector)(nil),    // 12: lifx.v1.StateWithSelector
+	(*SetStatesRequest)(nil),     // 13: lifx.v1.SetStatesRequest
+	(*ToggleRequest)(nil),        // 14: lifx.v1.ToggleRequest
+	(*BreatheRequest)(nil),       // 15: lifx.v1.BreatheRequest
+	(*Scene)(nil),                // 16: lifx.v1.Scene
+	(*ListScenesRequest)(nil),    // 17: lifx.v1.ListScenesRequest
+	(*ListScenesResponse)(nil),   // 18: lifx.v1.ListScenesResponse
+	(*ActivateSceneRequest)(nil), // 19: lifx.v1.ActivateSceneRequest
+	(*WatchRequest)(nil),         // 20: lifx.v1.WatchRequest
+	(*Change)(nil),               // 21: lifx.v1.Change
+	(*Event)(nil),                // 22: lifx.v1.Event
+}
+var file_lifx_proto_depIdxs = []int32{
+	3,  // 0: lifx.v1.Product.capabilities:type_name -> lifx.v1.Capabilities
+	1,  // 1: lifx.v1.Light.color:type_name -> lifx.v1.Color
+	2,  // 2: lifx.v1.Light.group:type_name -> lifx.v1.Group
+	2,  // 3: lifx.v1.Light.location:type_name -> lifx.v1.Group
+	4,  // 4: lifx.v1.Light.product:type_name -> lifx.v1.Product
+	7,  // 5: lifx.v1.ResultsResponse.results:type_name -> lifx.v1.Result
+	5,  // 6: lifx.v1.ListLightsResponse.lights:type_name -> lifx.v1.Light
+	6,  // 7: lifx.v1.SetStateRequest.state:type_name -> lifx.v1.State
+	6,  // 8: lifx.v1.StateWithSelector.state:type_name -> lifx.v1.State
+	12, // 9: lifx.v1.SetStatesRequest.states:type_name -> lifx.v1.StateWithSelector
+	6,  // 10: lifx.v1.SetStatesRequest.defaults:type_name -> lifx.v1.State
+	16, // 11: lifx.v1.ListScenesResponse.scenes:type_name -> lifx.v1.Scene
+	0,  // 12: lifx.v1.Event.type:type_name -> lifx.v1.Event.Type
+	5,  // 13: lifx.v1.Event.light:type_name -> lifx.v1.Light
+	21, // 14: lifx.v1.Event.changes:type_name -> lifx.v1.Change
+	9,  // 15: lifx.v1.Lifx.ListLights:input_type -> lifx.v1.ListLightsRequest
+	11, // 16: lifx.v1.Lifx.SetState:input_type -> lifx.v1.SetStateRequest
+	13, // 17: lifx.v1.Lifx.SetStates:input_type -> lifx.v1.SetStatesRequest
+	14, // 18: lifx.v1.Lifx.Toggle:input_type -> lifx.v1.ToggleRequest
+	15, // 19: lifx.v1.Lifx.Breathe:input_type -> lifx.v1.BreatheRequest
+	17, // 20: lifx.v1.Lifx.ListScenes:input_type -> lifx.v1.ListScenesRequest
+	19, // 21: lifx.v1.Lifx.ActivateScene:input_type -> lifx.v1.ActivateSceneRequest
+	20, // 22: lifx.v1.Lifx.Watch:input_type -> lifx.v1.WatchRequest
+	10, // 23: lifx.v1.Lifx.ListLights:output_type -> lifx.v1.ListLightsResponse
+	8,  // 24: lifx.v1.Lifx.SetState:output_type -> lifx.v1.ResultsResponse
+	8,  // 25: lifx.v1.Lifx.SetStates:output_type -> lifx.v1.ResultsResponse
+	8,  // 26: lifx.v1.Lifx.Toggle:output_type -> lifx.v1.ResultsResponse
+	8,  // 27: lifx.v1.Lifx.Breathe:output_type -> lifx.v1.ResultsResponse
+	18, // 28: lifx.v1.Lifx.ListScenes:output_type -> lifx.v1.ListScenesResponse
+	8,  // 29: lifx.v1.Lifx.ActivateScene:output_type -> lifx.v1.ResultsResponse
+	22, // 30: lifx.v1.Lifx.Watch:output_type -> lifx.v1.Event
+	23, // [23:31] is the sub-list for method output_type
+	15, // [15:23] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_lifx_proto_init() }
+func file_lifx_proto_init() {
+	if File_lifx_proto != nil {
+		return
+	}
+	file_lifx_proto_msgTypes[0].OneofWrappers = []any{}
+	file_lifx_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_lifx_proto_rawDesc), len(file_lifx_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lifx_proto_goTypes,
+		DependencyIndexes: file_lifx_proto_depIdxs,
+		EnumInfos:         file_lifx_proto_enumTypes,
+		MessageInfos:      file_lifx_proto_msgTypes,
+	}.Build()
+	File_lifx_proto = out.File
+	file_lifx_proto_goTypes = nil
+	file_lifx_proto_depIdxs = nil
+}
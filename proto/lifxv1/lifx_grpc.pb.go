@@ -0,0 +1,400 @@
+// Service definition for sharing one rate-limited LIFX client with services
+// written in other languages. Messages mirror the types in the lifx package.
+//
+// The generated code, a server delegating to a lifx.Client and the
+// lifx-grpc command live in this directory's own module, which keeps gRPC
+// out of the root module's dependencies. Regenerate with go generate.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: lifx.proto
+
+package lifxv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Lifx_ListLights_FullMethodName    = "/lifx.v1.Lifx/ListLights"
+	Lifx_SetState_FullMethodName      = "/lifx.v1.Lifx/SetState"
+	Lifx_SetStates_FullMethodName     = "/lifx.v1.Lifx/SetStates"
+	Lifx_Toggle_FullMethodName        = "/lifx.v1.Lifx/Toggle"
+	Lifx_Breathe_FullMethodName       = "/lifx.v1.Lifx/Breathe"
+	Lifx_ListScenes_FullMethodName    = "/lifx.v1.Lifx/ListScenes"
+	Lifx_ActivateScene_FullMethodName = "/lifx.v1.Lifx/ActivateScene"
+	Lifx_Watch_FullMethodName         = "/lifx.v1.Lifx/Watch"
+)
+
+// LifxClient is the client API for Lifx service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LifxClient interface {
+	ListLights(ctx context.Context, in *ListLightsRequest, opts ...grpc.CallOption) (*ListLightsResponse, error)
+	SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
+	SetStates(ctx context.Context, in *SetStatesRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
+	Toggle(ctx context.Context, in *ToggleRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
+	Breathe(ctx context.Context, in *BreatheRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
+	ListScenes(ctx context.Context, in *ListScenesRequest, opts ...grpc.CallOption) (*ListScenesResponse, error)
+	ActivateScene(ctx context.Context, in *ActivateSceneRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
+	// Watch streams changes to the selected lights as the watcher sees them.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type lifxClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLifxClient(cc grpc.ClientConnInterface) LifxClient {
+	return &lifxClient{cc}
+}
+
+func (c *lifxClient) ListLights(ctx context.Context, in *ListLightsRequest, opts ...grpc.CallOption) (*ListLightsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLightsResponse)
+	err := c.cc.Invoke(ctx, Lifx_ListLights_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsResponse)
+	err := c.cc.Invoke(ctx, Lifx_SetState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) SetStates(ctx context.Context, in *SetStatesRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsResponse)
+	err := c.cc.Invoke(ctx, Lifx_SetStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) Toggle(ctx context.Context, in *ToggleRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsResponse)
+	err := c.cc.Invoke(ctx, Lifx_Toggle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) Breathe(ctx context.Context, in *BreatheRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsResponse)
+	err := c.cc.Invoke(ctx, Lifx_Breathe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) ListScenes(ctx context.Context, in *ListScenesRequest, opts ...grpc.CallOption) (*ListScenesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScenesResponse)
+	err := c.cc.Invoke(ctx, Lifx_ListScenes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) ActivateScene(ctx context.Context, in *ActivateSceneRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsResponse)
+	err := c.cc.Invoke(ctx, Lifx_ActivateScene_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lifxClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Lifx_ServiceDesc.Streams[0], Lifx_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lifx_WatchClient = grpc.ServerStreamingClient[Event]
+
+// LifxServer is the server API for Lifx service.
+// All implementations must embed UnimplementedLifxServer
+// for forward compatibility.
+type LifxServer interface {
+	ListLights(context.Context, *ListLightsRequest) (*ListLightsResponse, error)
+	SetState(context.Context, *SetStateRequest) (*ResultsResponse, error)
+	SetStates(context.Context, *SetStatesRequest) (*ResultsResponse, error)
+	Toggle(context.Context, *ToggleRequest) (*ResultsResponse, error)
+	Breathe(context.Context, *BreatheRequest) (*ResultsResponse, error)
+	ListScenes(context.Context, *ListScenesRequest) (*ListScenesResponse, error)
+	ActivateScene(context.Context, *ActivateSceneRequest) (*ResultsResponse, error)
+	// Watch streams changes to the selected lights as the watcher sees them.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedLifxServer()
+}
+
+// UnimplementedLifxServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLifxServer struct{}
+
+func (UnimplementedLifxServer) ListLights(context.Context, *ListLightsRequest) (*ListLightsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLights not implemented")
+}
+func (UnimplementedLifxServer) SetState(context.Context, *SetStateRequest) (*ResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetState not implemented")
+}
+func (UnimplementedLifxServer) SetStates(context.Context, *SetStatesRequest) (*ResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetStates not implemented")
+}
+func (UnimplementedLifxServer) Toggle(context.Context, *ToggleRequest) (*ResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Toggle not implemented")
+}
+func (UnimplementedLifxServer) Breathe(context.Context, *BreatheRequest) (*ResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Breathe not implemented")
+}
+func (UnimplementedLifxServer) ListScenes(context.Context, *ListScenesRequest) (*ListScenesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScenes not implemented")
+}
+func (UnimplementedLifxServer) ActivateScene(context.Context, *ActivateSceneRequest) (*ResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateScene not implemented")
+}
+func (UnimplementedLifxServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedLifxServer) mustEmbedUnimplementedLifxServer() {}
+func (UnimplementedLifxServer) testEmbeddedByValue()              {}
+
+// UnsafeLifxServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LifxServer will
+// result in compilation errors.
+type UnsafeLifxServer interface {
+	mustEmbedUnimplementedLifxServer()
+}
+
+func RegisterLifxServer(s grpc.ServiceRegistrar, srv LifxServer) {
+	// If the following call pancis, it indicates UnimplementedLifxServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Lifx_ServiceDesc, srv)
+}
+
+func _Lifx_ListLights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).ListLights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_ListLights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).ListLights(ctx, req.(*ListLightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_SetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).SetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_SetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).SetState(ctx, req.(*SetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_SetStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).SetStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_SetStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).SetStates(ctx, req.(*SetStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_Toggle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ToggleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).Toggle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_Toggle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).Toggle(ctx, req.(*ToggleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_Breathe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BreatheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).Breathe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_Breathe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).Breathe(ctx, req.(*BreatheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_ListScenes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScenesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).ListScenes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_ListScenes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).ListScenes(ctx, req.(*ListScenesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_ActivateScene_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateSceneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LifxServer).ActivateScene(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Lifx_ActivateScene_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LifxServer).ActivateScene(ctx, req.(*ActivateSceneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lifx_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LifxServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Lifx_WatchServer = grpc.ServerStreamingServer[Event]
+
+// Lifx_ServiceDesc is the grpc.ServiceDesc for Lifx service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Lifx_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lifx.v1.Lifx",
+	HandlerType: (*LifxServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLights",
+			Handler:    _Lifx_ListLights_Handler,
+		},
+		{
+			MethodName: "SetState",
+			Handler:    _Lifx_SetState_Handler,
+		},
+		{
+			MethodName: "SetStates",
+			Handler:    _Lifx_SetStates_Handler,
+		},
+		{
+			MethodName: "Toggle",
+			Handler:    _Lifx_Toggle_Handler,
+		},
+		{
+			MethodName: "Breathe",
+			Handler:    _Lifx_Breathe_Handler,
+		},
+		{
+			MethodName: "ListScenes",
+			Handler:    _Lifx_ListScenes_Handler,
+		},
+		{
+			MethodName: "ActivateScene",
+			Handler:    _Lifx_ActivateScene_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Lifx_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lifx.proto",
+}
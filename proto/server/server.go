@@ -0,0 +1,324 @@
+// Package server serves the Lifx gRPC service defined in lifx.proto by
+// delegating to a lifx.Client, so that every caller shares its rate limit,
+// retries and caches.
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/proto/lifxv1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements lifxv1.LifxServer. Register it with
+// lifxv1.RegisterLifxServer.
+type Server struct {
+	lifxv1.UnimplementedLifxServer
+	client *lifx.Client
+}
+
+var _ lifxv1.LifxServer = (*Server)(nil)
+
+func New(c *lifx.Client) *Server {
+	return &Server{client: c}
+}
+
+func (s *Server) ListLights(ctx context.Context, req *lifxv1.ListLightsRequest) (*lifxv1.ListLightsResponse, error) {
+	lights, err := s.client.ListLights(selector(req.GetSelector()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &lifxv1.ListLightsResponse{}
+	for _, l := range lights {
+		resp.Lights = append(resp.Lights, light(l))
+	}
+	return resp, nil
+}
+
+func (s *Server) SetState(ctx context.Context, req *lifxv1.SetStateRequest) (*lifxv1.ResultsResponse, error) {
+	r, err := s.client.SetState(selector(req.GetSelector()), state(req.GetState()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return results(r), nil
+}
+
+func (s *Server) SetStates(ctx context.Context, req *lifxv1.SetStatesRequest) (*lifxv1.ResultsResponse, error) {
+	states := lifx.States{Defaults: state(req.GetDefaults())}
+	for _, st := range req.GetStates() {
+		states.States = append(states.States, lifx.StateWithSelector{
+			State:    state(st.GetState()),
+			Selector: st.GetSelector(),
+		})
+	}
+
+	r, err := s.client.SetStates("", states)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &lifxv1.ResultsResponse{Warnings: warnings(r.Warnings)}
+	for _, op := range r.Results {
+		resp.Results = append(resp.Results, results(&lifx.LifxResponse{Results: op.Results}).Results...)
+	}
+	return resp, nil
+}
+
+func (s *Server) Toggle(ctx context.Context, req *lifxv1.ToggleRequest) (*lifxv1.ResultsResponse, error) {
+	r, err := s.client.Toggle(selector(req.GetSelector()), req.GetDuration())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return results(r), nil
+}
+
+func (s *Server) Breathe(ctx context.Context, req *lifxv1.BreatheRequest) (*lifxv1.ResultsResponse, error) {
+	b := lifx.Breathe{
+		Period:  req.GetPeriod(),
+		Cycles:  req.GetCycles(),
+		Persist: req.GetPersist(),
+		PowerOn: req.GetPowerOn(),
+		Peak:    req.GetPeak(),
+	}
+	if req.GetColor() != "" {
+		b.Color = lifx.NamedColor(req.GetColor())
+	}
+	if req.GetFromColor() != "" {
+		b.FromColor = lifx.NamedColor(req.GetFromColor())
+	}
+
+	r, err := s.client.Breathe(selector(req.GetSelector()), b)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return results(r), nil
+}
+
+func (s *Server) ListScenes(ctx context.Context, req *lifxv1.ListScenesRequest) (*lifxv1.ListScenesResponse, error) {
+	scenes, err := s.client.ListScenes()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &lifxv1.ListScenesResponse{}
+	for _, sc := range scenes {
+		resp.Scenes = append(resp.Scenes, &lifxv1.Scene{Uuid: sc.UUID, Name: sc.Name})
+	}
+	return resp, nil
+}
+
+func (s *Server) ActivateScene(ctx context.Context, req *lifxv1.ActivateSceneRequest) (*lifxv1.ResultsResponse, error) {
+	scenes, err := s.client.ListScenes()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	scene, err := lifx.FindScene(scenes, req.GetScene())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	r, err := s.client.ActivateScene(scene.UUID, lifx.Activate{
+		Duration: req.GetDuration(),
+		Ignore:   req.GetIgnore(),
+		Fast:     req.GetFast(),
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return results(r), nil
+}
+
+// Watch polls the selected lights for as long as the caller stays
+// connected, sending each change as it is seen. Every stream polls on its
+// own, so each one counts against the client's rate limit.
+func (s *Server) Watch(req *lifxv1.WatchRequest, stream lifxv1.Lifx_WatchServer) error {
+	var options []func(*lifx.Watcher)
+	if req.GetInterval() > 0 {
+		options = append(options, lifx.WithInterval(time.Duration(req.GetInterval()*float64(time.Second))))
+	}
+	w := lifx.NewWatcher(s.client, selector(req.GetSelector()), options...)
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// Run only stops once ctx is done, when the caller went away or a send
+	// failed.
+	var sendErr error
+	w.Run(ctx, func(e lifx.Event) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(event(e)); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return status.FromContextError(stream.Context().Err()).Err()
+}
+
+// selector defaults an empty selector to every light, as the API does for
+// a missing one.
+func selector(s string) string {
+	if s == "" {
+		return "all"
+	}
+	return s
+}
+
+func state(st *lifxv1.State) lifx.State {
+	s := lifx.State{
+		Power:      st.GetPower(),
+		Brightness: st.GetBrightness(),
+		Duration:   st.GetDuration(),
+		Infrared:   st.GetInfrared(),
+		Fast:       st.GetFast(),
+	}
+	if st.GetColor() != "" {
+		s.Color = lifx.NamedColor(st.GetColor())
+	}
+	return s
+}
+
+func results(r *lifx.LifxResponse) *lifxv1.ResultsResponse {
+	resp := &lifxv1.ResultsResponse{}
+	if r == nil {
+		return resp
+	}
+
+	resp.Warnings = warnings(r.Warnings)
+	for _, res := range r.Results {
+		resp.Results = append(resp.Results, &lifxv1.Result{Id: res.Id, Label: res.Label, Status: string(res.Status)})
+	}
+	return resp
+}
+
+func warnings(ws []lifx.Warning) []string {
+	var s []string
+	for _, w := range ws {
+		s = append(s, w.Warning)
+	}
+	return s
+}
+
+func light(l lifx.Light) *lifxv1.Light {
+	c := l.Product.Capabilities
+	pl := &lifxv1.Light{
+		Id:         l.Id,
+		Uuid:       l.UUID,
+		Label:      l.Label,
+		Connected:  l.Connected,
+		Power:      l.Power,
+		Color:      color(l.Color),
+		Brightness: l.Brightness,
+		Effect:     l.Effect.String(),
+		Group:      &lifxv1.Group{Id: l.Group.Id, Name: l.Group.Name},
+		Location:   &lifxv1.Group{Id: l.Location.Id, Name: l.Location.Name},
+		Product: &lifxv1.Product{
+			Name:       l.Product.Name,
+			Identifier: l.Product.Identifier,
+			Company:    l.Product.Company,
+			VendorId:   int32(l.Product.VendorId),
+			ProductId:  int32(l.Product.ProductId),
+			Capabilities: &lifxv1.Capabilities{
+				HasColor:             c.HasColor,
+				HasVariableColorTemp: c.HasVariableColorTemp,
+				HasIr:                c.HasIR,
+				HasHev:               c.HasHEV,
+				HasChain:             c.HasChain,
+				HasMatrix:            c.HasMatrix,
+				HasMultizone:         c.HasMultizone,
+				MinKelvin:            c.MinKelvin,
+				MaxKelvin:            c.MaxKelvin,
+			},
+		},
+		SecondsSinceSeen: l.SecondsLastSeen,
+	}
+	if !l.LastSeen.IsZero() {
+		pl.LastSeen = l.LastSeen.Format(time.RFC3339)
+	}
+	return pl
+}
+
+func color(c lifx.HSBKColor) *lifxv1.Color {
+	pc := &lifxv1.Color{Hue: c.H, Saturation: c.S, Brightness: c.B}
+	if c.K != nil {
+		k := int32(*c.K)
+		pc.Kelvin = &k
+	}
+	return pc
+}
+
+func event(e lifx.Event) *lifxv1.Event {
+	pe := &lifxv1.Event{Time: e.Time.Format(time.RFC3339)}
+
+	switch e.Type {
+	case lifx.LightAdded:
+		pe.Type = lifxv1.Event_LIGHT_ADDED
+	case lifx.LightRemoved:
+		pe.Type = lifxv1.Event_LIGHT_REMOVED
+	case lifx.LightChanged:
+		pe.Type = lifxv1.Event_LIGHT_CHANGED
+	case lifx.WatchError:
+		pe.Type = lifxv1.Event_WATCH_ERROR
+	}
+
+	if e.Err != nil {
+		pe.Error = e.Err.Error()
+	} else {
+		pe.Light = light(e.Light)
+	}
+	for _, c := range e.Changes {
+		pe.Changes = append(pe.Changes, &lifxv1.Change{Field: c.Field, Old: c.Old, New: c.New})
+	}
+	return pe
+}
+
+// grpcError gives err the status code of the API's status, so that callers
+// in other languages can tell a bad selector from an outage.
+func grpcError(err error) error {
+	var (
+		api *lifx.APIError
+		sel *lifx.SelectorError
+	)
+
+	code := codes.Unknown
+	switch {
+	case errors.As(err, &sel):
+		code = codes.NotFound
+	case errors.As(err, &api):
+		code = httpCode(api.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, lifx.ErrClientClosed):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+func httpCode(status int) codes.Code {
+	switch {
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case status == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case status == http.StatusForbidden:
+		return codes.PermissionDenied
+	case status == http.StatusNotFound:
+		return codes.NotFound
+	case status == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case status >= 500:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
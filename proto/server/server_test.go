@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+	"git.kill0.net/chill9/lifx-go/proto/lifxv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, lights ...lifx.Light) lifxv1.LifxClient {
+	t.Helper()
+
+	api := lifxtest.NewServer(lights...)
+	t.Cleanup(api.Close)
+
+	l := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	lifxv1.RegisterLifxServer(s, New(lifx.NewClient("x", lifxtest.WithServer(api))))
+	go s.Serve(l)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return lifxv1.NewLifxClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t,
+		lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"),
+		lifxtest.NewLight("d073d5000002", "Porch", "Outside", "Home"),
+	)
+
+	lights, err := c.ListLights(ctx, &lifxv1.ListLightsRequest{Selector: "group:Office"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lights.Lights) != 1 || lights.Lights[0].Label != "Desk" {
+		t.Fatalf("ListLights = %v, want Desk", lights.Lights)
+	}
+
+	bright := 0.5
+	r, err := c.SetState(ctx, &lifxv1.SetStateRequest{
+		Selector: "label:Porch",
+		State:    &lifxv1.State{Power: "on", Brightness: &bright},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Results) != 1 || r.Results[0].Id != "d073d5000002" {
+		t.Fatalf("SetState results = %v, want the porch light", r.Results)
+	}
+
+	lights, err = c.ListLights(ctx, &lifxv1.ListLightsRequest{Selector: "label:Porch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lights.Lights[0].Brightness; got != 0.5 {
+		t.Errorf("brightness after SetState = %v, want 0.5", got)
+	}
+}
+
+func TestServerErrorCode(t *testing.T) {
+	c := newTestClient(t, lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+
+	_, err := c.ListLights(context.Background(), &lifxv1.ListLightsRequest{Selector: "label:Nowhere"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("code = %v (%v), want NotFound", got, err)
+	}
+}
@@ -0,0 +1,408 @@
+package lifx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultQueueTTL is how long a queued command stays worth applying.
+var DefaultQueueTTL = 10 * time.Minute
+
+// Actions a QueuedCommand can carry.
+const (
+	ActionState   Action = "state"
+	ActionToggle  Action = "toggle"
+	ActionBreathe Action = "breathe"
+)
+
+var (
+	// ErrQueued is returned, wrapping the error that caused it if there
+	// was one, when a Queue keeps a command to send later.
+	ErrQueued = errors.New("command queued until the API is reachable")
+
+	// ErrExpired is reported for queued commands whose TTL ran out
+	// before they could be sent.
+	ErrExpired = errors.New("queued command expired")
+)
+
+type (
+	// Action is the kind of change a QueuedCommand makes.
+	Action string
+
+	// QueuedCommand is a change a Queue is holding on to. Only the field
+	// for its Action is used: State, the toggle's Duration or Breathe.
+	QueuedCommand struct {
+		Selector string
+		Action   Action
+		State    State
+		Duration float64
+		Breathe  Breathe
+		Queued   time.Time
+		Expires  time.Time
+		seq      uint64
+	}
+
+	// Replayed reports what became of a queued command. Err is ErrExpired
+	// for a command that was dropped, and otherwise the error sending it.
+	Replayed struct {
+		Command  QueuedCommand
+		Applied  time.Time
+		Response *LifxResponse
+		Err      error
+	}
+
+	// Queue is a Backend that stores changes while the API is unreachable
+	// and forwards them once it is back. Calls go straight to the backend
+	// while nothing is queued; once something is, later calls queue up
+	// behind it so that changes keep their order. Reads are never queued.
+	//
+	// A command for a selector already in the queue is collapsed into the
+	// one before it: states are merged, colors component by component, a
+	// toggle cancels out a toggle and a breathe replaces a breathe. The
+	// command being replayed has already gone out and is never collapsed
+	// into. Selectors are compared as written, so "all" and a light's id
+	// are queued independently.
+	Queue struct {
+		backend  Backend
+		ttl      time.Duration
+		onReplay func(Replayed)
+		clock    Clock
+		mu       sync.Mutex
+		replay   sync.Mutex
+		seq      uint64
+		commands []QueuedCommand
+		sending  *QueuedCommand
+	}
+)
+
+var _ Backend = (*Queue)(nil)
+
+func NewQueue(backend Backend, options ...func(*Queue)) *Queue {
+	q := &Queue{
+		backend: backend,
+		ttl:     DefaultQueueTTL,
+	}
+
+	for _, option := range options {
+		option(q)
+	}
+
+	return q
+}
+
+// WithQueueTTL drops queued commands that could not be sent within ttl,
+// since a change made long ago may no longer be wanted. A ttl of zero
+// keeps them until they are sent.
+func WithQueueTTL(ttl time.Duration) func(*Queue) {
+	return func(q *Queue) {
+		q.ttl = ttl
+	}
+}
+
+// WithQueueClock stamps, expires and replays commands by clock.
+func WithQueueClock(clock Clock) func(*Queue) {
+	return func(q *Queue) {
+		q.clock = clock
+	}
+}
+
+// WithReplayHandler calls fn with the outcome of each queued command as
+// it is replayed or dropped.
+func WithReplayHandler(fn func(Replayed)) func(*Queue) {
+	return func(q *Queue) {
+		q.onReplay = fn
+	}
+}
+
+// IsUnreachable reports whether err means the API could not be reached,
+// rather than that it refused the request: a network error, a timeout or
+// a server error.
+func IsUnreachable(err error) bool {
+	var ne net.Error
+
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, ErrServer), errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	return errors.As(err, &ne)
+}
+
+func (q *Queue) ListLights(selector string) ([]Light, error) {
+	return q.backend.ListLights(selector)
+}
+
+func (q *Queue) SetState(selector string, state State) (*LifxResponse, error) {
+	return q.send(QueuedCommand{Selector: selector, Action: ActionState, State: state})
+}
+
+func (q *Queue) Toggle(selector string, duration float64) (*LifxResponse, error) {
+	return q.send(QueuedCommand{Selector: selector, Action: ActionToggle, Duration: duration})
+}
+
+func (q *Queue) PowerOn(selector string) (*LifxResponse, error) {
+	return q.SetState(selector, State{Power: "on"})
+}
+
+func (q *Queue) PowerOff(selector string) (*LifxResponse, error) {
+	return q.SetState(selector, State{Power: "off"})
+}
+
+func (q *Queue) Breathe(selector string, breathe Breathe) (*LifxResponse, error) {
+	return q.send(QueuedCommand{Selector: selector, Action: ActionBreathe, Breathe: breathe})
+}
+
+// Pending returns the commands waiting to be sent, oldest first, starting
+// with any that is being replayed.
+func (q *Queue) Pending() []QueuedCommand {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending []QueuedCommand
+	if q.sending != nil {
+		pending = append(pending, *q.sending)
+	}
+	return append(pending, q.commands...)
+}
+
+// Len is the number of commands waiting to be sent, including any that
+// is being replayed.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.sending != nil {
+		return len(q.commands) + 1
+	}
+	return len(q.commands)
+}
+
+// Replay sends the queued commands in order until the queue is empty or
+// the API turns out to be unreachable again, and reports what became of
+// each one it took off the queue.
+func (q *Queue) Replay() []Replayed {
+	var done []Replayed
+
+	q.replay.Lock()
+	defer q.replay.Unlock()
+
+	for {
+		q.mu.Lock()
+		if len(q.commands) == 0 {
+			q.mu.Unlock()
+			return done
+		}
+		// The command comes off the queue before it is sent, so that one
+		// queued meanwhile can't collapse into it.
+		cmd := q.commands[0]
+		q.commands = q.commands[1:]
+		q.sending = &cmd
+		q.mu.Unlock()
+
+		var rep Replayed
+		if !cmd.Expires.IsZero() && q.now().After(cmd.Expires) {
+			rep = Replayed{Command: cmd, Err: ErrExpired}
+		} else {
+			r, err := q.apply(cmd)
+			if IsUnreachable(err) {
+				q.mu.Lock()
+				q.commands = append([]QueuedCommand{cmd}, q.commands...)
+				q.sending = nil
+				q.mu.Unlock()
+				return done
+			}
+			rep = Replayed{Command: cmd, Applied: q.now(), Response: r, Err: err}
+		}
+
+		q.mu.Lock()
+		q.sending = nil
+		q.mu.Unlock()
+
+		done = append(done, rep)
+		if q.onReplay != nil {
+			q.onReplay(rep)
+		}
+	}
+}
+
+// Run replays the queue every interval until ctx is done.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) error {
+	t := clockOrSystem(q.clock).NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C():
+			if q.Len() > 0 {
+				q.Replay()
+			}
+		}
+	}
+}
+
+// Delay is how long the command waited between being queued and applied.
+func (r Replayed) Delay() time.Duration {
+	if r.Applied.IsZero() {
+		return 0
+	}
+	return r.Applied.Sub(r.Command.Queued)
+}
+
+func (q *Queue) now() time.Time {
+	return clockOrSystem(q.clock).Now()
+}
+
+func (q *Queue) send(cmd QueuedCommand) (*LifxResponse, error) {
+	if q.Len() == 0 {
+		r, err := q.apply(cmd)
+		if !IsUnreachable(err) {
+			return r, err
+		}
+		q.enqueue(cmd)
+		return nil, fmt.Errorf("%w: %v", ErrQueued, err)
+	}
+
+	q.enqueue(cmd)
+	return nil, ErrQueued
+}
+
+func (q *Queue) apply(cmd QueuedCommand) (*LifxResponse, error) {
+	switch cmd.Action {
+	case ActionToggle:
+		return q.backend.Toggle(cmd.Selector, cmd.Duration)
+	case ActionBreathe:
+		return q.backend.Breathe(cmd.Selector, cmd.Breathe)
+	}
+	return q.backend.SetState(cmd.Selector, cmd.State)
+}
+
+func (q *Queue) enqueue(cmd QueuedCommand) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cmd.Queued = q.now()
+	if q.ttl > 0 {
+		cmd.Expires = cmd.Queued.Add(q.ttl)
+	}
+
+	for i := len(q.commands) - 1; i >= 0; i-- {
+		prev := q.commands[i]
+		if prev.Selector != cmd.Selector {
+			continue
+		}
+
+		merged, ok := collapse(prev, cmd)
+		if !ok {
+			break
+		}
+		q.remove(prev.seq)
+		if merged == nil {
+			return
+		}
+		cmd = *merged
+		break
+	}
+
+	q.seq++
+	cmd.seq = q.seq
+	q.commands = append(q.commands, cmd)
+}
+
+func (q *Queue) remove(seq uint64) {
+	for i, c := range q.commands {
+		if c.seq == seq {
+			q.commands = append(q.commands[:i], q.commands[i+1:]...)
+			return
+		}
+	}
+}
+
+// collapse combines prev with cmd, which follows it for the same
+// selector, into the command to queue in place of both, or nil if the two
+// cancel out. It returns false when both have to be sent.
+func collapse(prev, cmd QueuedCommand) (*QueuedCommand, bool) {
+	switch {
+	case prev.Action == ActionToggle && cmd.Action == ActionToggle:
+		return nil, true
+	case prev.Action == ActionState && cmd.Action == ActionState:
+		state, ok := mergeState(prev.State, cmd.State)
+		if !ok {
+			return nil, false
+		}
+		cmd.State = state
+		cmd.Queued = prev.Queued
+		return &cmd, true
+	case prev.Action == ActionBreathe && cmd.Action == ActionBreathe:
+		return &cmd, true
+	case prev.Action == ActionToggle && cmd.Action == ActionState && cmd.State.Power != "":
+		return &cmd, true
+	}
+	return nil, false
+}
+
+// mergeState sets the fields of b over those of a. A color only replaces
+// the components of a's that it sets, so that "red" followed by
+// "saturation:0.5" keeps the hue. It returns false if either color can't
+// be split into components.
+func mergeState(a, b State) (State, bool) {
+	s := a
+
+	if b.Power != "" {
+		s.Power = b.Power
+	}
+	if b.Color != nil {
+		c, err := ParseColor(colorString(b.Color))
+		if err != nil {
+			return State{}, false
+		}
+		// The brightness field would override the new color's.
+		if c.B != nil {
+			s.Brightness = 0
+		}
+		if a.Color != nil {
+			prev, err := ParseColor(colorString(a.Color))
+			if err != nil {
+				return State{}, false
+			}
+			c = mergeComponents(prev, c)
+		}
+		s.Color = c
+	}
+	if b.Brightness != 0 {
+		s.Brightness = b.Brightness
+	}
+	if b.Infrared != 0 {
+		s.Infrared = b.Infrared
+	}
+	s.Duration, s.Fast = b.Duration, b.Fast
+
+	return s, true
+}
+
+// mergeComponents sets the components c sets over those of prev. As in
+// the API, a kelvin without a saturation sets the saturation to zero.
+func mergeComponents(prev, c HSBKColor) HSBKColor {
+	if c.K != nil && c.S == nil {
+		c.S = Float32Ptr(0)
+	}
+	if c.H == nil && !c.RandomHue {
+		c.H, c.RandomHue = prev.H, prev.RandomHue
+	}
+	if c.S == nil {
+		c.S = prev.S
+	}
+	if c.B == nil {
+		c.B = prev.B
+	}
+	if c.K == nil {
+		c.K = prev.K
+	}
+	return c
+}
@@ -0,0 +1,174 @@
+package lifx_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestQueueClock(t *testing.T) {
+	clock := lifxtest.NewClock(time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC))
+	f := lifxtest.NewFakeClient(lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home"))
+	f.FailWith("SetState", lifx.ErrServer)
+	f.FailWith("Toggle", lifx.ErrServer)
+
+	q := lifx.NewQueue(f, lifx.WithQueueTTL(time.Minute), lifx.WithQueueClock(clock))
+	if _, err := q.Toggle("label:Desk", 0); !errors.Is(err, lifx.ErrQueued) {
+		t.Fatalf("Toggle err = %v, want ErrQueued", err)
+	}
+	clock.Advance(50 * time.Second)
+	if _, err := q.PowerOn("group:Office"); !errors.Is(err, lifx.ErrQueued) {
+		t.Fatalf("PowerOn err = %v, want ErrQueued", err)
+	}
+
+	clock.Advance(20 * time.Second)
+	f.FailWith("SetState", nil)
+	f.FailWith("Toggle", nil)
+
+	done := q.Replay()
+	if len(done) != 2 {
+		t.Fatalf("replayed %d commands, want 2", len(done))
+	}
+	if !errors.Is(done[0].Err, lifx.ErrExpired) {
+		t.Errorf("toggle err = %v, want ErrExpired", done[0].Err)
+	}
+	if done[1].Err != nil {
+		t.Fatalf("power on err = %v", done[1].Err)
+	}
+	if got := done[1].Delay(); got != 20*time.Second {
+		t.Errorf("power on delay = %v, want 20s", got)
+	}
+}
+
+func TestQueueCollapse(t *testing.T) {
+	type queued struct {
+		action     lifx.Action
+		power      string
+		color      string
+		brightness float64
+	}
+
+	red := lifx.NamedColor("red")
+	tests := []struct {
+		name string
+		cmds func(q *lifx.Queue)
+		want []queued
+	}{
+		{
+			name: "brightness then color",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("all", lifx.State{Brightness: 0.5})
+				q.SetState("all", lifx.State{Color: red})
+			},
+			want: []queued{{lifx.ActionState, "", "hue:0 saturation:1", 0.5}},
+		},
+		{
+			name: "color then saturation",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("all", lifx.State{Color: red})
+				q.SetState("all", lifx.State{Color: lifx.NamedColor("saturation:0.5")})
+			},
+			want: []queued{{lifx.ActionState, "", "hue:0 saturation:0.5", 0}},
+		},
+		{
+			name: "brightness then color brightness",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("all", lifx.State{Brightness: 0.5})
+				q.SetState("all", lifx.State{Color: lifx.NamedColor("blue brightness:0.2")})
+			},
+			want: []queued{{lifx.ActionState, "", "hue:250 saturation:1 brightness:0.2", 0}},
+		},
+		{
+			name: "color then kelvin",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("all", lifx.State{Color: lifx.NamedColor("red brightness:0.4")})
+				q.SetState("all", lifx.State{Color: lifx.NamedColor("kelvin:2700")})
+			},
+			want: []queued{{lifx.ActionState, "", "hue:0 saturation:0 brightness:0.4 kelvin:2700", 0}},
+		},
+		{
+			name: "power then power",
+			cmds: func(q *lifx.Queue) {
+				q.PowerOn("all")
+				q.PowerOff("all")
+			},
+			want: []queued{{lifx.ActionState, "off", "", 0}},
+		},
+		{
+			name: "invalid color",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("all", lifx.State{Color: red})
+				q.SetState("all", lifx.State{Color: lifx.NamedColor("mauve")})
+			},
+			want: []queued{
+				{lifx.ActionState, "", "red", 0},
+				{lifx.ActionState, "", "mauve", 0},
+			},
+		},
+		{
+			name: "toggle twice",
+			cmds: func(q *lifx.Queue) {
+				q.PowerOn("all")
+				q.Toggle("all", 0)
+				q.Toggle("all", 0)
+			},
+			want: []queued{{lifx.ActionState, "on", "", 0}},
+		},
+		{
+			name: "toggle then power",
+			cmds: func(q *lifx.Queue) {
+				q.Toggle("all", 0)
+				q.PowerOff("all")
+			},
+			want: []queued{{lifx.ActionState, "off", "", 0}},
+		},
+		{
+			name: "toggle then color",
+			cmds: func(q *lifx.Queue) {
+				q.Toggle("all", 0)
+				q.SetState("all", lifx.State{Color: red})
+			},
+			want: []queued{
+				{lifx.ActionToggle, "", "", 0},
+				{lifx.ActionState, "", "red", 0},
+			},
+		},
+		{
+			name: "other selectors",
+			cmds: func(q *lifx.Queue) {
+				q.SetState("label:Desk", lifx.State{Brightness: 0.5})
+				q.SetState("all", lifx.State{Color: red})
+			},
+			want: []queued{
+				{lifx.ActionState, "", "", 0.5},
+				{lifx.ActionState, "", "red", 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := lifxtest.NewFakeClient()
+			f.FailWith("SetState", lifx.ErrServer)
+			f.FailWith("Toggle", lifx.ErrServer)
+			q := lifx.NewQueue(f)
+			tt.cmds(q)
+
+			var got []queued
+			for _, cmd := range q.Pending() {
+				c := queued{action: cmd.Action, power: cmd.State.Power, brightness: cmd.State.Brightness}
+				if cmd.State.Color != nil {
+					c.color = cmd.State.Color.ColorString()
+				}
+				got = append(got, c)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queued %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
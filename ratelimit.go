@@ -0,0 +1,80 @@
+package lifx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The API allows 120 requests per token every 60 seconds.
+var (
+	DefaultRateLimit       = 120
+	DefaultRateLimitWindow = time.Minute
+)
+
+// WithRateLimit makes the client wait rather than exceed requests per
+// window. It shares one budget across every goroutine using the client.
+func WithRateLimit(requests int, window time.Duration) func(*Client) {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(requests, window)
+	}
+}
+
+// rateLimiter is a token bucket that starts full, so a burst of up to
+// the whole budget goes out at once before requests are spaced evenly.
+type rateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func newRateLimiter(requests int, window time.Duration) *rateLimiter {
+	if requests <= 0 || window <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		capacity: float64(requests),
+		tokens:   float64(requests),
+		rate:     float64(requests) / window.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it reports how long
+// until one will be.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.capacity {
+		l.tokens = l.capacity
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,422 @@
+package lifx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultDriftInterval = time.Minute
+
+type (
+	// EffectSpec is an effect a Reconciler can keep running: a Breathe,
+	// Pulse, Move, Morph or Flame, or EffectsOff for no effect at all.
+	EffectSpec interface {
+		Effect() Effect
+		start(c *Client, selector string) (*LifxResponse, error)
+	}
+
+	// DesiredState is what the lights matching Selector should look like.
+	// State fields left at their zero value are not managed, and a nil
+	// Effect leaves whatever effect is running alone.
+	DesiredState struct {
+		Selector string
+		State    State
+		Effect   EffectSpec
+	}
+
+	// Plan is what a Reconciler would change to bring the lights to their
+	// desired states.
+	Plan struct {
+		States  []PlannedState
+		Effects []PlannedEffect
+	}
+
+	// PlannedState changes the fields of a light listed in Changes, by
+	// sending State.
+	PlannedState struct {
+		Light   Light
+		Changes []Change
+		State   StateWithSelector
+	}
+
+	// PlannedEffect starts Effect on Selector, because Lights are not
+	// running it.
+	PlannedEffect struct {
+		Selector string
+		Effect   EffectSpec
+		Lights   []Light
+	}
+
+	// DriftStats counts what a Reconciler's checks have found since it was
+	// created, for exporting as metrics.
+	DriftStats struct {
+		Checks    int
+		Drifted   int
+		Corrected int
+		Failed    int
+		LastDrift time.Time
+	}
+
+	// Reconciler brings lights to a declared state in two steps: Plan reads
+	// the lights and works out the smallest set of changes, and Apply
+	// makes them. Like DiffScene, it compares each light with the last
+	// desired state that matches it, and skips lights that are not
+	// connected.
+	//
+	// Watch keeps checking for drift. Lights matching a paused selector,
+	// such as a room someone is controlling by hand, are left out of its
+	// checks until the selector is resumed.
+	Reconciler struct {
+		client   *Client
+		interval time.Duration
+		onDrift  func(*Plan)
+		correct  bool
+		mu       sync.Mutex
+		paused   map[string]bool
+		stats    DriftStats
+	}
+)
+
+func NewReconciler(c *Client, options ...func(*Reconciler)) *Reconciler {
+	r := &Reconciler{
+		client:   c,
+		interval: DefaultDriftInterval,
+		paused:   make(map[string]bool),
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// WithDriftInterval sets how often Watch checks for drift.
+func WithDriftInterval(interval time.Duration) func(*Reconciler) {
+	return func(r *Reconciler) {
+		r.interval = interval
+	}
+}
+
+// WithDriftHandler calls fn with the plan whenever a check finds drift,
+// before it is corrected.
+func WithDriftHandler(fn func(*Plan)) func(*Reconciler) {
+	return func(r *Reconciler) {
+		r.onDrift = fn
+	}
+}
+
+// WithAutoCorrect has Watch apply the plan for any drift it finds, rather
+// than only reporting it.
+func WithAutoCorrect() func(*Reconciler) {
+	return func(r *Reconciler) {
+		r.correct = true
+	}
+}
+
+// Plan reads the lights and plans the changes that bring them to desired.
+func (r *Reconciler) Plan(desired []DesiredState) (*Plan, error) {
+	lights, err := r.client.ListLights("all")
+	if err != nil {
+		return nil, err
+	}
+	return NewPlan(desired, lights)
+}
+
+// Apply makes the changes in p, sending the states in one SetStates call
+// before starting the effects. An effect that fails to start doesn't stop
+// the rest, and the first error is returned once all have been tried.
+func (r *Reconciler) Apply(p *Plan) error {
+	c := r.client.correlated()
+
+	if len(p.States) > 0 {
+		var states States
+		for _, s := range p.States {
+			states.States = append(states.States, s.State)
+		}
+		if _, err := c.SetStates("", states); err != nil {
+			return err
+		}
+	}
+
+	var first error
+	for _, e := range p.Effects {
+		if _, err := e.Effect.start(c, e.Selector); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Watch checks the lights against desired straight away and then every
+// interval until ctx is done. Errors go to the client's ErrorHandler with
+// the op "reconcile" and don't stop it.
+func (r *Reconciler) Watch(ctx context.Context, desired []DesiredState) error {
+	t := clockOrSystem(r.client.clock).NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		if _, err := r.Check(desired); err != nil {
+			r.client.reportError("reconcile", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C():
+		}
+	}
+}
+
+// Check looks for drift once, leaving out paused lights, and returns the
+// plan it found. The drift handler is called and, with WithAutoCorrect,
+// the plan applied when it isn't empty.
+func (r *Reconciler) Check(desired []DesiredState) (*Plan, error) {
+	lights, err := r.client.ListLights("all")
+	var p *Plan
+	if err == nil {
+		p, err = NewPlan(desired, lights)
+	}
+	if err == nil {
+		p = r.unpaused(p, lights)
+	}
+
+	r.mu.Lock()
+	r.stats.Checks++
+	if err != nil {
+		r.stats.Failed++
+	} else if !p.Empty() {
+		r.stats.Drifted++
+		r.stats.LastDrift = r.client.now()
+	}
+	r.mu.Unlock()
+
+	if err != nil || p.Empty() {
+		return p, err
+	}
+
+	if r.onDrift != nil {
+		r.onDrift(p)
+	}
+	if !r.correct {
+		return p, nil
+	}
+
+	err = r.Apply(p)
+
+	r.mu.Lock()
+	if err != nil {
+		r.stats.Failed++
+	} else {
+		r.stats.Corrected++
+	}
+	r.mu.Unlock()
+	return p, err
+}
+
+// Pause leaves the lights matching selector out of Watch's checks.
+func (r *Reconciler) Pause(selector string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paused[selector] = true
+}
+
+// Resume checks the lights matching selector again.
+func (r *Reconciler) Resume(selector string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.paused, selector)
+}
+
+// Paused returns the paused selectors in order.
+func (r *Reconciler) Paused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	selectors := make([]string, 0, len(r.paused))
+	for s := range r.paused {
+		selectors = append(selectors, s)
+	}
+	sort.Strings(selectors)
+	return selectors
+}
+
+func (r *Reconciler) Stats() DriftStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// unpaused drops the parts of p that touch paused lights. An effect is
+// started on its whole selector, so it is dropped if any of the lights
+// that selector matches is paused.
+func (r *Reconciler) unpaused(p *Plan, lights []Light) *Plan {
+	paused := r.Paused()
+	if len(paused) == 0 {
+		return p
+	}
+
+	isPaused := func(l Light) bool {
+		for _, s := range paused {
+			if ok, _ := MatchSelector(s, l); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	kept := &Plan{}
+	for _, s := range p.States {
+		if !isPaused(s.Light) {
+			kept.States = append(kept.States, s)
+		}
+	}
+effects:
+	for _, e := range p.Effects {
+		for _, l := range lights {
+			if ok, _ := MatchSelector(e.Selector, l); ok && isPaused(l) {
+				continue effects
+			}
+		}
+		kept.Effects = append(kept.Effects, e)
+	}
+	return kept
+}
+
+// NewPlan plans the changes that bring lights to desired. Selectors must
+// pick out the same lights every time, so zones and random selectors are
+// rejected.
+func NewPlan(desired []DesiredState, lights []Light) (*Plan, error) {
+	p := &Plan{}
+
+	for _, d := range desired {
+		if err := reconcilable(d.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	effects := make([]PlannedEffect, len(desired))
+	for _, l := range lights {
+		if !l.Connected {
+			continue
+		}
+
+		want := -1
+		for i, d := range desired {
+			if ok, _ := MatchSelector(d.Selector, l); ok {
+				want = i
+			}
+		}
+		if want < 0 {
+			continue
+		}
+		d := desired[want]
+
+		changes, fix, err := diffState(d.State, l)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.Selector, err)
+		}
+		if len(changes) > 0 {
+			p.States = append(p.States, PlannedState{Light: l, Changes: changes, State: fix})
+		}
+
+		if d.Effect != nil && !sameEffect(d.Effect.Effect(), l.Effect) {
+			effects[want].Lights = append(effects[want].Lights, l)
+		}
+	}
+
+	for i, e := range effects {
+		if len(e.Lights) > 0 {
+			e.Selector, e.Effect = desired[i].Selector, desired[i].Effect
+			p.Effects = append(p.Effects, e)
+		}
+	}
+	return p, nil
+}
+
+// Empty reports whether the lights are already as desired.
+func (p *Plan) Empty() bool {
+	return len(p.States) == 0 && len(p.Effects) == 0
+}
+
+// String lays the plan out for a person to review, one light or effect
+// to a line.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes\n"
+	}
+
+	var b strings.Builder
+	for _, s := range p.States {
+		fmt.Fprintf(&b, "~ %s (%s)", s.State.Selector, s.Light.Label)
+		for _, c := range s.Changes {
+			fmt.Fprintf(&b, " %s: %s -> %s", c.Field, c.Old, c.New)
+		}
+		b.WriteByte('\n')
+	}
+	for _, e := range p.Effects {
+		fmt.Fprintf(&b, "+ %s effect %s on %d lights\n", e.Selector, strings.ToLower(e.Effect.Effect().String()), len(e.Lights))
+	}
+	return b.String()
+}
+
+func reconcilable(selector string) error {
+	if strings.Contains(selector, "|") {
+		return fmt.Errorf("'%s' picks out zones, which can't be reconciled", selector)
+	}
+	parts, err := ParseSelector(selector)
+	if err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if p.Random {
+			return fmt.Errorf("'%s' picks a random light, which can't be reconciled", selector)
+		}
+	}
+	return nil
+}
+
+func sameEffect(want, running Effect) bool {
+	if want == EffectNone {
+		return !running.Running()
+	}
+	return want == running
+}
+
+func (Breathe) Effect() Effect    { return EffectBreathe }
+func (Pulse) Effect() Effect      { return EffectPulse }
+func (Move) Effect() Effect       { return EffectMove }
+func (Morph) Effect() Effect      { return EffectMorph }
+func (Flame) Effect() Effect      { return EffectFlame }
+func (EffectsOff) Effect() Effect { return EffectNone }
+
+func (b Breathe) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.Breathe(selector, b)
+}
+
+func (p Pulse) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.Pulse(selector, p)
+}
+
+func (m Move) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.Move(selector, m)
+}
+
+func (m Morph) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.Morph(selector, m)
+}
+
+func (f Flame) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.Flame(selector, f)
+}
+
+func (o EffectsOff) start(c *Client, selector string) (*LifxResponse, error) {
+	return c.EffectsOff(selector, o)
+}
@@ -0,0 +1,165 @@
+package lifx_test
+
+import (
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func reconcileLights() []lifx.Light {
+	desk := lifxtest.NewLight("d073d5000001", "Desk", "Office", "Home")
+	lamp := lifxtest.NewLight("d073d5000002", "Lamp", "Office", "Home")
+	porch := lifxtest.NewLight("d073d5000003", "Porch", "Outside", "Home")
+	shed := lifxtest.NewLight("d073d5000004", "Shed", "Outside", "Home")
+	shed.Connected = false
+	return []lifx.Light{desk, lamp, porch, shed}
+}
+
+func plannedPower(p *lifx.Plan) map[string]string {
+	powers := make(map[string]string)
+	for _, s := range p.States {
+		powers[s.Light.Label] = s.State.Power
+	}
+	return powers
+}
+
+func TestNewPlan(t *testing.T) {
+	p, err := lifx.NewPlan([]lifx.DesiredState{
+		{Selector: "all", State: lifx.State{Power: "on"}},
+		{Selector: "label:Desk", State: lifx.State{Power: "off"}},
+		{Selector: "group:Office", Effect: lifx.NewBreathe()},
+		{Selector: "label:Lamp", State: lifx.State{Power: "on"}},
+	}, reconcileLights())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last desired state to match a light is the only one it is held
+	// to, so the desk only gets the office's effect and the lamp is only
+	// turned on. The disconnected shed is skipped.
+	if got := plannedPower(p); len(got) != 2 || got["Lamp"] != "on" || got["Porch"] != "on" {
+		t.Errorf("planned power %v, want the lamp and porch on", got)
+	}
+	for _, s := range p.States {
+		if s.State.Selector != lifx.ById(s.Light.Id).String() {
+			t.Errorf("%s is set by %q, want its id", s.Light.Label, s.State.Selector)
+		}
+	}
+	if len(p.Effects) != 1 || len(p.Effects[0].Lights) != 1 || p.Effects[0].Lights[0].Label != "Desk" {
+		t.Errorf("planned effects %v, want the office's on the desk", p.Effects)
+	}
+}
+
+func TestNewPlanEffects(t *testing.T) {
+	lights := reconcileLights()
+	lights[1].Effect = lifx.EffectBreathe
+
+	p, err := lifx.NewPlan([]lifx.DesiredState{
+		{Selector: "group:Office", Effect: lifx.NewBreathe()},
+		{Selector: "group:Outside", Effect: lifx.EffectsOff{}},
+	}, lights)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.States) != 0 {
+		t.Errorf("planned states %v, want none", p.States)
+	}
+	if len(p.Effects) != 1 {
+		t.Fatalf("planned %d effects, want 1", len(p.Effects))
+	}
+	e := p.Effects[0]
+	if e.Selector != "group:Office" || len(e.Lights) != 1 || e.Lights[0].Label != "Desk" {
+		t.Errorf("planned %s on %v, want group:Office on the desk", e.Selector, e.Lights)
+	}
+}
+
+func TestNewPlanRejects(t *testing.T) {
+	for _, selector := range []string{
+		"label:Desk|0-3",
+		"all:random",
+		"group:Office:random",
+		"colour:red",
+	} {
+		_, err := lifx.NewPlan([]lifx.DesiredState{
+			{Selector: "all", State: lifx.State{Power: "on"}},
+			{Selector: selector, State: lifx.State{Power: "off"}},
+		}, reconcileLights())
+		if err == nil {
+			t.Errorf("NewPlan accepted %q", selector)
+		}
+	}
+}
+
+func TestReconcilerCheck(t *testing.T) {
+	api := lifxtest.NewServer(reconcileLights()...)
+	defer api.Close()
+
+	start := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	clock := lifxtest.NewClock(start)
+	c := lifx.NewClient("x", lifxtest.WithServer(api), lifx.WithClock(clock))
+
+	var drifts int
+	r := lifx.NewReconciler(c, lifx.WithAutoCorrect(), lifx.WithDriftHandler(func(*lifx.Plan) { drifts++ }))
+	r.Pause("group:Office")
+
+	breathe := lifx.NewBreathe()
+	breathe.Color = lifx.NamedColor("red")
+
+	on := []lifx.DesiredState{{Selector: "all", State: lifx.State{Power: "on"}}}
+	p, err := r.Check([]lifx.DesiredState{
+		{Selector: "all", State: lifx.State{Power: "on"}, Effect: breathe},
+		{Selector: "label:Porch", State: lifx.State{Power: "on"}, Effect: breathe},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The office is paused, so only the porch is turned on, and the effect
+	// on all lights, which takes in the office, is dropped.
+	if got := plannedPower(p); len(got) != 1 || got["Porch"] != "on" {
+		t.Errorf("planned power %v, want only the porch on", got)
+	}
+	if len(p.Effects) != 1 || p.Effects[0].Selector != "label:Porch" {
+		t.Errorf("planned effects %v, want only the porch's", p.Effects)
+	}
+	if l, _ := api.Light("d073d5000001"); l.Power != "off" {
+		t.Errorf("paused desk power = %q, want it left off", l.Power)
+	}
+	if l, _ := api.Light("d073d5000003"); l.Power != "on" {
+		t.Errorf("porch power = %q, want on", l.Power)
+	}
+
+	clock.Advance(time.Minute)
+	if p, err = r.Check(on); err != nil || !p.Empty() {
+		t.Errorf("second check = %v, %v, want no drift", p, err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err = r.Check([]lifx.DesiredState{{Selector: "all:random"}}); err == nil {
+		t.Error("Check accepted a random selector")
+	}
+
+	want := lifx.DriftStats{Checks: 3, Drifted: 1, Corrected: 1, Failed: 1, LastDrift: start}
+	if got := r.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if drifts != 1 {
+		t.Errorf("drift handler called %d times, want 1", drifts)
+	}
+
+	r.Resume("group:Office")
+	if got := r.Paused(); len(got) != 0 {
+		t.Errorf("Paused = %v after Resume", got)
+	}
+	if p, err = r.Check(on); err != nil {
+		t.Fatal(err)
+	}
+	if got := plannedPower(p); len(got) != 2 || got["Desk"] != "on" || got["Lamp"] != "on" {
+		t.Errorf("planned power after Resume %v, want the office on", got)
+	}
+	if got := r.Stats().LastDrift; !got.Equal(start.Add(2 * time.Minute)) {
+		t.Errorf("LastDrift = %v, want the clock's time", got)
+	}
+}
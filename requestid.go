@@ -0,0 +1,89 @@
+package lifx
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// Headers that identify requests. Every request gets its own request id,
+// and the requests that make up one operation share a correlation id.
+const (
+	RequestIDHeader     = "X-Request-Id"
+	CorrelationIDHeader = "X-Correlation-Id"
+)
+
+// WithCorrelationID sends id with every request the client makes, so
+// that the calls making up one operation can be found together in logs
+// and errors. It is usually applied to a copy for one operation:
+//
+//	op := c.With(lifx.WithCorrelationID(lifx.NewCorrelationID()))
+//
+// Operations that fan out into several calls, such as SetStateMulti,
+// Restore and batched SetStates, pick one themselves if the client has
+// none.
+func WithCorrelationID(id string) func(*Client) {
+	return func(c *Client) {
+		c.correlation = id
+	}
+}
+
+// NewCorrelationID returns a random version 4 UUID.
+func NewCorrelationID() string {
+	return newUUID()
+}
+
+// correlated returns c if it has a correlation id and otherwise a copy
+// with a new one.
+func (c *Client) correlated() *Client {
+	if c.correlation != "" {
+		return c
+	}
+	return c.With(WithCorrelationID(newUUID()))
+}
+
+func setRequestIDs(req *http.Request, correlation string) {
+	req.Header.Set(RequestIDHeader, newUUID())
+	if correlation != "" {
+		req.Header.Set(CorrelationIDHeader, correlation)
+	}
+}
+
+// requestIDs returns the ids that were sent with req.
+func requestIDs(req *http.Request) (request, correlation string) {
+	if req == nil {
+		return "", ""
+	}
+	return req.Header.Get(RequestIDHeader), req.Header.Get(CorrelationIDHeader)
+}
+
+// requestError wraps err, from sending req, with the ids that were sent.
+func requestError(req *http.Request, err error) error {
+	e := &RequestError{Err: err}
+	e.RequestID, e.CorrelationID = requestIDs(req)
+	return e
+}
+
+// idSuffix describes the ids for the end of a message, or is empty when
+// there are none.
+func idSuffix(request, correlation string) string {
+	switch {
+	case request == "":
+		return ""
+	case correlation == "":
+		return fmt.Sprintf(" [request %s]", request)
+	}
+	return fmt.Sprintf(" [request %s, correlation %s]", request, correlation)
+}
+
+func newUUID() string {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,52 @@
+package lifx_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestErrorIDs(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	var sent *http.Request
+	c := lifx.NewClient("token", lifx.WithRetry(lifx.RetryPolicy{}), lifx.WithCorrelationID("op-1"))
+	c.Client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return nil, refused
+	})
+
+	_, err := c.ListLights("all")
+
+	var e *lifx.RequestError
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %#v, want a RequestError", err)
+	}
+	if want := sent.Header.Get(lifx.RequestIDHeader); e.RequestID == "" || e.RequestID != want {
+		t.Errorf("RequestID = %q, want %q", e.RequestID, want)
+	}
+	if e.CorrelationID != "op-1" {
+		t.Errorf("CorrelationID = %q, want op-1", e.CorrelationID)
+	}
+	if !strings.Contains(err.Error(), "[request "+e.RequestID+", correlation op-1]") {
+		t.Errorf("message %q is missing the ids", err)
+	}
+
+	var ne net.Error
+	if !errors.As(err, &ne) {
+		t.Errorf("err %v no longer unwraps to a net.Error", err)
+	}
+	if !lifx.IsUnreachable(err) {
+		t.Errorf("IsUnreachable(%v) = false", err)
+	}
+}
@@ -0,0 +1,264 @@
+package lifx
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a rate limit, a server
+// error or a network error are retried. Attempts counts retries, so zero
+// disables retrying. The wait doubles after every attempt, from Backoff
+// up to MaxBackoff, with some jitter so that clients do not retry in step.
+//
+// POST requests, such as toggles and deltas, may have been applied even
+// when their response was lost, so they are only retried when they were
+// rate limited or failed before anything was sent.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:   3,
+	Backoff:    500 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+func WithRetry(policy RetryPolicy) func(*Client) {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRandSource draws the jitter between retries from src, so a test can
+// seed it and get the same backoffs every run. Copies made with With
+// share it.
+func WithRandSource(src rand.Source) func(*Client) {
+	return func(c *Client) {
+		c.rand = &lockedRand{r: rand.New(src)}
+	}
+}
+
+// lockedRand makes a rand.Rand safe to share between goroutines, as the
+// package-level functions are.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Int63n(n int64) int64 {
+	if l == nil {
+		return rand.Int63n(n)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, 523:
+		return true
+	}
+	return false
+}
+
+// idempotent reports whether req can be sent twice to the same effect.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sent reports whether any of req reached the connection, in which case
+// the server may have acted on it.
+func sent(req *http.Request) (*http.Request, func() bool) {
+	var wrote int32
+
+	trace := &httptrace.ClientTrace{
+		WroteHeaders: func() { atomic.StoreInt32(&wrote, 1) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), func() bool {
+		return atomic.LoadInt32(&wrote) != 0
+	}
+}
+
+// do sends req within the client's rate limit, timeout and retry policy.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	hc := *c.Client
+	if c.timeout > 0 {
+		hc.Timeout = c.timeout
+	}
+
+	wait := c.retry.Backoff
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.pool != nil {
+			if err := c.pool.acquire(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		// A panic in a transport the caller supplied fails the request
+		// rather than the program.
+		var r *http.Response
+		traced, wrote := sent(req)
+		err := safely(func() (err error) {
+			r, err = hc.Do(traced)
+			return err
+		})
+		if c.pool != nil {
+			c.pool.release()
+		}
+		if _, ok := err.(*PanicError); ok {
+			return nil, err
+		}
+		if err != nil {
+			err = requestError(req, err)
+		}
+		again := err != nil || retryable(r.StatusCode)
+		if !idempotent(req) {
+			if err == nil {
+				again = r.StatusCode == http.StatusTooManyRequests
+			} else {
+				again = !wrote()
+			}
+		}
+		if attempt >= c.retry.Attempts || !again {
+			if err == nil {
+				r.Body = c.limitBody(r.Body)
+			}
+			return r, err
+		}
+		if err != nil && req.Context().Err() != nil {
+			return nil, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return r, err
+		}
+
+		delay := c.retry.delay(wait, c.rand)
+		if err == nil {
+			if reset := retryAfter(r, c.now()); reset > delay {
+				delay = reset
+			}
+			io.Copy(ioutil.Discard, r.Body)
+			r.Body.Close()
+		}
+
+		t := clockOrSystem(c.clock).NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		case <-t.C():
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		wait *= 2
+	}
+}
+
+func (p RetryPolicy) delay(wait time.Duration, r *lockedRand) time.Duration {
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// Up to a quarter either way.
+	return wait - wait/4 + time.Duration(r.Int63n(int64(wait)/2+1))
+}
+
+// retryAfter is how long a rate limited response asks the client to wait,
+// taken from Retry-After or, failing that, X-RateLimit-Reset.
+func retryAfter(r *http.Response, now time.Time) time.Duration {
+	if r.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	if s, err := strconv.Atoi(r.Header.Get("Retry-After")); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if reset, err := strconv.ParseInt(r.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if d := time.Unix(reset, 0).Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// RetryTimedOut calls fn for selector and then, up to attempts more times,
+// for just the lights that reported timed_out, which usually means they
+// were briefly unreachable from the LIFX cloud. The results are merged so
+// each light appears once with its latest status. A light that timed out
+// may still have applied the change, so fn should set a state rather than
+// toggle one:
+//
+//	r, err := lifx.RetryTimedOut("group:Kitchen", 2, func(s string) (*lifx.LifxResponse, error) {
+//		return c.SetState(s, state)
+//	})
+//
+// If a retry fails, the results so far are returned with its error.
+func RetryTimedOut(selector string, attempts int, fn func(selector string) (*LifxResponse, error)) (*LifxResponse, error) {
+	r, err := fn(selector)
+	if err != nil || r == nil {
+		return r, err
+	}
+
+	merged := *r
+	merged.Results = append([]Result(nil), r.Results...)
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		var parts []SelectorPart
+		for _, res := range merged.Results {
+			if res.Status.IsTimedOut() {
+				parts = append(parts, ById(res.Id))
+			}
+		}
+		if len(parts) == 0 {
+			break
+		}
+
+		r, err := EachBatch(parts, fn)
+		if err != nil {
+			return &merged, err
+		}
+		if r == nil {
+			break
+		}
+
+		merged.Warnings = append(merged.Warnings, r.Warnings...)
+		for _, res := range r.Results {
+			for i := range merged.Results {
+				if merged.Results[i].Id == res.Id {
+					merged.Results[i] = res
+				}
+			}
+		}
+	}
+
+	return &merged, nil
+}
@@ -0,0 +1,152 @@
+package lifx
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// How far a light may be from a scene before it counts as drifted. The API
+// reports levels after the bulb has rounded them, so exact comparisons
+// would report drift straight after the scene was applied.
+const (
+	driftHue    = 1.0
+	driftLevel  = 0.01
+	driftKelvin = 50
+)
+
+// SceneDrift is how a light differs from the state a scene gives it.
+// Changes record what the light shows as Old and what the scene wants as
+// New, and Fix sets only the fields that differ.
+type SceneDrift struct {
+	Light   Light
+	Changes []Change
+	Fix     StateWithSelector
+}
+
+// DiffScene compares each light with the state scene gives it, returning
+// the lights that have drifted from it. A light matched by several of the
+// scene's states is compared with the last, which is the one SetStates
+// would leave it in. Lights the scene does not cover, lights that are not
+// connected and states that pick out zones are left out.
+func DiffScene(scene Scene, lights []Light) ([]SceneDrift, error) {
+	var drifts []SceneDrift
+
+	for _, l := range lights {
+		if !l.Connected {
+			continue
+		}
+
+		var (
+			want  SceneState
+			found bool
+		)
+		for _, s := range scene.States {
+			if strings.Contains(s.Selector, "|") {
+				continue
+			}
+			ok, err := MatchSelector(s.Selector, l)
+			if err != nil {
+				return nil, fmt.Errorf("scene '%s': %w", scene.Name, err)
+			}
+			if ok {
+				want, found = s, true
+			}
+		}
+		if !found {
+			continue
+		}
+
+		changes, fix, err := diffState(want.ToState(), l)
+		if err != nil {
+			return nil, fmt.Errorf("scene '%s': %w", scene.Name, err)
+		}
+		if len(changes) > 0 {
+			drifts = append(drifts, SceneDrift{Light: l, Changes: changes, Fix: fix})
+		}
+	}
+
+	return drifts, nil
+}
+
+// DriftStates returns the states that put drifted lights back as their
+// scene has them, over duration seconds.
+func DriftStates(drifts []SceneDrift, duration float64) States {
+	var states States
+
+	for _, d := range drifts {
+		states.States = append(states.States, d.Fix)
+	}
+	states.Defaults.Duration = duration
+	return states
+}
+
+// diffState lists how l differs from want, along with the state that
+// sets only what differs, keeping want's duration.
+func diffState(want State, l Light) ([]Change, StateWithSelector, error) {
+	var changes []Change
+
+	fix := StateWithSelector{Selector: ById(l.Id).String()}
+	fix.Duration = want.Duration
+
+	add := func(field string, old, new interface{}) {
+		changes = append(changes, Change{Field: field, Old: fmt.Sprint(old), New: fmt.Sprint(new)})
+	}
+
+	if want.Power != "" && want.Power != l.Power {
+		add("power", l.Power, want.Power)
+		fix.Power = want.Power
+	}
+	// The color of a light that is meant to be off doesn't show.
+	if want.Power == "off" || (want.Power == "" && l.Power == "off") {
+		return changes, fix, nil
+	}
+
+	var c HSBKColor
+	if want.Color != nil {
+		var err error
+		if c, err = ParseColor(want.Color.ColorString()); err != nil {
+			return nil, fix, err
+		}
+	}
+	// The API takes a kelvin on its own to mean white.
+	if c.K != nil && c.H == nil && c.S == nil && !c.RandomHue {
+		c.S = Float32Ptr(0)
+	}
+
+	// Hue only shows with some saturation, and kelvin only without it.
+	var color HSBKColor
+	colored := c.S == nil || *c.S > 0
+	if c.H != nil && colored && l.Color.H != nil && hueDistance(*c.H, *l.Color.H) > driftHue {
+		add("hue", *l.Color.H, *c.H)
+		color.H = c.H
+	}
+	if c.S != nil && l.Color.S != nil && math.Abs(float64(*c.S-*l.Color.S)) > driftLevel {
+		add("saturation", *l.Color.S, *c.S)
+		color.S = c.S
+	}
+	if c.K != nil && !colored && l.Color.K != nil && math.Abs(float64(*c.K)-float64(*l.Color.K)) > driftKelvin {
+		add("kelvin", *l.Color.K, *c.K)
+		color.K = c.K
+	}
+	if color != (HSBKColor{}) {
+		fix.Color = color
+	}
+
+	brightness := want.Brightness
+	if c.B != nil && brightness == 0 {
+		brightness = float64(*c.B)
+	}
+	if brightness != 0 && math.Abs(brightness-l.Brightness) > driftLevel {
+		add("brightness", l.Brightness, brightness)
+		fix.Brightness = brightness
+	}
+
+	return changes, fix, nil
+}
+
+// hueDistance is how far apart two hues are around the color wheel.
+func hueDistance(a, b float32) float64 {
+	d := math.Mod(math.Abs(float64(a-b)), 360)
+	return math.Min(d, 360-d)
+}
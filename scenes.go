@@ -0,0 +1,126 @@
+package lifx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	// Scene is a scene saved in the LIFX app, with the state it puts
+	// each of its lights in.
+	Scene struct {
+		UUID      string       `json:"uuid"`
+		Name      string       `json:"name"`
+		Account   SceneAccount `json:"account"`
+		States    []SceneState `json:"states"`
+		CreatedAt time.Time    `json:"created_at"`
+		UpdatedAt time.Time    `json:"updated_at"`
+	}
+
+	SceneAccount struct {
+		UUID string `json:"uuid"`
+	}
+
+	// SceneState is what a scene does to the lights matching Selector,
+	// which is usually a single light's id. Brightness is carried outside
+	// the color, as the API reports it.
+	SceneState struct {
+		Selector   string    `json:"selector"`
+		Power      string    `json:"power,omitempty"`
+		Color      HSBKColor `json:"color"`
+		Brightness float64   `json:"brightness,omitempty"`
+	}
+
+	Activate struct {
+		Duration float64  `json:"duration,omitempty"`
+		Ignore   []string `json:"ignore,omitempty"`
+		Fast     bool     `json:"fast,omitempty"`
+	}
+)
+
+// sceneJSON is a Scene as the API sends it, with times in Unix seconds.
+type sceneJSON struct {
+	UUID      string       `json:"uuid"`
+	Name      string       `json:"name"`
+	Account   SceneAccount `json:"account"`
+	States    []SceneState `json:"states"`
+	CreatedAt int64        `json:"created_at,omitempty"`
+	UpdatedAt int64        `json:"updated_at,omitempty"`
+}
+
+func (s *Scene) UnmarshalJSON(b []byte) error {
+	var j sceneJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*s = Scene{UUID: j.UUID, Name: j.Name, Account: j.Account, States: j.States}
+	if j.CreatedAt != 0 {
+		s.CreatedAt = time.Unix(j.CreatedAt, 0).UTC()
+	}
+	if j.UpdatedAt != 0 {
+		s.UpdatedAt = time.Unix(j.UpdatedAt, 0).UTC()
+	}
+	return nil
+}
+
+// MarshalJSON writes the scene back in the form the API uses.
+func (s Scene) MarshalJSON() ([]byte, error) {
+	j := sceneJSON{UUID: s.UUID, Name: s.Name, Account: s.Account, States: s.States}
+	if !s.CreatedAt.IsZero() {
+		j.CreatedAt = s.CreatedAt.Unix()
+	}
+	if !s.UpdatedAt.IsZero() {
+		j.UpdatedAt = s.UpdatedAt.Unix()
+	}
+	return json.Marshal(j)
+}
+
+// ToState returns the state the scene sets, for applying it with
+// SetState or SetStates.
+func (s SceneState) ToState() State {
+	st := State{Power: s.Power, Brightness: s.Brightness}
+	if s.Color != (HSBKColor{}) {
+		st.Color = s.Color
+	}
+	return st
+}
+
+func (c *Client) ListScenes() ([]Scene, error) {
+	return doRequest[[]Scene](c, http.MethodGet, EndpointScenes(), nil)
+}
+
+func (c *Client) ActivateScene(uuid string, activate Activate) (*LifxResponse, error) {
+	if c.fast {
+		activate.Fast = true
+	}
+	activate.Duration = c.defaultDuration(activate.Duration)
+	return doRequest[*LifxResponse](c, http.MethodPut, EndpointActivateScene(BySceneId(uuid).String()), activate)
+}
+
+// FindScene looks a scene up by UUID or, failing that, by name ignoring
+// case. A name shared by several scenes is reported as ambiguous rather
+// than picking one of them.
+func FindScene(scenes []Scene, nameOrUUID string) (Scene, error) {
+	var found []Scene
+
+	for _, s := range scenes {
+		if s.UUID == nameOrUUID {
+			return s, nil
+		}
+		if strings.EqualFold(s.Name, nameOrUUID) {
+			found = append(found, s)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Scene{}, fmt.Errorf("no scene named '%s'", nameOrUUID)
+	case 1:
+		return found[0], nil
+	}
+	return Scene{}, fmt.Errorf("%d scenes are named '%s', use a uuid instead", len(found), nameOrUUID)
+}
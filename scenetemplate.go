@@ -0,0 +1,107 @@
+package lifx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+)
+
+type (
+	// SceneTemplate is a LocalScene file with text/template placeholders,
+	// rendered each time it is applied so that one file can follow the
+	// time of day and the season:
+	//
+	//	states:
+	//	  all:
+	//	    selector: all
+	//	    color: kelvin:{{if ge .Hour 19}}{{.SunsetKelvin}}{{else}}{{.DaylightKelvin}}{{end}}
+	SceneTemplate struct {
+		tmpl *template.Template
+	}
+
+	// TemplateData is what a SceneTemplate can refer to. Season is for the
+	// northern hemisphere, taking whole months.
+	TemplateData struct {
+		Now            time.Time
+		Hour           int
+		Weekday        string
+		Weekend        bool
+		Month          string
+		Season         string
+		SunsetKelvin   int
+		DaylightKelvin int
+	}
+)
+
+// ParseSceneTemplate parses text, making funcs available to it alongside
+// text/template's own functions.
+func ParseSceneTemplate(text string, funcs template.FuncMap) (*SceneTemplate, error) {
+	tmpl, err := template.New("scene").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &SceneTemplate{tmpl: tmpl}, nil
+}
+
+// LoadSceneTemplate parses the template in the file at path.
+func LoadSceneTemplate(path string, funcs template.FuncMap) (*SceneTemplate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := ParseSceneTemplate(string(b), funcs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// NewTemplateData describes now for a SceneTemplate.
+func NewTemplateData(now time.Time) TemplateData {
+	return TemplateData{
+		Now:            now,
+		Hour:           now.Hour(),
+		Weekday:        now.Weekday().String(),
+		Weekend:        now.Weekday() == time.Saturday || now.Weekday() == time.Sunday,
+		Month:          now.Month().String(),
+		Season:         season(now.Month()),
+		SunsetKelvin:   KelvinSunset,
+		DaylightKelvin: KelvinDaylight,
+	}
+}
+
+// Render fills in t with data and reads the result as a LocalScene. Data
+// is usually a TemplateData, but any value the template expects will do.
+func (t *SceneTemplate) Render(data interface{}) (LocalScene, error) {
+	var b bytes.Buffer
+
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return LocalScene{}, err
+	}
+	return ParseLocalScene(&b)
+}
+
+// ApplySceneTemplate renders t for the time on the client's clock and
+// applies the scene over duration seconds.
+func (c *Client) ApplySceneTemplate(t *SceneTemplate, duration float64) (*SetStatesResponse, error) {
+	s, err := t.Render(NewTemplateData(c.now()))
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyLocalScene(s, duration)
+}
+
+func season(m time.Month) string {
+	switch m {
+	case time.December, time.January, time.February:
+		return "winter"
+	case time.March, time.April, time.May:
+		return "spring"
+	case time.June, time.July, time.August:
+		return "summer"
+	}
+	return "autumn"
+}
@@ -0,0 +1,323 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parser compiles schedule strings. Times are in its location, and
+// schedules relative to the sun need its coordinates.
+type Parser struct {
+	location *time.Location
+	coords   *Coordinates
+}
+
+var (
+	weekdays = map[string]time.Weekday{
+		"sunday":    time.Sunday,
+		"monday":    time.Monday,
+		"tuesday":   time.Tuesday,
+		"wednesday": time.Wednesday,
+		"thursday":  time.Thursday,
+		"friday":    time.Friday,
+		"saturday":  time.Saturday,
+	}
+
+	units = map[string]time.Duration{
+		"second": time.Second,
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+	}
+
+	ErrNoCoordinates = errors.New("schedules relative to the sun need coordinates")
+)
+
+func NewParser(options ...func(*Parser)) *Parser {
+	p := &Parser{location: time.Local}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+func WithLocation(loc *time.Location) func(*Parser) {
+	return func(p *Parser) {
+		p.location = loc
+	}
+}
+
+func WithCoordinates(latitude, longitude float64) func(*Parser) {
+	return func(p *Parser) {
+		p.coords = &Coordinates{Latitude: latitude, Longitude: longitude}
+	}
+}
+
+// Parse compiles s with a parser for local time and no coordinates.
+func Parse(s string) (Schedule, error) {
+	return NewParser().Parse(s)
+}
+
+// Parse accepts an interval, such as "hourly" or "every 15 minutes", or a
+// time of day with optional days before it. Without days, the "at" before
+// the time may be left out, as in "20 minutes before sunset". Days are "daily", "weekdays",
+// "weekends", or weekday names and ranges like "mon-fri", each optionally
+// preceded by "every" or "on". Times are a clock time like "7:15", "7pm"
+// or "noon", or one of dawn, sunrise, sunset and dusk, shifted by a
+// duration as in "sunset -20m" or "20 minutes before sunset".
+func (p *Parser) Parse(s string) (Schedule, error) {
+	fields := strings.Fields(strings.NewReplacer(",", " ").Replace(strings.ToLower(s)))
+	if len(fields) == 0 {
+		return nil, errors.New("empty schedule")
+	}
+
+	if sched, ok, err := parseInterval(fields); ok || err != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", s, err)
+		}
+		return sched, nil
+	}
+
+	at := -1
+	for i, f := range fields {
+		if f == "at" {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		d := Daily{Location: p.location}
+		d.Days, _ = parseDays(nil)
+		if err := p.parseTime(&d, fields); err != nil {
+			if errors.Is(err, ErrNoCoordinates) {
+				return nil, fmt.Errorf("%q: %w", s, err)
+			}
+			return nil, fmt.Errorf("%q: missing \"at\" and a time", s)
+		}
+		return d, nil
+	}
+
+	// Days may also follow the time, as in "at 7:15 on weekdays".
+	when, rest := fields[at+1:], fields[:at]
+	for i, f := range when {
+		if f == "on" || f == "every" {
+			when, rest = when[:i], append(append([]string(nil), rest...), when[i:]...)
+			break
+		}
+	}
+
+	d := Daily{Location: p.location}
+	var err error
+	if d.Days, err = parseDays(rest); err != nil {
+		return nil, fmt.Errorf("%q: %w", s, err)
+	}
+	if err = p.parseTime(&d, when); err != nil {
+		return nil, fmt.Errorf("%q: %w", s, err)
+	}
+	return d, nil
+}
+
+func parseInterval(fields []string) (Schedule, bool, error) {
+	switch {
+	case len(fields) == 1 && fields[0] == "hourly":
+		return Every(time.Hour), true, nil
+	case len(fields) >= 2 && fields[0] == "every" && fields[1] != "day":
+		if _, err := parseDays(fields[1:]); err == nil {
+			return nil, false, nil
+		}
+		d, err := parseDuration(fields[1:])
+		if err != nil {
+			return nil, false, nil
+		}
+		if d <= 0 {
+			return nil, true, errors.New("interval must be positive")
+		}
+		return Every(d), true, nil
+	}
+	return nil, false, nil
+}
+
+func parseDays(fields []string) ([7]bool, error) {
+	var days [7]bool
+	named := false
+
+	for _, f := range fields {
+		switch f {
+		case "every", "on", "and":
+			continue
+		case "day", "daily", "everyday", "days":
+			days = [7]bool{true, true, true, true, true, true, true}
+		case "weekday", "weekdays":
+			for d := time.Monday; d <= time.Friday; d++ {
+				days[d] = true
+			}
+		case "weekend", "weekends":
+			days[time.Saturday], days[time.Sunday] = true, true
+		default:
+			from, to := f, f
+			if i := strings.IndexByte(f, '-'); i > 0 {
+				from, to = f[:i], f[i+1:]
+			}
+			a, err := parseWeekday(from)
+			if err != nil {
+				return days, err
+			}
+			b, err := parseWeekday(to)
+			if err != nil {
+				return days, err
+			}
+			for d := a; ; d = (d + 1) % 7 {
+				days[d] = true
+				if d == b {
+					break
+				}
+			}
+		}
+		named = true
+	}
+
+	if !named {
+		days = [7]bool{true, true, true, true, true, true, true}
+	}
+	return days, nil
+}
+
+// parseWeekday accepts full names, abbreviations of at least three letters
+// and plurals such as "mondays".
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.TrimSuffix(s, "s")
+	if len(s) >= 3 {
+		for name, d := range weekdays {
+			if strings.HasPrefix(name, s) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("'%s' is not a day", s)
+}
+
+func (p *Parser) parseTime(d *Daily, fields []string) error {
+	if len(fields) == 0 {
+		return errors.New("missing a time after \"at\"")
+	}
+
+	// "20 minutes before sunset"
+	for i, f := range fields {
+		if f != "before" && f != "after" {
+			continue
+		}
+		offset, err := parseDuration(fields[:i])
+		if err != nil {
+			return err
+		}
+		if f == "before" {
+			offset = -offset
+		}
+		if err = p.parseTime(d, fields[i+1:]); err != nil {
+			return err
+		}
+		d.Offset += offset
+		return nil
+	}
+
+	// "sunset -20m", "sunset-20m" or "sunset - 20m"
+	s := strings.Join(fields, "")
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		offset, err := time.ParseDuration(s[i:])
+		if err != nil {
+			return err
+		}
+		d.Offset = offset
+		s = s[:i]
+	}
+
+	if _, ok := solarEvents[s]; ok {
+		if p.coords == nil {
+			return ErrNoCoordinates
+		}
+		d.Solar, d.coords = s, *p.coords
+		return nil
+	}
+
+	var err error
+	d.Hour, d.Minute, err = parseClock(s)
+	return err
+}
+
+// parseClock accepts 24-hour times, 12-hour times ending in am or pm, noon
+// and midnight.
+func parseClock(s string) (int, int, error) {
+	switch s {
+	case "noon", "midday":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+
+	bad := fmt.Errorf("'%s' is not a time", s)
+
+	meridiem := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		s, meridiem = s[:len(s)-2], s[len(s)-2:]
+	}
+
+	hs, ms := s, "0"
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		hs, ms = s[:i], s[i+1:]
+		if len(ms) != 2 {
+			return 0, 0, bad
+		}
+	} else if meridiem == "" {
+		return 0, 0, bad
+	}
+
+	h, err := strconv.Atoi(hs)
+	if err != nil {
+		return 0, 0, bad
+	}
+	m, err := strconv.Atoi(ms)
+	if err != nil || m < 0 || m > 59 {
+		return 0, 0, bad
+	}
+
+	if meridiem != "" {
+		if h < 1 || h > 12 {
+			return 0, 0, bad
+		}
+		h %= 12
+		if meridiem == "pm" {
+			h += 12
+		}
+	}
+	if h < 0 || h > 23 {
+		return 0, 0, bad
+	}
+	return h, m, nil
+}
+
+// parseDuration accepts a Go duration such as "1h30m", or a count and a
+// unit such as "20 minutes". A unit on its own counts once.
+func parseDuration(fields []string) (time.Duration, error) {
+	s := strings.Join(fields, " ")
+
+	if len(fields) == 1 {
+		if d, err := time.ParseDuration(fields[0]); err == nil {
+			return d, nil
+		}
+		fields = []string{"1", fields[0]}
+	}
+
+	if len(fields) == 2 {
+		n, err := strconv.ParseFloat(fields[0], 64)
+		unit, ok := units[strings.TrimSuffix(fields[1], "s")]
+		if err == nil && ok {
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return 0, fmt.Errorf("'%s' is not a duration", s)
+}
@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	var (
+		all      = [7]bool{true, true, true, true, true, true, true}
+		weekdays = [7]bool{false, true, true, true, true, true, false}
+		london   = Coordinates{Latitude: 51.5, Longitude: -0.13}
+	)
+
+	tests := []struct {
+		spec string
+		want Schedule
+	}{
+		{"hourly", Every(time.Hour)},
+		{"every 15 minutes", Every(15 * time.Minute)},
+		{"every 90s", Every(90 * time.Second)},
+		{"every hour", Every(time.Hour)},
+		{"every weekday at 7:15", Daily{Days: weekdays, Hour: 7, Minute: 15, Location: time.UTC}},
+		{"at 7pm on mon-fri", Daily{Days: weekdays, Hour: 19, Location: time.UTC}},
+		{"Mon-Fri at 7PM", Daily{Days: weekdays, Hour: 19, Location: time.UTC}},
+		{"at noon on weekends", Daily{Days: [7]bool{true, false, false, false, false, false, true}, Hour: 12, Location: time.UTC}},
+		{"fri-mon at 6:30am", Daily{Days: [7]bool{true, true, false, false, false, true, true}, Hour: 6, Minute: 30, Location: time.UTC}},
+		{"tuesdays and thursdays at 12am", Daily{Days: [7]bool{false, false, true, false, true, false, false}, Location: time.UTC}},
+		{"daily at 23:59", Daily{Days: all, Hour: 23, Minute: 59, Location: time.UTC}},
+		{"20 minutes before sunset", Daily{Days: all, Solar: "sunset", Offset: -20 * time.Minute, Location: time.UTC, coords: london}},
+		{"daily at dusk -20m", Daily{Days: all, Solar: "dusk", Offset: -20 * time.Minute, Location: time.UTC, coords: london}},
+		{"at 1 hour after sunrise on weekdays", Daily{Days: weekdays, Solar: "sunrise", Offset: time.Hour, Location: time.UTC, coords: london}},
+	}
+
+	p := NewParser(WithLocation(time.UTC), WithCoordinates(london.Latitude, london.Longitude))
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := p.Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"every weekday",
+		"every 0 minutes",
+		"at",
+		"at 25:00",
+		"at 7:5",
+		"at 13pm",
+		"at 7",
+		"on funday at 7:00",
+		"at 7:15 tomorrow",
+		"at soon before sunset",
+	}
+
+	p := NewParser(WithLocation(time.UTC), WithCoordinates(51.5, -0.13))
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			if s, err := p.Parse(spec); err == nil {
+				t.Errorf("Parse = %+v, want an error", s)
+			}
+		})
+	}
+}
+
+func TestParseNoCoordinates(t *testing.T) {
+	for _, spec := range []string{"at sunset", "20 minutes before sunset"} {
+		if _, err := NewParser().Parse(spec); !errors.Is(err, ErrNoCoordinates) {
+			t.Errorf("Parse(%q) err = %v, want ErrNoCoordinates", spec, err)
+		}
+	}
+}
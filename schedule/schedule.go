@@ -0,0 +1,88 @@
+// Package schedule runs jobs at times described in plain English, such as
+// "every weekday at 7:15", "daily at dusk -20m" or "every 15 minutes".
+package schedule
+
+import (
+	"time"
+)
+
+type (
+	// Schedule reports the first time after t that a job is due, or the
+	// zero time if it never is.
+	Schedule interface {
+		Next(t time.Time) time.Time
+	}
+
+	// Every is due at multiples of a fixed interval.
+	Every time.Duration
+
+	// Daily is due once on each of the chosen weekdays, at a time of day
+	// that is either on the clock or relative to the sun.
+	Daily struct {
+		Days     [7]bool
+		Hour     int
+		Minute   int
+		Solar    string
+		Offset   time.Duration
+		Location *time.Location
+
+		coords Coordinates
+	}
+)
+
+func (e Every) Next(t time.Time) time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return t.Truncate(time.Duration(e)).Add(time.Duration(e))
+}
+
+func (d Daily) Next(t time.Time) time.Time {
+	loc := d.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+
+	// Near the poles the sun can go weeks without setting, so solar
+	// schedules look further ahead.
+	days := 8
+	if d.Solar != "" {
+		days = 366
+	}
+
+	// Starting a day early catches offsets that move the event back over
+	// midnight.
+	for i := -1; i < days; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+i, 0, 0, 0, 0, loc)
+		if !d.Days[day.Weekday()] {
+			continue
+		}
+
+		at, ok := d.on(day)
+		if ok && at.After(t) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+func (d Daily) on(day time.Time) (time.Time, bool) {
+	if d.Solar == "" {
+		at := time.Date(day.Year(), day.Month(), day.Day(), d.Hour, d.Minute, 0, 0, day.Location())
+		// A time the clocks skip over comes back before the change, so it
+		// is moved on by the size of the gap, as cron does.
+		if h, m, _ := at.Clock(); h != d.Hour || m != d.Minute {
+			_, before := at.Zone()
+			_, after := at.Add(2 * time.Hour).Zone()
+			at = at.Add(time.Duration(after-before) * time.Second)
+		}
+		return at.Add(d.Offset), true
+	}
+
+	at, ok := solarEvents[d.Solar].at(d.coords, day.Year(), day.Month(), day.Day())
+	if !ok {
+		return time.Time{}, false
+	}
+	return at.In(day.Location()).Add(d.Offset), true
+}
@@ -0,0 +1,161 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no time zone data: %s", err)
+	}
+	return loc
+}
+
+func TestEveryNext(t *testing.T) {
+	at := time.Date(2024, 3, 10, 7, 20, 5, 0, time.UTC)
+
+	if got, want := Every(15*time.Minute).Next(at), time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+	if got := Every(0).Next(at); !got.IsZero() {
+		t.Errorf("Next of a zero interval = %v, want the zero time", got)
+	}
+}
+
+func TestDailyNext(t *testing.T) {
+	ny := loadLocation(t, "America/New_York")
+	all := [7]bool{true, true, true, true, true, true, true}
+
+	tests := []struct {
+		name  string
+		daily Daily
+		from  time.Time
+		want  time.Time
+	}{
+		{
+			name:  "later today",
+			daily: Daily{Days: all, Hour: 7, Minute: 15, Location: ny},
+			from:  time.Date(2024, 1, 10, 6, 0, 0, 0, ny),
+			want:  time.Date(2024, 1, 10, 7, 15, 0, 0, ny),
+		},
+		{
+			name:  "tomorrow",
+			daily: Daily{Days: all, Hour: 7, Minute: 15, Location: ny},
+			from:  time.Date(2024, 1, 10, 7, 15, 0, 0, ny),
+			want:  time.Date(2024, 1, 11, 7, 15, 0, 0, ny),
+		},
+		{
+			name:  "next weekday",
+			daily: Daily{Days: [7]bool{false, true, true, true, true, true, false}, Hour: 7, Location: ny},
+			from:  time.Date(2024, 1, 12, 8, 0, 0, 0, ny), // a Friday
+			want:  time.Date(2024, 1, 15, 7, 0, 0, 0, ny),
+		},
+		{
+			name:  "clocks go forward",
+			daily: Daily{Days: all, Hour: 7, Minute: 15, Location: ny},
+			from:  time.Date(2024, 3, 9, 8, 0, 0, 0, ny),
+			want:  time.Date(2024, 3, 10, 7, 15, 0, 0, ny),
+		},
+		{
+			name:  "clocks go back",
+			daily: Daily{Days: all, Hour: 7, Minute: 15, Location: ny},
+			from:  time.Date(2024, 11, 2, 8, 0, 0, 0, ny),
+			want:  time.Date(2024, 11, 3, 7, 15, 0, 0, ny),
+		},
+		{
+			name:  "skipped hour",
+			daily: Daily{Days: all, Hour: 2, Minute: 30, Location: ny},
+			from:  time.Date(2024, 3, 10, 0, 0, 0, 0, ny),
+			want:  time.Date(2024, 3, 10, 3, 30, 0, 0, ny),
+		},
+		{
+			name:  "offset over midnight",
+			daily: Daily{Days: [7]bool{false, true}, Hour: 0, Minute: 10, Offset: -20 * time.Minute, Location: ny},
+			from:  time.Date(2024, 1, 14, 12, 0, 0, 0, ny), // a Sunday
+			want:  time.Date(2024, 1, 14, 23, 50, 0, 0, ny),
+		},
+		{
+			name:  "no days",
+			daily: Daily{Hour: 7, Location: ny},
+			from:  time.Date(2024, 1, 10, 6, 0, 0, 0, ny),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.daily.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDailyNextDST checks that a daily time stays on the wall clock
+// across both changes, so that the gaps between runs are 23 and 25 hours.
+func TestDailyNextDST(t *testing.T) {
+	ny := loadLocation(t, "America/New_York")
+	d := Daily{Days: [7]bool{true, true, true, true, true, true, true}, Hour: 7, Minute: 15, Location: ny}
+
+	for _, tt := range []struct {
+		from time.Time
+		gap  time.Duration
+	}{
+		{time.Date(2024, 3, 9, 7, 15, 0, 0, ny), 23 * time.Hour},
+		{time.Date(2024, 11, 2, 7, 15, 0, 0, ny), 25 * time.Hour},
+	} {
+		next := d.Next(tt.from)
+		if got := next.Sub(tt.from); got != tt.gap {
+			t.Errorf("Next(%v) = %v, %v later, want %v", tt.from, next, got, tt.gap)
+		}
+		if h, m, _ := next.Clock(); h != 7 || m != 15 {
+			t.Errorf("Next(%v) = %v, want 7:15 on the wall clock", tt.from, next)
+		}
+	}
+}
+
+func TestSolarNext(t *testing.T) {
+	london := loadLocation(t, "Europe/London")
+	p := NewParser(WithLocation(london), WithCoordinates(51.5074, -0.1278))
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"at sunrise", time.Date(2024, 6, 21, 0, 0, 0, 0, london), time.Date(2024, 6, 21, 4, 43, 0, 0, london)},
+		{"at sunset", time.Date(2024, 6, 21, 0, 0, 0, 0, london), time.Date(2024, 6, 21, 21, 21, 0, 0, london)},
+		{"20 minutes before sunset", time.Date(2024, 12, 21, 0, 0, 0, 0, london), time.Date(2024, 12, 21, 15, 34, 0, 0, london)},
+		{"at sunset", time.Date(2024, 12, 21, 16, 0, 0, 0, london), time.Date(2024, 12, 22, 15, 54, 0, 0, london)},
+	}
+
+	for _, tt := range tests {
+		s, err := p.Parse(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := s.Next(tt.from)
+		if diff := got.Sub(tt.want); diff < -3*time.Minute || diff > 3*time.Minute {
+			t.Errorf("%s after %v = %v, want about %v", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+// TestSolarPolar checks that a sunset is still found after a stretch of
+// days on which the sun never sets.
+func TestSolarPolar(t *testing.T) {
+	tromso := Coordinates{Latitude: 69.65, Longitude: 18.96}
+
+	if _, ok := solarEvents["sunset"].at(tromso, 2024, time.June, 21); ok {
+		t.Error("found a sunset in Tromsø at midsummer")
+	}
+
+	d := Daily{Days: [7]bool{true, true, true, true, true, true, true}, Solar: "sunset", Location: time.UTC, coords: tromso}
+	next := d.Next(time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC))
+	if next.IsZero() || next.Month() != time.July {
+		t.Errorf("Next = %v, want the first sunset in late July", next)
+	}
+}
@@ -0,0 +1,186 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+type (
+	Job struct {
+		Name     string
+		Spec     string
+		Schedule Schedule
+		Func     func(ctx context.Context) error
+
+		next time.Time
+	}
+
+	// Scheduler runs jobs when they are due, one at a time in the order
+	// they were added. Failed jobs, including ones that panic, are logged
+	// or passed to the handler from WithErrorHandler, and run again at
+	// their next time.
+	Scheduler struct {
+		parser  *Parser
+		clock   lifx.Clock
+		onError lifx.ErrorHandler
+		mu      sync.Mutex
+		jobs    []*Job
+		wake    chan struct{}
+	}
+)
+
+func NewScheduler(parser *Parser, options ...func(*Scheduler)) *Scheduler {
+	if parser == nil {
+		parser = NewParser()
+	}
+	s := &Scheduler{parser: parser, clock: lifx.SystemClock, wake: make(chan struct{}, 1)}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// WithErrorHandler passes the errors of failed jobs to fn, with the job's
+// name as the operation, instead of logging them.
+func WithErrorHandler(fn lifx.ErrorHandler) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.onError = fn
+	}
+}
+
+// WithClock decides when jobs are due by clock instead of the real time.
+func WithClock(clock lifx.Clock) func(*Scheduler) {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// Add parses spec and schedules fn to run on it.
+func (s *Scheduler) Add(name, spec string, fn func(ctx context.Context) error) (*Job, error) {
+	sched, err := s.parser.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Job{Name: name, Spec: spec, Schedule: sched, Func: fn}
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, j)
+	s.mu.Unlock()
+
+	// A running scheduler may be asleep until a later job.
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return j, nil
+}
+
+// Jobs returns the jobs with the time each is next due.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, len(s.jobs))
+	for i, j := range s.jobs {
+		jobs[i] = *j
+		if jobs[i].next.IsZero() {
+			jobs[i].next = j.Schedule.Next(s.clock.Now())
+		}
+	}
+	return jobs
+}
+
+// Next is when the job is next due, or the zero time if it never is.
+func (j Job) Next() time.Time {
+	return j.next
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		now := s.clock.Now()
+		next := s.plan(now)
+
+		var (
+			t     lifx.Timer
+			timer <-chan time.Time
+		)
+		if !next.IsZero() {
+			t = s.clock.NewTimer(next.Sub(now))
+			timer = t.C()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.wake:
+		case <-timer:
+			s.runDue(ctx, s.clock.Now())
+		}
+
+		if t != nil {
+			t.Stop()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// plan returns when the earliest job is due.
+func (s *Scheduler) plan(now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			j.next = j.Schedule.Next(now)
+		}
+		if !j.next.IsZero() && (next.IsZero() || j.next.Before(next)) {
+			next = j.next
+		}
+	}
+	return next
+}
+
+// run calls the job, turning a panic into a *lifx.PanicError so that the
+// scheduler carries on with the next job.
+func run(ctx context.Context, j *Job) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &lifx.PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return j.Func(ctx)
+}
+
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []*Job
+	for _, j := range s.jobs {
+		if !j.next.IsZero() && !j.next.After(now) {
+			due = append(due, j)
+			j.next = j.Schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, j := range due {
+		err := run(ctx, j)
+		switch {
+		case err == nil:
+		case s.onError != nil:
+			s.onError(j.Name, err)
+		default:
+			log.Printf("schedule: %s: %s", j.Name, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	start := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	clock := lifxtest.NewClock(start)
+
+	var (
+		mu   sync.Mutex
+		errs = make(map[string]error)
+	)
+	s := NewScheduler(NewParser(WithLocation(time.UTC)), WithClock(clock), WithErrorHandler(func(op string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[op] = err
+	}))
+
+	runs := make(chan string, 10)
+	add := func(name, spec string, err error) {
+		t.Helper()
+		_, e := s.Add(name, spec, func(ctx context.Context) error {
+			runs <- name
+			if name == "panics" {
+				panic("boom")
+			}
+			return err
+		})
+		if e != nil {
+			t.Fatal(e)
+		}
+	}
+	add("often", "every 15 minutes", nil)
+	add("fails", "at 7:20", errors.New("bulb on fire"))
+	add("panics", "at 7:25", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Waiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the scheduler never started waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(30 * time.Minute)
+
+	// The timer for 7:15 wakes the scheduler at 7:30, which runs every job
+	// due by then once, in the order they were added.
+	for _, want := range []string{"often", "fails", "panics"} {
+		select {
+		case got := <-runs:
+			if got != want {
+				t.Fatalf("ran %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s never ran", want)
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run = %v, want context.Canceled", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := errs["fails"]; err == nil || err.Error() != "bulb on fire" {
+		t.Errorf("fails reported %v", err)
+	}
+	var pe *lifx.PanicError
+	if !errors.As(errs["panics"], &pe) || pe.Value != "boom" {
+		t.Errorf("panics reported %v, want a PanicError", errs["panics"])
+	}
+	if _, ok := errs["often"]; ok {
+		t.Errorf("often reported %v", errs["often"])
+	}
+
+	want := map[string]time.Time{
+		"often":  start.Add(45 * time.Minute),
+		"fails":  start.Add(24*time.Hour + 20*time.Minute),
+		"panics": start.Add(24*time.Hour + 25*time.Minute),
+	}
+	for _, j := range s.Jobs() {
+		if !j.Next().Equal(want[j.Name]) {
+			t.Errorf("%s next = %v, want %v", j.Name, j.Next(), want[j.Name])
+		}
+	}
+}
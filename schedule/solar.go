@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"math"
+	"time"
+)
+
+// Sun elevations, in degrees, that mark each solar event. Sunrise and
+// sunset allow for refraction and the size of the sun's disc; dawn and dusk
+// are civil twilight.
+const (
+	horizon  = -0.833
+	twilight = -6
+)
+
+type (
+	Coordinates struct {
+		Latitude, Longitude float64
+	}
+
+	solarEvent struct {
+		elevation float64
+		rising    bool
+	}
+)
+
+var solarEvents = map[string]solarEvent{
+	"dawn":    {twilight, true},
+	"sunrise": {horizon, true},
+	"sunset":  {horizon, false},
+	"dusk":    {twilight, false},
+}
+
+// at finds the event on the given local date using the sunrise equation,
+// which is accurate to a minute or so away from the poles. It reports false
+// on days the sun never crosses the elevation.
+func (e solarEvent) at(c Coordinates, year int, month time.Month, day int) (time.Time, bool) {
+	const j2000 = 2451545.0
+
+	noon := time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	n := math.Round(float64(noon.Unix())/86400 + 2440587.5 - j2000)
+
+	meanNoon := n - c.Longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	center := 1.9148*sin(anomaly) + 0.02*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	longitude := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := j2000 + meanNoon + 0.0053*sin(anomaly) - 0.0069*sin(2*longitude)
+	declination := math.Asin(sin(longitude) * sin(23.4397))
+
+	cosHour := (sin(e.elevation) - sin(c.Latitude)*math.Sin(declination)) / (cos(c.Latitude) * math.Cos(declination))
+	if cosHour < -1 || cosHour > 1 {
+		return time.Time{}, false
+	}
+
+	hour := math.Acos(cosHour) * 180 / math.Pi / 360
+	if e.rising {
+		transit -= hour
+	} else {
+		transit += hour
+	}
+
+	return time.Unix(0, int64((transit-2440587.5)*86400*float64(time.Second))), true
+}
+
+func sin(deg float64) float64 { return math.Sin(deg * math.Pi / 180) }
+func cos(deg float64) float64 { return math.Cos(deg * math.Pi / 180) }
@@ -0,0 +1,240 @@
+package lifx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const randomSuffix = ":random"
+
+type SelectorPart struct {
+	Kind   string
+	Value  string
+	Random bool
+}
+
+var selectorKinds = map[string]bool{
+	"id":          true,
+	"label":       true,
+	"group":       true,
+	"group_id":    true,
+	"location":    true,
+	"location_id": true,
+	"scene_id":    true,
+}
+
+func All() SelectorPart                   { return SelectorPart{Kind: "all"} }
+func ById(id string) SelectorPart         { return SelectorPart{Kind: "id", Value: id} }
+func ByLabel(label string) SelectorPart   { return SelectorPart{Kind: "label", Value: label} }
+func ByGroup(name string) SelectorPart    { return SelectorPart{Kind: "group", Value: name} }
+func ByGroupId(id string) SelectorPart    { return SelectorPart{Kind: "group_id", Value: id} }
+func ByLocation(name string) SelectorPart { return SelectorPart{Kind: "location", Value: name} }
+func ByLocationId(id string) SelectorPart { return SelectorPart{Kind: "location_id", Value: id} }
+func BySceneId(uuid string) SelectorPart  { return SelectorPart{Kind: "scene_id", Value: uuid} }
+
+// Randomly narrows the part to a single light picked by the API.
+func (p SelectorPart) Randomly() SelectorPart {
+	p.Random = true
+	return p
+}
+
+func (p SelectorPart) Valid() error {
+	if p.Kind == "all" {
+		if p.Value != "" {
+			return errors.New("the all selector takes no value")
+		}
+		return nil
+	}
+
+	if !selectorKinds[p.Kind] {
+		return fmt.Errorf("'%s' is not a valid selector", p.Kind)
+	}
+	if p.Value == "" {
+		return fmt.Errorf("%s selector needs a value", p.Kind)
+	}
+	// Commas separate parts and a trailing ":random" is a modifier, so
+	// values containing either cannot be expressed.
+	if strings.ContainsAny(p.Value, ",") {
+		return fmt.Errorf("%s selector value cannot contain a comma", p.Kind)
+	}
+	if strings.HasSuffix(p.Value, randomSuffix) {
+		return fmt.Errorf("%s selector value cannot end in %s", p.Kind, randomSuffix)
+	}
+	return nil
+}
+
+func (p SelectorPart) String() string {
+	var b strings.Builder
+
+	b.WriteString(p.Kind)
+	if p.Kind != "all" {
+		b.WriteRune(':')
+		b.WriteString(p.Value)
+	}
+	if p.Random {
+		b.WriteString(randomSuffix)
+	}
+	return b.String()
+}
+
+// Match reports whether the part selects l. Random parts match every
+// light they could pick.
+func (p SelectorPart) Match(l Light) bool {
+	switch p.Kind {
+	case "all":
+		return true
+	case "id":
+		// A zone range such as "|0-5" narrows the light, it does not
+		// name a different one.
+		id := p.Value
+		if i := strings.IndexByte(id, '|'); i >= 0 {
+			id = id[:i]
+		}
+		return strings.EqualFold(l.Id, id)
+	case "label":
+		return l.Label == p.Value
+	case "group":
+		return l.Group.Name == p.Value
+	case "group_id":
+		return l.Group.Id == p.Value
+	case "location":
+		return l.Location.Name == p.Value
+	case "location_id":
+		return l.Location.Id == p.Value
+	}
+	return false
+}
+
+// BuildSelector joins parts into a selector string, refusing any part that
+// could not be parsed back unchanged.
+func BuildSelector(parts ...SelectorPart) (string, error) {
+	if len(parts) == 0 {
+		return "", errors.New("a selector needs at least one part")
+	}
+
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		if err := p.Valid(); err != nil {
+			return "", err
+		}
+		s[i] = p.String()
+	}
+	return strings.Join(s, ","), nil
+}
+
+// MaxSelectorLength bounds the selectors BatchSelectors builds, keeping
+// request URLs well within what the API and proxies accept.
+const MaxSelectorLength = 1024
+
+// BatchSelectors joins parts into as few selectors as it can without any
+// of them growing past MaxSelectorLength, for addressing hundreds of
+// lights by id in a handful of requests.
+func BatchSelectors(parts ...SelectorPart) ([]string, error) {
+	var (
+		batches []string
+		b       strings.Builder
+	)
+
+	for _, p := range parts {
+		if err := p.Valid(); err != nil {
+			return nil, err
+		}
+		s := p.String()
+		if b.Len() > 0 && b.Len()+1+len(s) > MaxSelectorLength {
+			batches = append(batches, b.String())
+			b.Reset()
+		}
+		if b.Len() > 0 {
+			b.WriteRune(',')
+		}
+		b.WriteString(s)
+	}
+	if b.Len() > 0 {
+		batches = append(batches, b.String())
+	}
+	return batches, nil
+}
+
+// EachBatch calls fn with each of the selectors BatchSelectors builds from
+// parts and merges the responses:
+//
+//	r, err := lifx.EachBatch(parts, func(s string) (*lifx.LifxResponse, error) {
+//		return c.SetState(s, state)
+//	})
+//
+// If a call fails, the results so far are returned with its error.
+func EachBatch(parts []SelectorPart, fn func(selector string) (*LifxResponse, error)) (*LifxResponse, error) {
+	var merged *LifxResponse
+
+	selectors, err := BatchSelectors(parts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range selectors {
+		r, err := fn(s)
+		if r != nil {
+			if merged == nil {
+				merged = &LifxResponse{}
+			}
+			merged.Warnings = append(merged.Warnings, r.Warnings...)
+			merged.Results = append(merged.Results, r.Results...)
+		}
+		if err != nil {
+			return merged, err
+		}
+	}
+	return merged, nil
+}
+
+func ParseSelector(s string) ([]SelectorPart, error) {
+	var parts []SelectorPart
+
+	for _, raw := range strings.Split(s, ",") {
+		var p SelectorPart
+
+		// "label:random" is a light labelled random, not a random light
+		// with no label, so the suffix only counts if what precedes it is
+		// a complete selector.
+		if trimmed := strings.TrimSuffix(raw, randomSuffix); trimmed != raw && (trimmed == "all" || strings.Contains(trimmed, ":")) {
+			p.Random = true
+			raw = trimmed
+		}
+
+		if raw == "all" {
+			p.Kind = "all"
+		} else {
+			i := strings.IndexByte(raw, ':')
+			if i < 0 {
+				return nil, fmt.Errorf("'%s' is not a valid selector", raw)
+			}
+			p.Kind, p.Value = raw[:i], raw[i+1:]
+			if p.Kind == "all" {
+				return nil, errors.New("the all selector takes no value")
+			}
+		}
+
+		if err := p.Valid(); err != nil {
+			return nil, err
+		}
+		parts = append(parts, p)
+	}
+
+	return parts, nil
+}
+
+// MatchSelector reports whether any part of selector matches l.
+func MatchSelector(selector string, l Light) (bool, error) {
+	parts, err := ParseSelector(selector)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range parts {
+		if p.Match(l) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,65 @@
+package lifx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func FuzzParseSelector(f *testing.F) {
+	for _, s := range []string{
+		"all",
+		"all:random",
+		"id:d073d5000001",
+		"label:Kitchen Lamp",
+		"group:Lounge,label:Porch",
+		"location_id:1d6fe8ef0fde4c6d77b0012dc736662c:random",
+		"label:",
+		"bogus:1",
+		",",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		parts, err := ParseSelector(s)
+		if err != nil {
+			return
+		}
+
+		built, err := BuildSelector(parts...)
+		if err != nil {
+			t.Fatalf("%q parsed to %+v which does not build: %v", s, parts, err)
+		}
+		if built != s {
+			t.Fatalf("%q rebuilt as %q", s, built)
+		}
+	})
+}
+
+func FuzzBuildSelector(f *testing.F) {
+	f.Add("label", "Kitchen Lamp", false)
+	f.Add("group", "Lounge", true)
+	f.Add("all", "", true)
+	f.Add("label", "a,b", false)
+	f.Add("label", "x:random", false)
+	f.Add("id", "", false)
+
+	f.Fuzz(func(t *testing.T, kind, value string, random bool) {
+		p := SelectorPart{Kind: kind, Value: value, Random: random}
+
+		s, err := BuildSelector(p)
+		if err != nil {
+			return
+		}
+
+		// A selector the builder accepts must reach the API meaning
+		// exactly what the caller asked for.
+		parts, err := ParseSelector(s)
+		if err != nil {
+			t.Fatalf("%+v built %q which does not parse: %v", p, s, err)
+		}
+		if !reflect.DeepEqual(parts, []SelectorPart{p}) {
+			t.Fatalf("%+v built %q which parsed as %+v", p, s, parts)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package lifx
+
+import "time"
+
+// Snapshot records how a set of lights looked so that they can be put
+// back later. It survives being written out as JSON.
+type Snapshot struct {
+	Taken  time.Time `json:"taken"`
+	Lights []Light   `json:"lights"`
+}
+
+// Snapshot records the lights matching selector.
+func (c *Client) Snapshot(selector string) (*Snapshot, error) {
+	lights, err := c.ListLights(selector)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Taken: time.Now(), Lights: lights}, nil
+}
+
+// Restore returns every light in s to its recorded power, color and
+// brightness over duration seconds. Large snapshots are sent in chunks,
+// as many at once as the client's worker pool allows.
+func (c *Client) Restore(s *Snapshot, duration float64) (*SetStatesResponse, error) {
+	var states States
+
+	for _, l := range s.Lights {
+		states.States = append(states.States, StateWithSelector{
+			State:    l.ToState(),
+			Selector: ById(l.Id).String(),
+		})
+	}
+	states.Defaults.Duration = duration
+
+	return c.correlated().SetStates("", states)
+}
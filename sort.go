@@ -0,0 +1,54 @@
+package lifx
+
+import (
+	"sort"
+	"strings"
+)
+
+// Adapters for sort.Sort. Labels compare ignoring case, and lights that
+// would otherwise tie fall back to their id so that the order is stable
+// between calls.
+type (
+	LightsByLabel          []Light
+	LightsByGroupThenLabel []Light
+
+	// LightsByLastSeen puts the most recently seen lights first.
+	LightsByLastSeen []Light
+)
+
+func SortByLabel(lights []Light)          { sort.Sort(LightsByLabel(lights)) }
+func SortByGroupThenLabel(lights []Light) { sort.Sort(LightsByGroupThenLabel(lights)) }
+func SortByLastSeen(lights []Light)       { sort.Sort(LightsByLastSeen(lights)) }
+
+func (s LightsByLabel) Len() int      { return len(s) }
+func (s LightsByLabel) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s LightsByLabel) Less(i, j int) bool {
+	return lessLabel(s[i], s[j])
+}
+
+func (s LightsByGroupThenLabel) Len() int      { return len(s) }
+func (s LightsByGroupThenLabel) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s LightsByGroupThenLabel) Less(i, j int) bool {
+	a, b := strings.ToLower(s[i].Group.Name), strings.ToLower(s[j].Group.Name)
+	if a != b {
+		return a < b
+	}
+	return lessLabel(s[i], s[j])
+}
+
+func (s LightsByLastSeen) Len() int      { return len(s) }
+func (s LightsByLastSeen) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s LightsByLastSeen) Less(i, j int) bool {
+	if !s[i].LastSeen.Equal(s[j].LastSeen) {
+		return s[i].LastSeen.After(s[j].LastSeen)
+	}
+	return s[i].Id < s[j].Id
+}
+
+func lessLabel(a, b Light) bool {
+	la, lb := strings.ToLower(a.Label), strings.ToLower(b.Label)
+	if la != lb {
+		return la < lb
+	}
+	return a.Id < b.Id
+}
@@ -0,0 +1,150 @@
+package lifx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+	"git.kill0.net/chill9/lifx-go/lifxtest"
+)
+
+// chunkedStates is a batch of n states, one per light, that SetStates has
+// to split.
+func chunkedStates(n int) ([]lifx.Light, lifx.States) {
+	var (
+		lights []lifx.Light
+		states lifx.States
+	)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("d073d5%06d", i)
+		lights = append(lights, lifxtest.NewLight(id, fmt.Sprintf("Light %d", i), "Office", "Home"))
+		states.States = append(states.States, lifx.StateWithSelector{Selector: "id:" + id, State: lifx.State{Power: "on"}})
+	}
+	return lights, states
+}
+
+// chunkTransport hands each request to fn with the selectors it carries,
+// so tests can tell the chunks apart.
+func chunkTransport(next http.RoundTripper, fn func(selectors []string) *http.Response) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var states lifx.States
+		if err := json.Unmarshal(body, &states); err != nil {
+			return nil, err
+		}
+		var selectors []string
+		for _, s := range states.States {
+			selectors = append(selectors, s.Selector)
+		}
+		if r := fn(selectors); r != nil {
+			return r, nil
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+func TestSetStatesChunkOrder(t *testing.T) {
+	lights, states := chunkedStates(2*lifx.MaxStates + 10)
+	api := lifxtest.NewServer(lights...)
+	defer api.Close()
+
+	c := lifx.NewClient("x", lifxtest.WithServer(api), lifx.WithStatesConcurrency(3))
+
+	var (
+		mu    sync.Mutex
+		sizes []int
+	)
+	c.Client.Transport = chunkTransport(c.Client.Transport, func(selectors []string) *http.Response {
+		mu.Lock()
+		sizes = append(sizes, len(selectors))
+		mu.Unlock()
+		// Earlier chunks answer last, so the responses arrive out of order.
+		if selectors[0] == states.States[0].Selector {
+			time.Sleep(30 * time.Millisecond)
+		} else if selectors[0] == states.States[lifx.MaxStates].Selector {
+			time.Sleep(15 * time.Millisecond)
+		}
+		return nil
+	})
+
+	r, err := c.SetStates("", states)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(sizes))
+	}
+	for _, n := range sizes {
+		if n > lifx.MaxStates {
+			t.Errorf("a request carried %d states, more than %d", n, lifx.MaxStates)
+		}
+	}
+	if len(r.Results) != len(states.States) {
+		t.Fatalf("got %d operations, want %d", len(r.Results), len(states.States))
+	}
+	for i, op := range r.Results {
+		if want := states.States[i].Selector; op.Operation.Selector != want {
+			t.Fatalf("operation %d is for %s, want %s", i, op.Operation.Selector, want)
+		}
+	}
+}
+
+func TestSetStatesChunkErrors(t *testing.T) {
+	lights, states := chunkedStates(2*lifx.MaxStates + 10)
+	api := lifxtest.NewServer(lights...)
+	defer api.Close()
+
+	c := lifx.NewClient("x", lifxtest.WithServer(api), lifx.WithRetry(lifx.RetryPolicy{}))
+	failed := states.States[lifx.MaxStates].Selector
+	c.Client.Transport = chunkTransport(c.Client.Transport, func(selectors []string) *http.Response {
+		if selectors[0] != failed {
+			return nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"error":"Service Unavailable"}`)),
+		}
+	})
+
+	r, err := c.SetStates("", states)
+	if !errors.Is(err, lifx.ErrServer) {
+		t.Fatalf("err = %v, want ErrServer", err)
+	}
+
+	// The chunks either side of the failed one are still sent and merged
+	// in order.
+	var want []string
+	for i, s := range states.States {
+		if i < lifx.MaxStates || i >= 2*lifx.MaxStates {
+			want = append(want, s.Selector)
+		}
+	}
+	if r == nil || len(r.Results) != len(want) {
+		t.Fatalf("response = %+v, want %d operations", r, len(want))
+	}
+	for i, op := range r.Results {
+		if op.Operation.Selector != want[i] {
+			t.Fatalf("operation %d is for %s, want %s", i, op.Operation.Selector, want[i])
+		}
+	}
+	if l, _ := api.Light(lights[len(lights)-1].Id); l.Power != "on" {
+		t.Errorf("the last chunk was not applied")
+	}
+	if l, _ := api.Light(lights[lifx.MaxStates].Id); l.Power != "off" {
+		t.Errorf("the failed chunk was applied")
+	}
+}
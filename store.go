@@ -0,0 +1,277 @@
+package lifx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StateStore keeps an up-to-date copy of the lights matching a selector.
+// A Watcher refreshes it in the background, and callers that change a
+// light can record the change immediately rather than waiting for the next
+// poll to notice it. Every change is published to subscribers.
+type StateStore struct {
+	watcher *Watcher
+	polling sync.Mutex
+	mu      sync.RWMutex
+	lights  map[string]Light
+	fetched time.Time
+	path    string
+	subs    map[chan Event]struct{}
+}
+
+// storeFile is the on-disk form of a StateStore.
+type storeFile struct {
+	Version  int       `json:"version"`
+	Selector string    `json:"selector"`
+	Fetched  time.Time `json:"fetched"`
+	Lights   []Light   `json:"lights"`
+}
+
+const storeVersion = 1
+
+func NewStateStore(lister Lister, selector string, options ...func(*Watcher)) *StateStore {
+	return &StateStore{
+		watcher: NewWatcher(lister, selector, options...),
+		lights:  make(map[string]Light),
+		subs:    make(map[chan Event]struct{}),
+	}
+}
+
+// Run keeps the store current until ctx is done. Failed polls are
+// published as WatchError events.
+func (s *StateStore) Run(ctx context.Context) error {
+	ticker := s.watcher.clock.NewTicker(s.watcher.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(); err != nil {
+			s.publish(Event{Type: WatchError, Time: s.watcher.clock.Now(), Err: err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// Refresh polls immediately, replacing the store's contents.
+func (s *StateStore) Refresh() error {
+	s.polling.Lock()
+	defer s.polling.Unlock()
+
+	events := s.watcher.Poll()
+	for _, e := range events {
+		if e.Type == WatchError {
+			return e.Err
+		}
+	}
+
+	s.mu.Lock()
+	s.lights = make(map[string]Light, len(s.watcher.lights))
+	for id, l := range s.watcher.lights {
+		s.lights[id] = l
+	}
+	s.fetched = s.watcher.clock.Now()
+	s.mu.Unlock()
+
+	for _, e := range events {
+		s.publish(e)
+	}
+	return s.save()
+}
+
+// Persist keeps the store in the file at path, so that a program started
+// while the cloud is unreachable still has the lights as they last were.
+// Lights saved there for the same selector are loaded straight away, and
+// the first poll reports how they have changed since. The file is
+// rewritten after every refresh and update.
+func (s *StateStore) Persist(path string) error {
+	var f storeFile
+
+	s.mu.Lock()
+	s.path = path
+	s.mu.Unlock()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Version != storeVersion || f.Selector != s.watcher.selector {
+		return nil
+	}
+
+	s.polling.Lock()
+	defer s.polling.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A poll has already replaced whatever was saved.
+	if !s.fetched.IsZero() {
+		return nil
+	}
+
+	s.lights = make(map[string]Light, len(f.Lights))
+	s.watcher.lights = make(map[string]Light, len(f.Lights))
+	for _, l := range f.Lights {
+		s.lights[l.Id] = l
+		s.watcher.lights[l.Id] = l
+	}
+	s.fetched = f.Fetched
+	return nil
+}
+
+// Fetched is when the lights were last listed, which after Persist may be
+// before the program started. It is the zero time until then.
+func (s *StateStore) Fetched() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.fetched
+}
+
+func (s *StateStore) save() error {
+	s.mu.RLock()
+	path := s.path
+	f := storeFile{Version: storeVersion, Selector: s.watcher.selector, Fetched: s.fetched}
+	s.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	f.Lights = s.Lights()
+
+	b, err := json.MarshalIndent(&f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// file behind.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".lifx-state-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Lights returns the lights sorted by location, group and label.
+func (s *StateStore) Lights() []Light {
+	s.mu.RLock()
+	lights := make([]Light, 0, len(s.lights))
+	for _, l := range s.lights {
+		lights = append(lights, l)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(lights, func(i, j int) bool {
+		a, b := lights[i], lights[j]
+		if a.Location.Name != b.Location.Name {
+			return a.Location.Name < b.Location.Name
+		}
+		if a.Group.Name != b.Group.Name {
+			return a.Group.Name < b.Group.Name
+		}
+		if a.Label != b.Label {
+			return a.Label < b.Label
+		}
+		return a.Id < b.Id
+	})
+	return lights
+}
+
+func (s *StateStore) Light(id string) (Light, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.lights[id]
+	return l, ok
+}
+
+// Update records a change the caller made to a light, reporting whether
+// the light is known. The watcher's copy is updated too, so that the next
+// poll reports how the light differs from the change rather than the
+// change again. An update waits for a poll in progress to finish.
+func (s *StateStore) Update(id string, fn func(*Light)) bool {
+	s.polling.Lock()
+	defer s.polling.Unlock()
+
+	s.mu.Lock()
+	old, ok := s.lights[id]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	l := old
+	fn(&l)
+	s.lights[id] = l
+	s.mu.Unlock()
+
+	if _, ok := s.watcher.lights[id]; ok {
+		s.watcher.lights[id] = l
+	}
+
+	if changes := Diff(old, l); len(changes) > 0 {
+		s.publish(Event{Type: LightChanged, Time: s.watcher.clock.Now(), Light: l, Changes: changes})
+		s.save()
+	}
+	return true
+}
+
+// Subscribe returns a channel of changes and a function that cancels the
+// subscription. Slow subscribers miss events rather than blocking the
+// store, so they should treat an event as a cue to reread its state.
+func (s *StateStore) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 64)
+
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *StateStore) publish(e Event) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
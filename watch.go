@@ -0,0 +1,173 @@
+package lifx
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	LightAdded   EventType = "added"
+	LightRemoved EventType = "removed"
+	LightChanged EventType = "changed"
+	WatchError   EventType = "error"
+)
+
+type (
+	EventType string
+
+	Event struct {
+		Type    EventType `json:"type"`
+		Time    time.Time `json:"time"`
+		Light   Light     `json:"light"`
+		Changes []Change  `json:"changes,omitempty"`
+		Err     error     `json:"-"`
+	}
+
+	Change struct {
+		Field string `json:"field"`
+		Old   string `json:"old"`
+		New   string `json:"new"`
+	}
+
+	Lister interface {
+		ListLights(selector string) ([]Light, error)
+	}
+
+	// Watcher polls the lights matching a selector and reports how they
+	// change between polls. The API has no push notifications, so polling
+	// is the only way to notice changes made by other clients.
+	Watcher struct {
+		lister   Lister
+		selector string
+		interval time.Duration
+		clock    Clock
+		onError  ErrorHandler
+		lights   map[string]Light
+	}
+)
+
+var DefaultWatchInterval = 5 * time.Second
+
+func NewWatcher(lister Lister, selector string, options ...func(*Watcher)) *Watcher {
+	w := &Watcher{
+		lister:   lister,
+		selector: selector,
+		interval: DefaultWatchInterval,
+		clock:    SystemClock,
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+func WithInterval(interval time.Duration) func(*Watcher) {
+	return func(w *Watcher) {
+		w.interval = interval
+	}
+}
+
+// WithWatchErrorHandler passes panics raised by the function given to
+// Run to fn, as a *PanicError with the operation "watch".
+func WithWatchErrorHandler(fn ErrorHandler) func(*Watcher) {
+	return func(w *Watcher) {
+		w.onError = fn
+	}
+}
+
+// WithWatchClock times polls and events with clock.
+func WithWatchClock(clock Clock) func(*Watcher) {
+	return func(w *Watcher) {
+		w.clock = clock
+	}
+}
+
+// Run polls until ctx is done, calling fn for every event. The first poll
+// only records the lights' state. Failed polls are reported as WatchError
+// events and do not stop the watcher, and neither does fn panicking.
+func (w *Watcher) Run(ctx context.Context, fn func(Event)) error {
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, e := range w.Poll() {
+			err := safely(func() error {
+				fn(e)
+				return nil
+			})
+			if err != nil && w.onError != nil {
+				w.onError("watch", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// Poll lists the lights once and returns the events since the last poll.
+func (w *Watcher) Poll() []Event {
+	var events []Event
+
+	now := w.clock.Now()
+	lights, err := w.lister.ListLights(w.selector)
+	if err != nil {
+		return []Event{{Type: WatchError, Time: now, Err: err}}
+	}
+
+	current := make(map[string]Light, len(lights))
+	for _, l := range lights {
+		current[l.Id] = l
+	}
+
+	if w.lights == nil {
+		w.lights = current
+		return nil
+	}
+
+	for _, l := range lights {
+		old, ok := w.lights[l.Id]
+		if !ok {
+			events = append(events, Event{Type: LightAdded, Time: now, Light: l})
+		} else if changes := Diff(old, l); len(changes) > 0 {
+			events = append(events, Event{Type: LightChanged, Time: now, Light: l, Changes: changes})
+		}
+	}
+	for id, l := range w.lights {
+		if _, ok := current[id]; !ok {
+			events = append(events, Event{Type: LightRemoved, Time: now, Light: l})
+		}
+	}
+
+	w.lights = current
+	return events
+}
+
+// Diff lists the user-visible differences between two states of a light,
+// ignoring fields such as LastSeen that change on every poll.
+func Diff(old, new Light) []Change {
+	var changes []Change
+
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+
+	add("label", old.Label, new.Label)
+	add("connected", fmt.Sprint(old.Connected), fmt.Sprint(new.Connected))
+	add("power", old.Power, new.Power)
+	add("color", old.Color.ColorString(), new.Color.ColorString())
+	add("brightness", fmt.Sprint(old.Brightness), fmt.Sprint(new.Brightness))
+	add("effect", old.Effect.String(), new.Effect.String())
+	add("group", old.Group.Name, new.Group.Name)
+	add("location", old.Location.Name, new.Location.Name)
+
+	return changes
+}
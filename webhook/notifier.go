@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// the body, keyed with the shared secret.
+	SignatureHeader = "X-Lifx-Signature"
+	EventHeader     = "X-Lifx-Event"
+
+	// DeliveryHeader identifies a delivery. It is the same for every
+	// attempt, so receivers can discard duplicates.
+	DeliveryHeader = "X-Lifx-Delivery"
+)
+
+type (
+	// Notifier posts watcher events to webhook URLs. Pass its Handle method
+	// to Watcher.Run and run the Notifier alongside it; deliveries happen in
+	// the order events arrive without holding up the watcher.
+	Notifier struct {
+		urls    []string
+		secret  []byte
+		client  *http.Client
+		retry   lifx.RetryPolicy
+		queue   chan lifx.Event
+		timeout time.Duration
+		onError lifx.ErrorHandler
+	}
+
+	payload struct {
+		Type    lifx.EventType `json:"type"`
+		Time    time.Time      `json:"time"`
+		Light   lifx.Light     `json:"light"`
+		Changes []lifx.Change  `json:"changes,omitempty"`
+	}
+
+	// StatusError is returned for a delivery the receiver rejected.
+	StatusError struct {
+		URL        string
+		StatusCode int
+	}
+)
+
+var (
+	DefaultQueueSize       = 100
+	DefaultDeliveryTimeout = 10 * time.Second
+
+	// ErrQueueFull is reported for events dropped by Handle.
+	ErrQueueFull = errors.New("webhook queue full")
+)
+
+func NewNotifier(urls []string, secret string, options ...func(*Notifier)) *Notifier {
+	n := &Notifier{
+		urls:    urls,
+		secret:  []byte(secret),
+		client:  http.DefaultClient,
+		retry:   lifx.DefaultRetryPolicy,
+		queue:   make(chan lifx.Event, DefaultQueueSize),
+		timeout: DefaultDeliveryTimeout,
+	}
+
+	for _, option := range options {
+		option(n)
+	}
+
+	return n
+}
+
+func WithHTTPClient(c *http.Client) func(*Notifier) {
+	return func(n *Notifier) {
+		n.client = c
+	}
+}
+
+// WithRetry sets how deliveries that fail with a network error, a rate
+// limit or a server error are retried.
+func WithRetry(policy lifx.RetryPolicy) func(*Notifier) {
+	return func(n *Notifier) {
+		n.retry = policy
+	}
+}
+
+// WithQueueSize sets how many events can wait for delivery before new ones
+// are dropped.
+func WithQueueSize(size int) func(*Notifier) {
+	return func(n *Notifier) {
+		n.queue = make(chan lifx.Event, size)
+	}
+}
+
+// WithDeliveryTimeout bounds each attempt at a delivery.
+func WithDeliveryTimeout(timeout time.Duration) func(*Notifier) {
+	return func(n *Notifier) {
+		n.timeout = timeout
+	}
+}
+
+// WithErrorHandler passes failed deliveries to fn with the op "notify",
+// and events dropped because the queue was full with the op "handle".
+func WithErrorHandler(fn lifx.ErrorHandler) func(*Notifier) {
+	return func(n *Notifier) {
+		n.onError = fn
+	}
+}
+
+// Sign returns the signature header value for body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body, for receivers
+// written in Go.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.URL, http.StatusText(e.StatusCode))
+}
+
+// Handle queues e for delivery. Watch errors are not delivered, and events
+// are dropped, reporting ErrQueueFull, when the queue is full.
+func (n *Notifier) Handle(e lifx.Event) {
+	if e.Type == lifx.WatchError {
+		return
+	}
+
+	select {
+	case n.queue <- e:
+	default:
+		n.reportError("handle", fmt.Errorf("%w: dropping %s event for %s", ErrQueueFull, e.Type, e.Light.Id))
+	}
+}
+
+// Run delivers queued events until ctx is done, reporting failed
+// deliveries to the handler from WithErrorHandler.
+func (n *Notifier) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e := <-n.queue:
+			if err := n.Notify(ctx, e); err != nil {
+				n.reportError("notify", err)
+			}
+		}
+	}
+}
+
+// Notify delivers e to every URL, retrying each as the policy allows, and
+// returns the first error.
+func (n *Notifier) Notify(ctx context.Context, e lifx.Event) error {
+	body, err := json.Marshal(payload{
+		Type:    e.Type,
+		Time:    e.Time,
+		Light:   e.Light,
+		Changes: e.Changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	id, err := deliveryId()
+	if err != nil {
+		return err
+	}
+
+	var first error
+	for _, url := range n.urls {
+		if err := n.deliver(ctx, url, id, string(e.Type), body); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (n *Notifier) deliver(ctx context.Context, url, id, event string, body []byte) error {
+	wait := n.retry.Backoff
+	for attempt := 0; ; attempt++ {
+		err := n.post(ctx, url, id, event, body)
+		if err == nil || attempt >= n.retry.Attempts || !temporary(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if n.retry.MaxBackoff > 0 && wait > n.retry.MaxBackoff {
+			wait = n.retry.MaxBackoff
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		wait *= 2
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, url, id, event string, body []byte) error {
+	if n.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "lifx-go/"+lifx.Version)
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, id)
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	r, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+
+	if r.StatusCode > 299 {
+		return &StatusError{URL: url, StatusCode: r.StatusCode}
+	}
+	return nil
+}
+
+// temporary reports whether a failed delivery is worth another attempt.
+// Receivers that reject a delivery outright will do so again.
+func temporary(err error) bool {
+	if e, ok := err.(*StatusError); ok {
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	}
+	return true
+}
+
+func deliveryId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (n *Notifier) reportError(op string, err error) {
+	if n.onError != nil {
+		n.onError(op, err)
+	}
+}
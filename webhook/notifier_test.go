@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+func TestNotifierErrorHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	errs := make(chan error, 2)
+	ops := make(chan string, 2)
+	n := NewNotifier([]string{srv.URL}, "secret", WithQueueSize(1), WithErrorHandler(func(op string, err error) {
+		ops <- op
+		errs <- err
+	}))
+
+	e := lifx.Event{Type: lifx.LightChanged, Light: lifx.Light{Id: "d073d5000001"}}
+	n.Handle(e)
+	n.Handle(e)
+	if op, err := <-ops, <-errs; op != "handle" || !errors.Is(err, ErrQueueFull) {
+		t.Errorf("second event reported %s: %v, want handle: ErrQueueFull", op, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	var se *StatusError
+	if op, err := <-ops, <-errs; op != "notify" || !errors.As(err, &se) || se.StatusCode != http.StatusForbidden {
+		t.Errorf("delivery reported %s: %v, want notify and a 403", op, err)
+	}
+}
@@ -0,0 +1,222 @@
+// Package webhook connects LIFX lights to other systems over plain HTTP.
+// Server turns incoming POST requests into light actions, so that anything
+// able to call a URL, such as a doorbell, a CI job or IFTTT, can control
+// lights. Notifier goes the other way, posting the changes a watcher sees
+// to webhook URLs.
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"git.kill0.net/chill9/lifx-go"
+)
+
+const (
+	SceneAction  = "scene"
+	ToggleAction = "toggle"
+	StateAction  = "state"
+)
+
+// SecretHeader carries the shared secret. An "Authorization: Bearer" header
+// or a "secret" query parameter is accepted too, for senders that cannot set
+// custom headers.
+const SecretHeader = "X-Webhook-Secret"
+
+type (
+	// Controller is the part of the client that actions need.
+	Controller interface {
+		SetState(selector string, state lifx.State) (*lifx.LifxResponse, error)
+		Toggle(selector string, duration float64) (*lifx.LifxResponse, error)
+		ListScenes() ([]lifx.Scene, error)
+		ActivateScene(uuid string, activate lifx.Activate) (*lifx.LifxResponse, error)
+	}
+
+	// Action is what a hook does when it is called. Scene actions name the
+	// scene by name or UUID; toggle and state actions address lights with
+	// Selector, which defaults to all of them.
+	Action struct {
+		Action     string   `json:"action"`
+		Selector   string   `json:"selector,omitempty"`
+		Scene      string   `json:"scene,omitempty"`
+		Power      string   `json:"power,omitempty"`
+		Color      string   `json:"color,omitempty"`
+		Brightness float64  `json:"brightness,omitempty"`
+		Infrared   float64  `json:"infrared,omitempty"`
+		Duration   float64  `json:"duration,omitempty"`
+		Ignore     []string `json:"ignore,omitempty"`
+	}
+
+	// Server serves POST /<name> for every configured action.
+	Server struct {
+		controller Controller
+		secret     string
+		actions    map[string]Action
+	}
+)
+
+var ErrNoSecret = errors.New("a webhook secret is required")
+
+func NewServer(controller Controller, secret string, actions map[string]Action) (*Server, error) {
+	if secret == "" {
+		return nil, ErrNoSecret
+	}
+
+	for name, a := range actions {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("'%s' is not a valid hook name", name)
+		}
+		if err := a.Valid(); err != nil {
+			return nil, fmt.Errorf("hook %s: %w", name, err)
+		}
+	}
+
+	return &Server{controller: controller, secret: secret, actions: actions}, nil
+}
+
+// LoadActions reads a JSON object mapping hook names to actions.
+func LoadActions(path string) (map[string]Action, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var actions map[string]Action
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&actions); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return actions, nil
+}
+
+func (a Action) Valid() error {
+	switch a.Action {
+	case SceneAction:
+		if a.Scene == "" {
+			return errors.New("scene action needs a scene")
+		}
+	case ToggleAction:
+	case StateAction:
+		if a.Power == "" && a.Color == "" && a.Brightness == 0 && a.Infrared == 0 {
+			return errors.New("state action needs a power, color, brightness or infrared")
+		}
+		if a.Power != "" && a.Power != "on" && a.Power != "off" {
+			return fmt.Errorf("'%s' is not a valid power", a.Power)
+		}
+		if a.Color != "" {
+			if _, err := lifx.ParseColor(a.Color); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("'%s' is not a valid action", a.Action)
+	}
+
+	if a.Selector != "" {
+		if _, err := lifx.ParseSelector(a.Selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a Action) selector() string {
+	if a.Selector == "" {
+		return lifx.All().String()
+	}
+	return a.Selector
+}
+
+func (a Action) State() lifx.State {
+	s := lifx.State{
+		Power:      a.Power,
+		Brightness: a.Brightness,
+		Infrared:   a.Infrared,
+		Duration:   a.Duration,
+	}
+	if a.Color != "" {
+		s.Color = lifx.NamedColor(a.Color)
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("hooks must be called with POST"))
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, errors.New("bad webhook secret"))
+		return
+	}
+
+	// Unknown hooks are only reported to callers that know the secret.
+	a, ok := s.actions[strings.Trim(r.URL.Path, "/")]
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("no such hook"))
+		return
+	}
+
+	resp, err := s.run(a)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	var results []lifx.Result
+	if resp != nil {
+		results = resp.Results
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	given := r.Header.Get(SecretHeader)
+	if given == "" {
+		given = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if given == "" {
+		given = r.URL.Query().Get("secret")
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(s.secret)) == 1
+}
+
+func (s *Server) run(a Action) (*lifx.LifxResponse, error) {
+	switch a.Action {
+	case SceneAction:
+		scenes, err := s.controller.ListScenes()
+		if err != nil {
+			return nil, err
+		}
+		scene, err := lifx.FindScene(scenes, a.Scene)
+		if err != nil {
+			return nil, err
+		}
+		return s.controller.ActivateScene(scene.UUID, lifx.Activate{Duration: a.Duration, Ignore: a.Ignore})
+	case ToggleAction:
+		return s.controller.Toggle(a.selector(), a.Duration)
+	case StateAction:
+		return s.controller.SetState(a.selector(), a.State())
+	}
+	return nil, fmt.Errorf("'%s' is not a valid action", a.Action)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}